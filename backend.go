@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"zel/lo/internal"
+	"zel/lo/ui"
+)
+
+// ZELLO_BACKEND picks where the TUI keeps issues. The default is the
+// Supabase project in SUPABASE_URL; "postgres" talks to the database in
+// DATABASE_URL directly and signs in against its users table. Subcommands
+// always use Supabase.
+const (
+	backendSupabase = "supabase"
+	backendPostgres = "postgres"
+)
+
+// runPostgres signs in to a self-hosted database and starts the TUI on it.
+// Features that need Supabase beyond the issue store are unavailable.
+func runPostgres() error {
+	url := os.Getenv("DATABASE_URL")
+	if url == "" {
+		return fmt.Errorf("DATABASE_URL is required with ZELLO_BACKEND=%s", backendPostgres)
+	}
+	ctx := context.Background()
+	store, err := internal.NewPostgresStore(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	userID, err := promptPostgresAuth(ctx, store)
+	if err != nil {
+		return err
+	}
+	return ui.Start(nil, store, userID, "")
+}
+
+// runLocal starts the TUI on a SQLite database with no server and no
+// sign-in:
+//
+//	zello --local [FILE]
+//
+// FILE defaults to localDBPath.
+func runLocal(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("usage: zello --local [FILE]")
+	}
+	path := ""
+	if len(args) == 1 {
+		path = args[0]
+	}
+	path, err := localDBPath(path)
+	if err != nil {
+		return err
+	}
+	store, err := internal.OpenSQLiteStore(context.Background(), path)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	return ui.Start(nil, store, internal.LocalUserID, "")
+}
+
+// localDBPath returns path when set, else ZELLO_LOCAL_DB, else local.db in
+// the user's config directory, creating the directory as needed.
+func localDBPath(path string) (string, error) {
+	if path == "" {
+		path = os.Getenv("ZELLO_LOCAL_DB")
+	}
+	if path == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to find config directory: %w", err)
+		}
+		path = filepath.Join(dir, "zello", "local.db")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	return path, nil
+}
+
+// promptPostgresAuth signs in with ZELLO_EMAIL and ZELLO_PASSWORD when set,
+// otherwise it asks on stdin like promptAuth does.
+func promptPostgresAuth(ctx context.Context, store *internal.PostgresStore) (string, error) {
+	email := os.Getenv("ZELLO_EMAIL")
+	password := os.Getenv("ZELLO_PASSWORD")
+	if email != "" && password != "" {
+		return store.SignIn(ctx, email, password)
+	}
+
+	in := bufio.NewReader(os.Stdin)
+	ask := func(prompt string) string {
+		fmt.Println(prompt)
+		line, _ := in.ReadString('\n')
+		return strings.TrimSpace(line)
+	}
+	switch strings.ToLower(ask("press S to sign in and C to create account")) {
+	case "s":
+		email = ask("enter your email:")
+		password = ask("enter your password:")
+		return store.SignIn(ctx, email, password)
+	case "c":
+		email = ask("enter your email:")
+		password = ask("enter your password:")
+		name := ask("enter your name:")
+		userID, err := store.SignUp(ctx, email, password, name)
+		if err != nil {
+			return "", err
+		}
+		fmt.Println("Account created successfully!")
+		return userID, nil
+	}
+	return "", fmt.Errorf("expected S or C")
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"zel/lo/internal"
+	"zel/lo/supabase"
+)
+
+// runCommand runs a non-interactive zello subcommand, e.g.
+//
+//	zello issue list --query "status:open assignee:me"
+//
+// Commands sign in with ZELLO_EMAIL (or ZELLO_PHONE) and ZELLO_PASSWORD when
+// set and fall back to the interactive prompt otherwise. Accounts with an
+// authenticator app also need ZELLO_TOTP_CODE or answer a prompt for it.
+func runCommand(args []string) error {
+	switch args[0] {
+	case "issue":
+		return runIssueCommand(newClient(), args[1:])
+	case "time":
+		return runTimeCommand(newClient(), args[1:])
+	case "git":
+		return runGitCommand(args[1:])
+	case "export":
+		return runExportCommand(newClient(), args[1:])
+	case "import":
+		return runImportCommand(newClient(), args[1:])
+	case "webhook":
+		return runWebhookCommand(args[1:])
+	case "sync":
+		return runSyncCommand(newClient(), args[1:])
+	case "db":
+		return runDBCommand(args[1:])
+	case "profile":
+		return runProfileCommand(newClient(), args[1:])
+	case "template":
+		return runTemplateCommand(newClient(), args[1:])
+	case "mfa":
+		return runMFACommand(newClient(), args[1:])
+	}
+	return fmt.Errorf("unknown command %q", args[0])
+}
+
+func runIssueCommand(client *supabase.Client, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: zello issue list [--query QUERY] [--sort COLUMN.DIR] | zello issue create --title TITLE [--desc DESC] [--labels a,b] | zello issue stats [--all]")
+	}
+	switch args[0] {
+	case "create":
+		fs := flag.NewFlagSet("issue create", flag.ContinueOnError)
+		title := fs.String("title", "", "issue title")
+		desc := fs.String("desc", "", "issue description (opens $VISUAL/$EDITOR when omitted)")
+		labels := fs.String("labels", "", "comma separated labels")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if strings.TrimSpace(*title) == "" {
+			return fmt.Errorf("--title is required")
+		}
+		descSet := false
+		fs.Visit(func(f *flag.Flag) { descSet = descSet || f.Name == "desc" })
+		if !descSet {
+			text, err := internal.EditText("")
+			if err != nil {
+				return err
+			}
+			*desc = text
+		}
+		userID := signIn(client)
+		req := internal.CreateIssueRequest{Title: strings.TrimSpace(*title), Description: strings.TrimSpace(*desc)}
+		if *labels != "" {
+			for _, l := range strings.Split(*labels, ",") {
+				if l = strings.TrimSpace(l); l != "" {
+					req.Labels = append(req.Labels, l)
+				}
+			}
+		}
+		board, err := internal.CurrentBoard(client, os.Getenv("ZELLO_BOARD"))
+		if err != nil && !errors.Is(err, internal.ErrNoBoards) {
+			return err
+		}
+		if board == nil {
+			// Without any board the issue is simply not numbered.
+			issue, err := internal.CreateIssue(client, req, userID)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Created #%d %s\n", issue.ID, issue.Title)
+			return nil
+		}
+		req.BoardID = &board.ID
+		issue, err := internal.CreateBoardIssue(context.Background(), client, req)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created %s %s\n", issue.Key(board), issue.Title)
+		return nil
+	case "list":
+		fs := flag.NewFlagSet("issue list", flag.ContinueOnError)
+		query := fs.String("query", "", "filter issues, e.g. \"status:open label:bug assignee:me\"")
+		sort := fs.String("sort", "", "order issues, e.g. created_at.desc")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		userID := signIn(client)
+		issues, err := internal.ListIssuesQuery(client, *query, *sort, userID)
+		if err != nil {
+			return err
+		}
+		for _, is := range issues {
+			fmt.Printf("#%-4d %-12s %-s\n", is.ID, is.Status, is.Title)
+		}
+		return nil
+	case "stats":
+		fs := flag.NewFlagSet("issue stats", flag.ContinueOnError)
+		all := fs.Bool("all", false, "count issues on every board")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		signIn(client)
+		boardID := 0
+		if !*all {
+			board, err := internal.CurrentBoard(client, os.Getenv("ZELLO_BOARD"))
+			if err != nil {
+				return err
+			}
+			boardID = board.ID
+		}
+		counts, err := internal.IssueStatusCounts(context.Background(), client, boardID)
+		if err != nil {
+			return err
+		}
+		total := 0
+		for _, c := range counts {
+			fmt.Printf("%-12s %d\n", c.Status, c.Count)
+			total += c.Count
+		}
+		fmt.Printf("%-12s %d\n", "total", total)
+		return nil
+	}
+	return fmt.Errorf("unknown issue command %q", args[0])
+}
+
+// signIn authenticates from the environment when credentials are present,
+// otherwise it prompts like the TUI launcher does.
+func signIn(client *supabase.Client) string {
+	email := os.Getenv("ZELLO_EMAIL")
+	phone := os.Getenv("ZELLO_PHONE")
+	password := os.Getenv("ZELLO_PASSWORD")
+	if (email == "" && phone == "") || password == "" {
+		return promptAuth(client)
+	}
+	login, account := client.SignInWithEmailPassword, email
+	if email == "" {
+		login, account = client.SignInWithPhonePassword, phone
+	}
+	session, err := login(account, password)
+	if err != nil {
+		log.Fatal("Error signing in:", err)
+	}
+	verifyMFA(client, session)
+	return session.User.ID.String()
+}
+
+func runTimeCommand(client *supabase.Client, args []string) error {
+	usage := fmt.Errorf("usage: zello time log ISSUE DURATION [--note NOTE] [--date YYYY-MM-DD] | zello time report [--issue ISSUE]")
+	if len(args) == 0 {
+		return usage
+	}
+	switch args[0] {
+	case "log":
+		if len(args) < 3 {
+			return usage
+		}
+		issueID, err := strconv.Atoi(strings.TrimPrefix(args[1], "#"))
+		if err != nil {
+			return fmt.Errorf("invalid issue number %q", args[1])
+		}
+		d, err := internal.ParseWorkDuration(args[2])
+		if err != nil {
+			return err
+		}
+		fs := flag.NewFlagSet("time log", flag.ContinueOnError)
+		note := fs.String("note", "", "what the time was spent on")
+		date := fs.String("date", "", "day the work was done (default today)")
+		if err := fs.Parse(args[3:]); err != nil {
+			return err
+		}
+		on := time.Now()
+		if *date != "" {
+			on, err = time.Parse("2006-01-02", *date)
+			if err != nil {
+				return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", *date)
+			}
+		}
+		userID := signIn(client)
+		entry, err := internal.LogWork(client, issueID, userID, d, *note, on)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Logged %s on #%d\n", internal.FormatDuration(entry.Duration()), issueID)
+		return nil
+	case "report":
+		fs := flag.NewFlagSet("time report", flag.ContinueOnError)
+		issueID := fs.Int("issue", 0, "only report time on this issue")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		signIn(client)
+		totals, err := internal.WorklogTotals(context.Background(), client, *issueID)
+		if err != nil {
+			return err
+		}
+		ids := make([]int, 0, len(totals.ByIssue))
+		for id := range totals.ByIssue {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+		fmt.Println("By issue:")
+		for _, id := range ids {
+			fmt.Printf("  #%-6d %s\n", id, internal.FormatDuration(totals.ByIssue[id]))
+		}
+		fmt.Println("By user:")
+		for _, u := range totals.SortedUsers() {
+			fmt.Printf("  %-36s %s\n", u, internal.FormatDuration(totals.ByUser[u]))
+		}
+		fmt.Printf("Total: %s\n", internal.FormatDuration(totals.Total))
+		return nil
+	}
+	return fmt.Errorf("unknown time command %q", args[0])
+}
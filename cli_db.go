@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/joho/godotenv"
+
+	"zel/lo/internal"
+	"zel/lo/migrations"
+)
+
+// runDBCommand manages the database schema shipped in migrations/:
+//
+//	zello db migrate [--url URL]
+//	zello db status [--url URL]
+//
+// URL defaults to DATABASE_URL. For a Supabase project use its direct
+// connection string from the database settings. status never writes to the
+// database.
+func runDBCommand(args []string) error {
+	usage := fmt.Errorf("usage: zello db migrate|status [--url URL]")
+	if len(args) == 0 {
+		return usage
+	}
+	godotenv.Load()
+	fs := flag.NewFlagSet("db "+args[0], flag.ContinueOnError)
+	url := fs.String("url", os.Getenv("DATABASE_URL"), "Postgres connection string")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *url == "" {
+		return fmt.Errorf("set DATABASE_URL or pass --url")
+	}
+
+	all, err := internal.LoadMigrations(migrations.FS)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	conn, err := pgx.Connect(ctx, *url)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close(ctx)
+
+	switch args[0] {
+	case "migrate":
+		n := 0
+		err := internal.Migrate(ctx, conn, all, func(m internal.Migration) {
+			fmt.Printf("applied %s\n", m)
+			n++
+		})
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			fmt.Println("Database is up to date")
+		} else {
+			fmt.Printf("Applied %d migrations\n", n)
+		}
+		return nil
+	case "status":
+		states, err := internal.MigrationStatus(ctx, conn, all)
+		if err != nil {
+			return err
+		}
+		pending := 0
+		for _, st := range states {
+			switch {
+			case st.AppliedAt == nil:
+				pending++
+				fmt.Printf("pending   %s\n", st.Migration)
+			case st.SQL == "":
+				fmt.Printf("unknown   %s  applied %s, no such file\n", st.Migration, st.AppliedAt.Local().Format("2006-01-02 15:04"))
+			case st.Modified:
+				fmt.Printf("modified  %s  applied %s, file changed since\n", st.Migration, st.AppliedAt.Local().Format("2006-01-02 15:04"))
+			default:
+				fmt.Printf("applied   %s  %s\n", st.Migration, st.AppliedAt.Local().Format("2006-01-02 15:04"))
+			}
+		}
+		fmt.Printf("%d pending\n", pending)
+		return nil
+	}
+	return usage
+}
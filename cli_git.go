@@ -0,0 +1,182 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"zel/lo/git"
+	"zel/lo/internal"
+)
+
+func runGitCommand(args []string) error {
+	usage := fmt.Errorf("usage: zello git branch KEY-N | commit-msg FILE | install-hook | sync [--dry-run] [--limit N] [RANGE]")
+	if len(args) == 0 {
+		return usage
+	}
+	repo := git.Repo{}
+	switch args[0] {
+	case "branch":
+		if len(args) != 2 {
+			return usage
+		}
+		key, err := gitBoardKey(repo)
+		if err != nil {
+			return err
+		}
+		// Accept ZEL-12 as well as the bare number on the board.
+		arg := strings.TrimPrefix(args[1], "#")
+		if prefix := key + "-"; len(arg) > len(prefix) && strings.EqualFold(arg[:len(prefix)], prefix) {
+			arg = arg[len(prefix):]
+		}
+		number, err := strconv.Atoi(arg)
+		if err != nil {
+			return fmt.Errorf("invalid issue key %q, expected %s-N", args[1], strings.ToUpper(key))
+		}
+		client := newClient()
+		signIn(client)
+		board, err := internal.CurrentBoard(client, strings.ToUpper(key))
+		if err != nil {
+			return err
+		}
+		issue, err := internal.GetBoardIssue(client, board, number)
+		if err != nil {
+			return err
+		}
+		name := git.BranchName(key, issue.Number, issue.Title)
+		if err := repo.CreateBranch(name); err != nil {
+			return err
+		}
+		fmt.Printf("Switched to new branch %s\n", name)
+		return nil
+
+	case "commit-msg":
+		// Runs from the commit-msg hook, so it must not need Supabase.
+		if len(args) != 2 {
+			return usage
+		}
+		key, err := gitBoardKey(repo)
+		if err != nil {
+			return err
+		}
+		msg, err := os.ReadFile(args[1])
+		if err != nil {
+			return err
+		}
+		return git.ValidateCommitMessage(string(msg), key)
+
+	case "install-hook":
+		key, err := gitBoardKey(repo)
+		if err != nil {
+			return err
+		}
+		// Remember the board so the hook works without ZELLO_BOARD.
+		if err := repo.SetConfig("zello.board", key); err != nil {
+			return err
+		}
+		path, err := repo.InstallCommitMsgHook()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Installed %s\n", path)
+		return nil
+
+	case "sync":
+		fs := flag.NewFlagSet("git sync", flag.ContinueOnError)
+		dryRun := fs.Bool("dry-run", false, "print what would change without changing it")
+		limit := fs.Int("limit", 50, "maximum number of commits to read")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		key, err := gitBoardKey(repo)
+		if err != nil {
+			return err
+		}
+		commits, err := repo.Log(fs.Arg(0), *limit)
+		if err != nil {
+			return err
+		}
+		return syncCommits(commits, key, *dryRun)
+	}
+	return fmt.Errorf("unknown git command %q", args[0])
+}
+
+// syncCommits closes or links the issues referenced by commits, oldest
+// first, reporting failures per reference and carrying on.
+func syncCommits(commits []git.Commit, boardKey string, dryRun bool) error {
+	type pending struct {
+		commit git.Commit
+		ref    git.IssueRef
+	}
+	var refs []pending
+	for i := len(commits) - 1; i >= 0; i-- {
+		for _, ref := range git.ParseRefs(commits[i].Message(), boardKey) {
+			refs = append(refs, pending{commits[i], ref})
+		}
+	}
+	if len(refs) == 0 {
+		fmt.Println("No issue references found.")
+		return nil
+	}
+
+	if dryRun {
+		for _, p := range refs {
+			verb := "link"
+			if p.ref.Action == git.RefClose {
+				verb = "close"
+			}
+			fmt.Printf("%s %s %s (%s)\n", p.commit.Hash[:7], verb, p.ref.Key(), p.commit.Subject)
+		}
+		return nil
+	}
+
+	client := newClient()
+	userID := signIn(client)
+	board, err := internal.CurrentBoard(client, strings.ToUpper(boardKey))
+	if err != nil {
+		return err
+	}
+	// Keys carry the number on the board, not the issue id.
+	issueIDs := map[int]int{}
+	failed := 0
+	for _, p := range refs {
+		issueID, ok := issueIDs[p.ref.Number]
+		if !ok {
+			issue, err := internal.GetBoardIssue(client, board, p.ref.Number)
+			if err != nil {
+				failed++
+				fmt.Printf("%s %s: %v\n", p.commit.Hash[:7], p.ref.Key(), err)
+				continue
+			}
+			issueID = issue.ID
+			issueIDs[p.ref.Number] = issueID
+		}
+		changed, err := internal.LinkCommit(client, issueID, userID, p.commit.Hash, p.commit.Subject, p.ref.Action == git.RefClose)
+		switch {
+		case err != nil:
+			failed++
+			fmt.Printf("%s %s: %v\n", p.commit.Hash[:7], p.ref.Key(), err)
+		case changed:
+			fmt.Printf("%s %s updated\n", p.commit.Hash[:7], p.ref.Key())
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d issue references failed", failed)
+	}
+	return nil
+}
+
+// gitBoardKey finds the board whose keys commits use: ZELLO_BOARD, then the
+// repository's zello.board git config.
+func gitBoardKey(repo git.Repo) (string, error) {
+	if key := os.Getenv("ZELLO_BOARD"); key != "" {
+		return key, nil
+	}
+	if key := repo.Config("zello.board"); key != "" {
+		return key, nil
+	}
+	return "", errors.New("no board key: set ZELLO_BOARD or run `git config zello.board KEY`")
+}
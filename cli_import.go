@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"zel/lo/internal"
+	"zel/lo/supabase"
+)
+
+// runExportCommand writes every issue on the current board, with comments
+// and labels, as JSON:
+//
+//	zello export [--out FILE] [--all]
+func runExportCommand(client *supabase.Client, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	out := fs.String("out", "", "write to FILE instead of stdout")
+	all := fs.Bool("all", false, "export issues from every board")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	signIn(client)
+
+	var board *internal.Board
+	if !*all {
+		b, err := internal.CurrentBoard(client, os.Getenv("ZELLO_BOARD"))
+		if err != nil {
+			return err
+		}
+		board = b
+	}
+	file, err := internal.ExportIssues(client, board)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode export: %w", err)
+	}
+	data = append(data, '\n')
+
+	if *out == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d issues to %s\n", len(file.Issues), *out)
+	return nil
+}
+
+// runImportCommand reads issues from FILE, or stdin when FILE is -, into the
+// current board:
+//
+//	zello import [--format zello|csv|github|trello] [--map field=column,...] [--dry-run] FILE
+//
+// Imports are keyed by external ID, so running the same import again only
+// applies what changed.
+func runImportCommand(client *supabase.Client, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := fs.String("format", "zello", "input format: zello, csv, github or trello")
+	columns := fs.String("map", "", "CSV column mapping, e.g. external_id=Key,title=Summary")
+	dryRun := fs.Bool("dry-run", false, "report what would change without writing anything")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: zello import [--format zello|csv|github|trello] [--map field=column,...] [--dry-run] FILE")
+	}
+
+	var data []byte
+	var err error
+	if path := fs.Arg(0); path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read import: %w", err)
+	}
+
+	var items []internal.ImportIssue
+	switch *format {
+	case "zello":
+		items, err = internal.ParseZelloExport(data)
+	case "csv":
+		mapping, mapErr := internal.ParseColumnMap(*columns)
+		if mapErr != nil {
+			return mapErr
+		}
+		items, err = internal.ParseCSV(bytes.NewReader(data), mapping)
+	case "github":
+		items, err = internal.ParseGitHubIssues(data)
+	case "trello":
+		items, err = internal.ParseTrelloBoard(data)
+	default:
+		return fmt.Errorf("unknown import format %q", *format)
+	}
+	if err != nil {
+		return err
+	}
+
+	userID := signIn(client)
+	opts := internal.ImportOptions{UserID: userID, DryRun: *dryRun}
+	if board, err := internal.CurrentBoard(client, os.Getenv("ZELLO_BOARD")); err == nil {
+		opts.BoardID = &board.ID
+	}
+	report, err := internal.ImportIssues(client, items, opts)
+	if err != nil {
+		return err
+	}
+
+	verb := "Imported"
+	if report.DryRun {
+		verb = "Would import"
+		for _, id := range report.Created {
+			fmt.Printf("create %s\n", id)
+		}
+		for _, id := range report.Updated {
+			fmt.Printf("update %s\n", id)
+		}
+	}
+	fmt.Printf("%s %d issues: %d new, %d updated, %d unchanged, %d new comments\n",
+		verb, len(items), len(report.Created), len(report.Updated), len(report.Unchanged), report.Comments)
+	return nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"zel/lo/internal"
+	"zel/lo/supabase"
+
+	"github.com/google/uuid"
+)
+
+// runMFACommand manages the authenticator apps that sign-ins are checked
+// against:
+//
+//	zello mfa list
+//	zello mfa add [--name NAME]
+//	zello mfa remove ID
+func runMFACommand(client *supabase.Client, args []string) error {
+	usage := fmt.Errorf("usage: zello mfa list | zello mfa add [--name NAME] | zello mfa remove ID")
+	if len(args) == 0 {
+		return usage
+	}
+	switch args[0] {
+	case "list":
+		signIn(client)
+		user, err := internal.CurrentUser(client)
+		if err != nil {
+			return err
+		}
+		factors := internal.VerifiedFactors(*user)
+		if len(factors) == 0 {
+			fmt.Println("no authenticator apps; add one with zello mfa add")
+			return nil
+		}
+		for _, f := range factors {
+			fmt.Printf("%s  %-20s added %s\n", f.ID, f.FriendlyName, f.CreatedAt.Format("2006-01-02"))
+		}
+		return nil
+
+	case "add":
+		fs := flag.NewFlagSet("mfa add", flag.ContinueOnError)
+		name := fs.String("name", "", "name to tell this app apart from others")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		signIn(client)
+		enrolment, err := internal.EnrollTOTP(client, *name)
+		if err != nil {
+			return err
+		}
+		qr, err := internal.TerminalQR(enrolment.TOTP.URI)
+		if err != nil {
+			return err
+		}
+		fmt.Print(qr)
+		fmt.Println("scan the code with your authenticator app, or enter this key by hand:")
+		fmt.Println(enrolment.TOTP.Secret)
+		fmt.Println(enrolment.TOTP.URI)
+		fmt.Println("enter the code the app shows:")
+		var code string
+		fmt.Scanf("%s", &code)
+		if err := internal.ConfirmTOTP(client, enrolment.ID, code); err != nil {
+			// Leave no half-enrolled factor behind to clash with a retry.
+			internal.RemoveFactor(client, enrolment.ID)
+			return err
+		}
+		fmt.Println("Authenticator app added; signing in now asks for its code.")
+		return nil
+
+	case "remove":
+		if len(args) != 2 {
+			return usage
+		}
+		id, err := uuid.Parse(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid factor id %q", args[1])
+		}
+		signIn(client)
+		if err := internal.RemoveFactor(client, id); err != nil {
+			return err
+		}
+		fmt.Println("Authenticator app removed.")
+		return nil
+	}
+	return usage
+}
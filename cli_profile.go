@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"zel/lo/internal"
+	"zel/lo/supabase"
+)
+
+// runProfileCommand shows or changes the signed-in user's profile:
+//
+//	zello profile [--name NAME] [--avatar IMAGE]
+func runProfileCommand(client *supabase.Client, args []string) error {
+	fs := flag.NewFlagSet("profile", flag.ContinueOnError)
+	name := fs.String("name", "", "new display name")
+	avatar := fs.String("avatar", "", "image file to upload as the avatar")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	nameSet := false
+	fs.Visit(func(f *flag.Flag) { nameSet = nameSet || f.Name == "name" })
+
+	userID := signIn(client)
+	var profile *internal.Profile
+	var err error
+	if *avatar != "" {
+		data, err := os.ReadFile(*avatar)
+		if err != nil {
+			return err
+		}
+		if profile, err = internal.UploadAvatar(client, userID, data); err != nil {
+			return err
+		}
+	}
+	if nameSet {
+		profile, err = internal.UpdateProfile(client, userID, internal.ProfileUpdate{DisplayName: name})
+	} else if profile == nil {
+		profile, err = internal.GetProfile(client, userID)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("id:     %s\n", profile.ID)
+	fmt.Printf("name:   %s\n", profile.DisplayName)
+	fmt.Printf("avatar: %s\n", profile.AvatarURL)
+	return nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"zel/lo/internal"
+	"zel/lo/supabase"
+)
+
+// runSyncCommand pushes the issues and comments of a local database to the
+// current Supabase board:
+//
+//	zello sync [--db FILE] [--dry-run]
+//
+// Synced issues remember where they came from, so syncing again updates
+// them instead of creating copies. Changes made on the board are not pulled
+// back.
+func runSyncCommand(client *supabase.Client, args []string) error {
+	fs := flag.NewFlagSet("sync", flag.ContinueOnError)
+	db := fs.String("db", "", "local database (default $ZELLO_LOCAL_DB or the one zello --local uses)")
+	dryRun := fs.Bool("dry-run", false, "report what would change without writing anything")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	path, err := localDBPath(*db)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("no local database at %s", path)
+	}
+	ctx := context.Background()
+	store, err := internal.OpenSQLiteStore(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	items, err := store.SyncItems(ctx)
+	if err != nil {
+		return err
+	}
+
+	userID := signIn(client)
+	board, err := internal.CurrentBoard(client, os.Getenv("ZELLO_BOARD"))
+	if err != nil {
+		return err
+	}
+	report, err := internal.ImportIssues(client, items, internal.ImportOptions{
+		BoardID: &board.ID,
+		UserID:  userID,
+		DryRun:  *dryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	verb := "Synced"
+	if report.DryRun {
+		verb = "Would sync"
+	}
+	fmt.Printf("%s %d issues to %s: %d new, %d updated, %d unchanged, %d new comments\n",
+		verb, len(items), board.Key, len(report.Created), len(report.Updated), len(report.Unchanged), report.Comments)
+	return nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"zel/lo/internal"
+	"zel/lo/supabase"
+)
+
+// runTemplateCommand manages the issue templates of the board in
+// ZELLO_BOARD (or the first board):
+//
+//	zello template list
+//	zello template add --name NAME [--prefix PREFIX] [--labels a,b] [--body FILE]
+//	zello template remove NAME
+//
+// Bodies may use {{field}} placeholders; the TUI asks for each one when the
+// template is used, filling date, author and board itself.
+func runTemplateCommand(client *supabase.Client, args []string) error {
+	usage := fmt.Errorf("usage: zello template list | zello template add --name NAME [--prefix PREFIX] [--labels a,b] [--body FILE] | zello template remove NAME")
+	if len(args) == 0 {
+		return usage
+	}
+	switch args[0] {
+	case "list":
+		signIn(client)
+		board, err := internal.CurrentBoard(client, os.Getenv("ZELLO_BOARD"))
+		if err != nil {
+			return err
+		}
+		templates, err := internal.ListIssueTemplates(client, board.ID)
+		if err != nil {
+			return err
+		}
+		for _, t := range templates {
+			line := fmt.Sprintf("%-20s prefix %q", t.Name, t.TitlePrefix)
+			if len(t.Labels) > 0 {
+				line += "  labels " + strings.Join(t.Labels, ",")
+			}
+			if fields := t.Fields(); len(fields) > 0 {
+				line += "  fields " + strings.Join(fields, ",")
+			}
+			fmt.Println(line)
+		}
+		if len(templates) > 0 && templates[0].ID == 0 {
+			fmt.Printf("(built-in defaults; %s has no templates of its own)\n", board.Key)
+		}
+		return nil
+
+	case "add":
+		fs := flag.NewFlagSet("template add", flag.ContinueOnError)
+		name := fs.String("name", "", "template name, replacing the board's template of that name")
+		prefix := fs.String("prefix", "", "text new titles start with, e.g. \"[Bug] \"")
+		labels := fs.String("labels", "", "comma separated labels for new issues")
+		bodyFile := fs.String("body", "", "Markdown file with the body (opens $VISUAL/$EDITOR when omitted)")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if strings.TrimSpace(*name) == "" {
+			return fmt.Errorf("--name is required")
+		}
+		var body string
+		if *bodyFile != "" {
+			data, err := os.ReadFile(*bodyFile)
+			if err != nil {
+				return err
+			}
+			body = string(data)
+		} else {
+			text, err := internal.EditText("")
+			if err != nil {
+				return err
+			}
+			body = text
+		}
+		signIn(client)
+		board, err := internal.CurrentBoard(client, os.Getenv("ZELLO_BOARD"))
+		if err != nil {
+			return err
+		}
+		t := internal.IssueTemplate{BoardID: board.ID, Name: *name, TitlePrefix: *prefix, Body: body}
+		for _, l := range strings.Split(*labels, ",") {
+			if l = strings.TrimSpace(l); l != "" {
+				t.Labels = append(t.Labels, l)
+			}
+		}
+		saved, err := internal.SaveIssueTemplate(client, t)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Saved template %q on %s\n", saved.Name, board.Key)
+		return nil
+
+	case "remove":
+		if len(args) != 2 {
+			return usage
+		}
+		signIn(client)
+		board, err := internal.CurrentBoard(client, os.Getenv("ZELLO_BOARD"))
+		if err != nil {
+			return err
+		}
+		if err := internal.DeleteIssueTemplate(client, board.ID, args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("Removed template %q from %s\n", args[1], board.Key)
+		return nil
+	}
+	return usage
+}
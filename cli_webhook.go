@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"zel/lo/internal"
+	"zel/lo/supabase"
+	"zel/lo/webhook"
+)
+
+func runWebhookCommand(args []string) error {
+	usage := fmt.Errorf("usage: zello webhook add URL [--events a,b] [--secret S] | list | rm ID | ping ID | log [--id ID] [--limit N] | dispatch [--once] [--interval D] | listen [--addr ADDR] --secret S")
+	if len(args) == 0 {
+		return usage
+	}
+
+	if args[0] == "listen" {
+		// A local receiver for trying out subscriptions; it needs no Supabase.
+		fs := flag.NewFlagSet("webhook listen", flag.ContinueOnError)
+		addr := fs.String("addr", "127.0.0.1:8787", "address to listen on")
+		secret := fs.String("secret", "", "webhook secret used to verify signatures")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *secret == "" {
+			return fmt.Errorf("--secret is required")
+		}
+		receiver := webhook.Receiver{
+			Secret: *secret,
+			Handle: func(event, id string, body []byte) {
+				var pretty bytes.Buffer
+				if json.Indent(&pretty, body, "", "  ") != nil {
+					pretty.Reset()
+					pretty.Write(body)
+				}
+				fmt.Printf("%s %s %s\n%s\n", time.Now().Format(time.TimeOnly), event, id, pretty.String())
+			},
+			Reject: func(r *http.Request, err error) {
+				fmt.Printf("%s rejected request from %s: %v\n", time.Now().Format(time.TimeOnly), r.RemoteAddr, err)
+			},
+		}
+		fmt.Printf("Listening for webhooks on http://%s/\n", *addr)
+		return http.ListenAndServe(*addr, receiver)
+	}
+
+	var client *supabase.Client
+	switch args[0] {
+	case "ping", "dispatch":
+		// Deliveries read the secrets, which signed-in users cannot.
+		c, err := newServiceClient()
+		if err != nil {
+			return err
+		}
+		client = c
+	default:
+		client = newClient()
+		signIn(client)
+	}
+	board, err := internal.CurrentBoard(client, os.Getenv("ZELLO_BOARD"))
+	if err != nil {
+		return err
+	}
+	hookByID := func(arg string) (internal.Webhook, error) {
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			return internal.Webhook{}, fmt.Errorf("invalid webhook id %q", arg)
+		}
+		hooks, err := internal.ListWebhooks(client, board.ID)
+		if err != nil {
+			return internal.Webhook{}, err
+		}
+		for _, h := range hooks {
+			if h.ID == id {
+				return h, nil
+			}
+		}
+		return internal.Webhook{}, fmt.Errorf("webhook %d not found on board %s", id, board.Key)
+	}
+
+	switch args[0] {
+	case "add":
+		if len(args) < 2 {
+			return usage
+		}
+		fs := flag.NewFlagSet("webhook add", flag.ContinueOnError)
+		events := fs.String("events", "", "comma separated events (default all): "+strings.Join(internal.WebhookEvents, ", "))
+		secret := fs.String("secret", "", "signing secret (generated when omitted)")
+		if err := fs.Parse(args[2:]); err != nil {
+			return err
+		}
+		var list []string
+		for _, e := range strings.Split(*events, ",") {
+			if e = strings.TrimSpace(e); e != "" {
+				list = append(list, e)
+			}
+		}
+		h, err := internal.CreateWebhook(client, board.ID, args[1], list, *secret)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created webhook %d for %s\nSecret (shown only now): %s\n", h.ID, strings.Join(h.Events, ", "), h.Secret)
+		return nil
+
+	case "list":
+		hooks, err := internal.ListWebhooks(client, board.ID)
+		if err != nil {
+			return err
+		}
+		for _, h := range hooks {
+			state := "active"
+			if !h.Active {
+				state = "paused"
+			}
+			fmt.Printf("%-4d %-7s %s  %s\n", h.ID, state, h.URL, strings.Join(h.Events, ","))
+		}
+		return nil
+
+	case "rm":
+		if len(args) != 2 {
+			return usage
+		}
+		h, err := hookByID(args[1])
+		if err != nil {
+			return err
+		}
+		return internal.DeleteWebhook(client, h.ID)
+
+	case "ping":
+		if len(args) != 2 {
+			return usage
+		}
+		h, err := hookByID(args[1])
+		if err != nil {
+			return err
+		}
+		d, err := internal.PingWebhook(context.Background(), client, webhook.Sender{}, h)
+		if err != nil {
+			return err
+		}
+		printDelivery(*d)
+		return nil
+
+	case "log":
+		fs := flag.NewFlagSet("webhook log", flag.ContinueOnError)
+		id := fs.Int("id", 0, "only show deliveries of this webhook")
+		limit := fs.Int("limit", 20, "number of deliveries to show")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		deliveries, err := internal.ListWebhookDeliveries(client, *id, *limit)
+		if err != nil {
+			return err
+		}
+		for _, d := range deliveries {
+			printDelivery(d)
+		}
+		return nil
+
+	case "dispatch":
+		fs := flag.NewFlagSet("webhook dispatch", flag.ContinueOnError)
+		once := fs.Bool("once", false, "deliver pending events and exit")
+		interval := fs.Duration("interval", 10*time.Second, "how often to look for new events")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		for {
+			n, err := internal.DispatchWebhooks(ctx, client, webhook.Sender{}, *board)
+			if err != nil && ctx.Err() == nil {
+				if *once {
+					return err
+				}
+				log.Println("dispatch:", err)
+			}
+			if n > 0 {
+				log.Printf("delivered %d events", n)
+			}
+			if *once {
+				return nil
+			}
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(*interval):
+			}
+		}
+	}
+	return fmt.Errorf("unknown webhook command %q", args[0])
+}
+
+// newServiceClient connects with SUPABASE_SERVICE_ROLE_KEY instead of
+// signing in. Keep that key on the machine running the dispatcher.
+func newServiceClient() (*supabase.Client, error) {
+	godotenv.Load()
+	key := os.Getenv("SUPABASE_SERVICE_ROLE_KEY")
+	if key == "" {
+		return nil, fmt.Errorf("SUPABASE_SERVICE_ROLE_KEY is required to deliver webhooks")
+	}
+	return supabase.NewClient(os.Getenv("SUPABASE_URL"), key, &supabase.ClientOptions{})
+}
+
+func printDelivery(d internal.WebhookDelivery) {
+	result := strconv.Itoa(d.StatusCode)
+	if d.Error != "" {
+		result = d.Error
+	}
+	fmt.Printf("%-6d hook %-4d %-22s %-20s attempts %d  %s\n", d.ID, d.WebhookID, d.Event, shortTime(d.CreatedAt), d.Attempts, result)
+}
+
+func shortTime(ts string) string {
+	if t, err := time.Parse(time.RFC3339, ts); err == nil {
+		return t.Local().Format("2006-01-02 15:04:05")
+	}
+	return ts
+}
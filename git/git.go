@@ -0,0 +1,119 @@
+// Package git runs the git commands behind zello's branch and commit
+// helpers against a working tree.
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Repo is a git working tree. An empty Dir means the current directory.
+type Repo struct {
+	Dir string
+}
+
+type Commit struct {
+	Hash    string
+	Subject string
+	Body    string
+}
+
+// Message is the full commit message.
+func (c Commit) Message() string {
+	if c.Body == "" {
+		return c.Subject
+	}
+	return c.Subject + "\n\n" + c.Body
+}
+
+func (r Repo) run(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.Dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return stdout.String(), nil
+}
+
+// CreateBranch creates name from the current HEAD and checks it out.
+func (r Repo) CreateBranch(name string) error {
+	_, err := r.run("checkout", "-b", name)
+	return err
+}
+
+// Config returns a git config value, or "" when it is unset.
+func (r Repo) Config(key string) string {
+	out, err := r.run("config", "--get", key)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+// SetConfig sets a git config value in the repository.
+func (r Repo) SetConfig(key, value string) error {
+	_, err := r.run("config", key, value)
+	return err
+}
+
+// HooksDir returns the directory git runs hooks from.
+func (r Repo) HooksDir() (string, error) {
+	out, err := r.run("rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	dir := strings.TrimSpace(out)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(r.Dir, dir)
+	}
+	return dir, nil
+}
+
+// Record and field separators that cannot appear in commit messages.
+const (
+	logRecordSep = "\x1e"
+	logFieldSep  = "\x1f"
+)
+
+// Log returns the commits in revRange (anything git log accepts, such as
+// "main..HEAD"), newest first. An empty range means HEAD, and a positive
+// limit caps the number of commits.
+func (r Repo) Log(revRange string, limit int) ([]Commit, error) {
+	args := []string{"log", "--format=%H" + logFieldSep + "%s" + logFieldSep + "%b" + logRecordSep}
+	if limit > 0 {
+		args = append(args, fmt.Sprintf("--max-count=%d", limit))
+	}
+	if revRange != "" {
+		args = append(args, revRange)
+	}
+	out, err := r.run(args...)
+	if err != nil {
+		return nil, err
+	}
+	var commits []Commit
+	for _, rec := range strings.Split(out, logRecordSep) {
+		rec = strings.TrimLeft(rec, "\n")
+		if rec == "" {
+			continue
+		}
+		fields := strings.SplitN(rec, logFieldSep, 3)
+		if len(fields) < 3 {
+			continue
+		}
+		commits = append(commits, Commit{
+			Hash:    fields[0],
+			Subject: fields[1],
+			Body:    strings.TrimSpace(fields[2]),
+		})
+	}
+	return commits, nil
+}
@@ -0,0 +1,105 @@
+package git
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// newRepo initialises an empty repository in a temporary directory.
+func newRepo(t *testing.T) Repo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := Repo{Dir: t.TempDir()}
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.name", "Test"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "commit.gpgsign", "false"},
+		{"config", "core.hooksPath", ".git/hooks"},
+	} {
+		if _, err := repo.run(args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return repo
+}
+
+// commit records an empty commit with message.
+func commit(t *testing.T, repo Repo, message string) {
+	t.Helper()
+	if _, err := repo.run("commit", "-q", "--allow-empty", "--no-verify", "-m", message); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLog(t *testing.T) {
+	repo := newRepo(t)
+	commit(t, repo, "First")
+	commit(t, repo, "Second\n\nWith a body\nover two lines")
+	commit(t, repo, "Third")
+
+	commits, err := repo.Log("", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var subjects []string
+	for _, c := range commits {
+		subjects = append(subjects, c.Subject)
+		if len(c.Hash) != 40 {
+			t.Errorf("hash %q is not a full hash", c.Hash)
+		}
+	}
+	if got := strings.Join(subjects, ","); got != "Third,Second,First" {
+		t.Errorf("subjects = %s, want newest first", got)
+	}
+	if got, want := commits[1].Message(), "Second\n\nWith a body\nover two lines"; got != want {
+		t.Errorf("message = %q, want %q", got, want)
+	}
+
+	limited, err := repo.Log("HEAD~1..HEAD", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(limited) != 1 || limited[0].Subject != "Third" {
+		t.Errorf("range log = %+v, want only Third", limited)
+	}
+	if capped, _ := repo.Log("", 2); len(capped) != 2 {
+		t.Errorf("limit 2 returned %d commits", len(capped))
+	}
+}
+
+func TestCreateBranch(t *testing.T) {
+	repo := newRepo(t)
+	commit(t, repo, "Initial")
+
+	name := BranchName("ZEL", 42, "Fix login on Safari")
+	if err := repo.CreateBranch(name); err != nil {
+		t.Fatal(err)
+	}
+	out, err := repo.run("rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(out); got != "zel-42-fix-login-on-safari" {
+		t.Errorf("checked out %q", got)
+	}
+	if err := repo.CreateBranch(name); err == nil {
+		t.Error("creating an existing branch succeeded")
+	}
+}
+
+func TestConfig(t *testing.T) {
+	repo := newRepo(t)
+	if got := repo.Config("zello.board"); got != "" {
+		t.Errorf("unset config = %q", got)
+	}
+	if err := repo.SetConfig("zello.board", "ZEL"); err != nil {
+		t.Fatal(err)
+	}
+	if got := repo.Config("zello.board"); got != "ZEL" {
+		t.Errorf("config = %q, want ZEL", got)
+	}
+}
@@ -0,0 +1,151 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type RefAction int
+
+const (
+	// RefMention links the commit to the issue.
+	RefMention RefAction = iota
+	// RefClose closes the issue, e.g. "fixes ZEL-42".
+	RefClose
+)
+
+// IssueRef is an issue key found in a commit message. Number is the
+// issue's number on the board, not its global id.
+type IssueRef struct {
+	BoardKey string
+	Number   int
+	Action   RefAction
+}
+
+func (r IssueRef) Key() string {
+	return fmt.Sprintf("%s-%d", r.BoardKey, r.Number)
+}
+
+var (
+	issueKeyPattern = regexp.MustCompile(`(?i)\b([a-z][a-z0-9]*)-(\d+)\b`)
+	closingPattern  = regexp.MustCompile(`(?i)\b(?:fix(?:e[sd])?|close[sd]?|resolve[sd]?)[:\s]+((?:[a-z][a-z0-9]*-\d+[,\s]*(?:and\s+)?)+)`)
+)
+
+// ParseRefs finds the issue keys for boardKey in a commit message. Keys
+// after fixes, closes or resolves (and their variants) close the issue; any
+// other mention links it. Each issue appears once, with closing winning.
+func ParseRefs(message, boardKey string) []IssueRef {
+	closing := map[int]bool{}
+	for _, m := range closingPattern.FindAllStringSubmatch(message, -1) {
+		for _, n := range matchBoard(m[1], boardKey) {
+			closing[n] = true
+		}
+	}
+
+	var refs []IssueRef
+	seen := map[int]bool{}
+	for _, n := range matchBoard(message, boardKey) {
+		if seen[n] {
+			continue
+		}
+		seen[n] = true
+		action := RefMention
+		if closing[n] {
+			action = RefClose
+		}
+		refs = append(refs, IssueRef{BoardKey: strings.ToUpper(boardKey), Number: n, Action: action})
+	}
+	return refs
+}
+
+func matchBoard(text, boardKey string) []int {
+	var numbers []int
+	for _, m := range issueKeyPattern.FindAllStringSubmatch(text, -1) {
+		if !strings.EqualFold(m[1], boardKey) {
+			continue
+		}
+		if n, err := strconv.Atoi(m[2]); err == nil {
+			numbers = append(numbers, n)
+		}
+	}
+	return numbers
+}
+
+// ValidateCommitMessage fails unless the message references an issue on the
+// board. Merge and fixup commits are let through.
+func ValidateCommitMessage(message, boardKey string) error {
+	var lines []string
+	for _, l := range strings.Split(message, "\n") {
+		if !strings.HasPrefix(l, "#") {
+			lines = append(lines, l)
+		}
+	}
+	message = strings.TrimSpace(strings.Join(lines, "\n"))
+	for _, prefix := range []string{"Merge ", "fixup! ", "squash! ", "Revert "} {
+		if strings.HasPrefix(message, prefix) {
+			return nil
+		}
+	}
+	if len(ParseRefs(message, boardKey)) == 0 {
+		return fmt.Errorf("commit message must reference an issue, e.g. %s-42", strings.ToUpper(boardKey))
+	}
+	return nil
+}
+
+// maxSlugLen keeps branch names readable.
+const maxSlugLen = 40
+
+// BranchName builds a branch like zel-42-short-title for the issue with
+// number on the board.
+func BranchName(boardKey string, number int, title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteRune('-')
+			dash = true
+		}
+		if b.Len() >= maxSlugLen {
+			break
+		}
+	}
+	slug := strings.Trim(b.String(), "-")
+	name := fmt.Sprintf("%s-%d", strings.ToLower(boardKey), number)
+	if slug != "" {
+		name += "-" + slug
+	}
+	return name
+}
+
+// commitMsgHook runs zello's validation from git's commit-msg hook.
+const commitMsgHook = `#!/bin/sh
+# Installed by zello: require commit messages to reference an issue.
+exec zello git commit-msg "$1"
+`
+
+// InstallCommitMsgHook writes the commit-msg hook into the repository,
+// refusing to overwrite a hook that zello did not install.
+func (r Repo) InstallCommitMsgHook() (string, error) {
+	dir, err := r.HooksDir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "commit-msg")
+	if existing, err := os.ReadFile(path); err == nil && string(existing) != commitMsgHook {
+		return "", fmt.Errorf("%s already exists; remove it or add `zello git commit-msg \"$1\"` to it", path)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(commitMsgHook), 0o755); err != nil {
+		return "", err
+	}
+	return path, nil
+}
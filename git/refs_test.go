@@ -0,0 +1,184 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRefsFromLog(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    []IssueRef
+	}{
+		{"closing", "fixes ZEL-42", []IssueRef{{"ZEL", 42, RefClose}}},
+		{"mention", "Tidy the parser for ZEL-7", []IssueRef{{"ZEL", 7, RefMention}}},
+		{"lower case key", "closes zel-3", []IssueRef{{"ZEL", 3, RefClose}}},
+		{"several closed", "Resolves ZEL-1, ZEL-2 and ZEL-3", []IssueRef{
+			{"ZEL", 1, RefClose}, {"ZEL", 2, RefClose}, {"ZEL", 3, RefClose},
+		}},
+		{"mention and close", "Refactor for ZEL-5\n\nFixes: ZEL-6", []IssueRef{
+			{"ZEL", 5, RefMention}, {"ZEL", 6, RefClose},
+		}},
+		{"closing wins over mention", "ZEL-9 follow-up\n\nfixed ZEL-9", []IssueRef{{"ZEL", 9, RefClose}}},
+		{"wrong board key", "fixes OPS-42", nil},
+		{"wrong board among right", "fixes OPS-1 and ZEL-2", []IssueRef{{"ZEL", 2, RefClose}}},
+		{"no key", "Bump dependencies", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newRepo(t)
+			commit(t, repo, tt.message)
+			commits, err := repo.Log("", 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := ParseRefs(commits[0].Message(), "zel")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseRefs(%q) = %+v, want %+v", tt.message, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateCommitMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		ok      bool
+	}{
+		{"reference", "ZEL-12 Add sprint view", true},
+		{"closing reference", "Add sprint view\n\nCloses ZEL-12", true},
+		{"missing", "Add sprint view", false},
+		{"other board", "OPS-12 Add sprint view", false},
+		{"only in comment", "Add sprint view\n# ZEL-12", false},
+		{"merge", "Merge branch 'main' into zel-12-sprints", true},
+		{"fixup", "fixup! Add sprint view", true},
+		{"revert", "Revert \"Add sprint view\"", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCommitMessage(tt.message, "ZEL")
+			if tt.ok && err != nil {
+				t.Errorf("rejected: %v", err)
+			}
+			if !tt.ok && err == nil {
+				t.Error("accepted")
+			}
+		})
+	}
+}
+
+func TestBranchName(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Fix login on Safari", "zel-42-fix-login-on-safari"},
+		{"  Crash: nil map (again!)  ", "zel-42-crash-nil-map-again"},
+		{"", "zel-42"},
+		{"!!!", "zel-42"},
+		{"Émoji 🚀 support", "zel-42-moji-support"},
+		{strings.Repeat("word ", 20), "zel-42-word-word-word-word-word-word-word-word"},
+	}
+	for _, tt := range tests {
+		if got := BranchName("ZEL", 42, tt.title); got != tt.want {
+			t.Errorf("BranchName(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestInstallCommitMsgHook(t *testing.T) {
+	repo := newRepo(t)
+	path, err := repo.InstallCommitMsgHook()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(repo.Dir, ".git", "hooks", "commit-msg"); path != want {
+		t.Errorf("hook path = %s, want %s", path, want)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm()&0o100 == 0 {
+		t.Error("hook is not executable")
+	}
+
+	// Reinstalling our own hook is fine; someone else's is left alone.
+	if _, err := repo.InstallCommitMsgHook(); err != nil {
+		t.Errorf("reinstall: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("#!/bin/sh\nexit 0\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.InstallCommitMsgHook(); err == nil {
+		t.Error("overwrote a foreign hook")
+	}
+}
+
+// TestMain lets the test binary stand in for zello when the commit-msg
+// hook runs it, so hook tests go through ValidateCommitMessage.
+func TestMain(m *testing.M) {
+	if os.Getenv("ZELLO_HOOK_TEST") == "" {
+		os.Exit(m.Run())
+	}
+	args := os.Args[1:]
+	if len(args) != 3 || args[0] != "git" || args[1] != "commit-msg" {
+		fmt.Fprintf(os.Stderr, "unexpected arguments %q\n", args)
+		os.Exit(2)
+	}
+	message, err := os.ReadFile(args[2])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := ValidateCommitMessage(string(message), "ZEL"); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func TestCommitMsgHook(t *testing.T) {
+	repo := newRepo(t)
+	if _, err := repo.InstallCommitMsgHook(); err != nil {
+		t.Fatal(err)
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bin := t.TempDir()
+	stub := fmt.Sprintf("#!/bin/sh\nZELLO_HOOK_TEST=1 exec '%s' \"$@\"\n", exe)
+	if err := os.WriteFile(filepath.Join(bin, "zello"), []byte(stub), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	tests := []struct {
+		message string
+		ok      bool
+	}{
+		{"Add sprint view", false},
+		{"OPS-12 Add sprint view", false},
+		{"ZEL-12 Add sprint view", true},
+		{"Add sprint view\n\nFixes zel-12", true},
+		{"Merge branch 'zel-12-sprints'", true},
+	}
+	for _, tt := range tests {
+		_, err := repo.run("commit", "-q", "--allow-empty", "-m", tt.message)
+		switch {
+		case tt.ok && err != nil:
+			t.Errorf("hook rejected %q: %v", tt.message, err)
+		case !tt.ok && err == nil:
+			t.Errorf("hook accepted %q", tt.message)
+		case !tt.ok && !strings.Contains(err.Error(), "must reference an issue"):
+			t.Errorf("hook rejected %q for another reason: %v", tt.message, err)
+		}
+	}
+}
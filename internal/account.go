@@ -0,0 +1,156 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"zel/lo/supabase"
+
+	"github.com/supabase-community/auth-go/types"
+)
+
+// ValidateEmail reports whether email is a bare address such as
+// ann@example.com.
+func ValidateEmail(email string) error {
+	if email == "" {
+		return fmt.Errorf("email required")
+	}
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || !strings.Contains(email[strings.LastIndex(email, "@"):], ".") {
+		return fmt.Errorf("%q is not a valid email address", email)
+	}
+	return nil
+}
+
+// ValidatePhone reports whether phone is in the E.164 form GoTrue expects,
+// such as +14155550100.
+func ValidatePhone(phone string) error {
+	digits, ok := strings.CutPrefix(phone, "+")
+	if !ok || len(digits) < 8 || len(digits) > 15 || strings.Trim(digits, "0123456789") != "" {
+		return fmt.Errorf("phone must look like +14155550100")
+	}
+	return nil
+}
+
+func ValidatePassword(password string) error {
+	if len(password) < minPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+	return nil
+}
+
+// SendPasswordReset mails a recovery code and link to email. GoTrue answers
+// the same whether or not the address has an account.
+func SendPasswordReset(client *supabase.Client, email string) error {
+	if err := ValidateEmail(email); err != nil {
+		return err
+	}
+	if err := client.Auth.Recover(types.RecoverRequest{Email: email}); err != nil {
+		return fmt.Errorf("failed to send password reset: %w", err)
+	}
+	return nil
+}
+
+// ResetPassword signs in with the code from a password reset email and sets
+// a new password.
+func ResetPassword(client *supabase.Client, email, code, password string) (types.Session, error) {
+	if err := ValidatePassword(password); err != nil {
+		return types.Session{}, err
+	}
+	session, err := client.VerifyOTP(types.VerifyForUserRequest{
+		Type:  types.VerificationTypeRecovery,
+		Email: email,
+		Token: strings.TrimSpace(code),
+	})
+	if err != nil {
+		return types.Session{}, fmt.Errorf("invalid or expired code: %w", err)
+	}
+	if _, err := client.Auth.UpdateUser(types.UpdateUserRequest{Password: &password}); err != nil {
+		return types.Session{}, fmt.Errorf("failed to set new password: %w", err)
+	}
+	return session, nil
+}
+
+// SendSignInCode mails a one-time sign-in code to an existing account.
+func SendSignInCode(client *supabase.Client, email string) error {
+	if err := ValidateEmail(email); err != nil {
+		return err
+	}
+	if err := client.Auth.OTP(types.OTPRequest{Email: email}); err != nil {
+		return fmt.Errorf("failed to send sign-in code: %w", err)
+	}
+	return nil
+}
+
+// SignInWithCode signs in with the code SendSignInCode mailed out.
+func SignInWithCode(client *supabase.Client, email, code string) (types.Session, error) {
+	session, err := client.VerifyOTP(types.VerifyForUserRequest{
+		Type:  "email",
+		Email: email,
+		Token: strings.TrimSpace(code),
+	})
+	if err != nil {
+		return types.Session{}, fmt.Errorf("invalid or expired code: %w", err)
+	}
+	return session, nil
+}
+
+// CurrentUser fetches the signed-in user from GoTrue.
+func CurrentUser(client *supabase.Client) (*types.User, error) {
+	resp, err := client.Auth.GetUser()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch account: %w", err)
+	}
+	return &resp.User, nil
+}
+
+// ChangePassword replaces the signed-in user's password once the current
+// one checks out. The client keeps its session, so users who passed MFA
+// stay at aal2.
+func ChangePassword(client *supabase.Client, email, current, password string) error {
+	if err := ValidatePassword(password); err != nil {
+		return err
+	}
+	if password == current {
+		return fmt.Errorf("new password must differ from the current one")
+	}
+	if err := client.VerifyPassword(email, current); err != nil {
+		return fmt.Errorf("current password is incorrect")
+	}
+	if _, err := client.Auth.UpdateUser(types.UpdateUserRequest{Password: &password}); err != nil {
+		return fmt.Errorf("failed to change password: %w", err)
+	}
+	return nil
+}
+
+// ChangeEmail asks GoTrue to move the account to a new address. Nothing
+// changes until the link GoTrue mails out is followed; until then the
+// returned user's EmailChange holds the pending address.
+func ChangeEmail(client *supabase.Client, email string) (*types.User, error) {
+	email = strings.TrimSpace(email)
+	if err := ValidateEmail(email); err != nil {
+		return nil, err
+	}
+	resp, err := client.Auth.UpdateUser(types.UpdateUserRequest{Email: email})
+	if err != nil {
+		return nil, fmt.Errorf("failed to change email: %w", err)
+	}
+	return &resp.User, nil
+}
+
+// DeleteAccount deletes the signed-in user's account, profile and avatar
+// with the delete_account function and signs out. Their issues and
+// comments are kept.
+func DeleteAccount(ctx context.Context, client *supabase.Client, userID string) error {
+	// The avatar may never have been uploaded.
+	client.Storage.RemoveFile(AvatarBucket, []string{userID + "/avatar"})
+
+	if _, _, err := supabase.Rpc[interface{}](ctx, client, "delete_account", nil, nil); err != nil {
+		return fmt.Errorf("failed to delete account: %w", err)
+	}
+	// The session died with the account, so a failed logout changes nothing.
+	client.Auth.Logout()
+	return nil
+}
@@ -0,0 +1,44 @@
+package internal
+
+import (
+	"testing"
+
+	"zel/lo/supabase/supabasetest"
+)
+
+func TestChangePasswordKeepsMFASession(t *testing.T) {
+	srv := supabasetest.NewServer()
+	defer srv.Close()
+	const email = "ann@example.com"
+	userID := srv.AddUser(email, "first-password")
+	secret := srv.AddTOTPFactor(userID, "phone")
+
+	client := srv.Client()
+	session, err := client.SignInWithEmailPassword(email, "first-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	factor := PendingFactor(session)
+	if factor == nil {
+		t.Fatal("no factor to verify after signing in")
+	}
+	if _, err := VerifyMFA(client, factor.ID, srv.TOTPCode(secret)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ChangePassword(client, email, "wrong-password", "second-password"); err == nil {
+		t.Error("changed the password with a wrong current one")
+	}
+	if err := ChangePassword(client, email, "first-password", "second-password"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Removing a verified factor takes aal2, which a fresh password sign-in
+	// would have lost.
+	if err := RemoveFactor(client, factor.ID); err != nil {
+		t.Errorf("session lost aal2 after changing the password: %v", err)
+	}
+	if _, err := srv.Client().SignInWithEmailPassword(email, "second-password"); err != nil {
+		t.Errorf("new password does not sign in: %v", err)
+	}
+}
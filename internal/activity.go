@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/supabase-community/postgrest-go"
+
+	"zel/lo/supabase"
+)
+
+// IssueEvent is one entry in an issue's activity history. Status changes are
+// recorded by a trigger on the issues table with the old and new status in
+// FromValue and ToValue.
+type IssueEvent struct {
+	ID        int    `json:"id"`
+	IssueID   int    `json:"issue_id"`
+	UserID    string `json:"user_id"`
+	Kind      string `json:"kind"`
+	FromValue string `json:"from_value"`
+	ToValue   string `json:"to_value"`
+	CreatedAt string `json:"created_at"`
+}
+
+// EventStatus is the IssueEvent kind for status changes.
+const EventStatus = "status"
+
+// ListStatusEvents returns the status changes of the given issues, oldest
+// first.
+func ListStatusEvents(client *supabase.Client, issueIDs []int) ([]IssueEvent, error) {
+	if len(issueIDs) == 0 {
+		return nil, nil
+	}
+
+	var events []IssueEvent
+
+	_, err := client.From("issue_events").
+		Select("*", "", false).
+		Eq("kind", EventStatus).
+		In("issue_id", idStrings(issueIDs)).
+		Order("created_at", &postgrest.OrderOpts{Ascending: true}).
+		ExecuteTo(&events)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch issue activity: %w", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/supabase-community/postgrest-go"
+
+	"zel/lo/supabase"
+)
+
+// Board groups issues, sprints and settings under a short key such as ZEL.
+type Board struct {
+	ID   int    `json:"id"`
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+func ListBoards(client *supabase.Client) ([]Board, error) {
+	var boards []Board
+
+	_, err := client.From("boards").Select("*", "", false).Order("id", &postgrest.OrderOpts{Ascending: true}).ExecuteTo(&boards)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch boards: %w", err)
+	}
+
+	return boards, nil
+}
+
+// ErrNoBoards is returned by CurrentBoard when no key is given and there are
+// no boards at all.
+var ErrNoBoards = errors.New("no boards found")
+
+// CurrentBoard returns the board with the given key, or the first board when
+// key is empty.
+func CurrentBoard(client *supabase.Client, key string) (*Board, error) {
+	boards, err := ListBoards(client)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range boards {
+		if key == "" || b.Key == key {
+			return &b, nil
+		}
+	}
+	if key == "" {
+		return nil, ErrNoBoards
+	}
+	return nil, fmt.Errorf("board %q not found", key)
+}
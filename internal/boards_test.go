@@ -0,0 +1,33 @@
+package internal
+
+import (
+	"errors"
+	"testing"
+
+	"zel/lo/supabase/supabasetest"
+)
+
+func TestCurrentBoard(t *testing.T) {
+	srv := supabasetest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	if _, err := CurrentBoard(client, ""); !errors.Is(err, ErrNoBoards) {
+		t.Errorf("without boards err = %v, want ErrNoBoards", err)
+	}
+
+	srv.Seed("boards",
+		map[string]interface{}{"key": "ZEL", "name": "Zello"},
+		map[string]interface{}{"key": "OPS", "name": "Operations"},
+	)
+	if b, err := CurrentBoard(client, ""); err != nil || b.Key != "ZEL" {
+		t.Errorf("default board = %+v, %v", b, err)
+	}
+	if b, err := CurrentBoard(client, "OPS"); err != nil || b.Name != "Operations" {
+		t.Errorf("board OPS = %+v, %v", b, err)
+	}
+	// A mistyped key must not look like having no boards.
+	if _, err := CurrentBoard(client, "ZLE"); err == nil || errors.Is(err, ErrNoBoards) {
+		t.Errorf("unknown key err = %v", err)
+	}
+}
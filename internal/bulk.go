@@ -0,0 +1,225 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"zel/lo/supabase"
+)
+
+type BulkAction int
+
+const (
+	BulkSetStatus BulkAction = iota
+	BulkAddLabel
+	BulkRemoveLabel
+	BulkAssign
+	BulkDelete
+)
+
+// BulkOp is one action applied to many issues, e.g. setting the status to
+// Value. For BulkAssign, Value is a user ID, "me" or "none". Force closes
+// issues even while their blockers are open.
+type BulkOp struct {
+	Action BulkAction
+	Value  string
+	Force  bool
+}
+
+type BulkFailure struct {
+	IssueID int
+	Err     error
+}
+
+// BulkResult reports which issues a bulk operation changed and why the rest
+// were not.
+type BulkResult struct {
+	Succeeded []int
+	Failures  []BulkFailure
+}
+
+// errNotChanged is reported for issues a batched request silently skipped,
+// usually because they no longer exist or row level security hid them.
+var errNotChanged = errors.New("not found or not permitted")
+
+// ParseBulkOp parses the bulk command typed in the list view:
+//
+//	status <value> [--force]
+//	+label <name>, -label <name>
+//	assign <user id|me|none>
+//	delete
+func ParseBulkOp(input string) (BulkOp, error) {
+	verb, value, _ := strings.Cut(strings.TrimSpace(input), " ")
+	value = strings.TrimSpace(value)
+	var op BulkOp
+	switch verb {
+	case "status":
+		op.Action = BulkSetStatus
+		if v, ok := strings.CutSuffix(value, "--force"); ok {
+			value = strings.TrimSpace(v)
+			op.Force = true
+		}
+	case "+label":
+		op.Action = BulkAddLabel
+	case "-label":
+		op.Action = BulkRemoveLabel
+	case "assign":
+		op.Action = BulkAssign
+	case "delete":
+		if value != "" {
+			return op, fmt.Errorf("delete takes no value")
+		}
+		return BulkOp{Action: BulkDelete}, nil
+	default:
+		return op, fmt.Errorf("unknown bulk action %q", verb)
+	}
+	if value == "" {
+		return op, fmt.Errorf("%s needs a value", verb)
+	}
+	op.Value = value
+	return op, nil
+}
+
+// BulkUpdateIssues applies op to every issue. Status changes are
+// transitions, so blocked issues are reported as failures unless op.Force
+// is set. Label changes depend on each issue's current labels and are sent
+// per issue. On Supabase, assignment and deletion go out as a single request
+// filtered by id; if it fails as a whole it is retried issue by issue so
+// failures can be reported individually.
+func BulkUpdateIssues(ctx context.Context, store IssueStore, issues []Issue, op BulkOp, userID string) *BulkResult {
+	result := &BulkResult{}
+	if len(issues) == 0 {
+		return result
+	}
+
+	assignee := op.Value
+	switch assignee {
+	case "me":
+		assignee = userID
+	case "none":
+		assignee = ""
+	}
+
+	if s, ok := store.(*SupabaseStore); ok && (op.Action == BulkAssign || op.Action == BulkDelete) {
+		bulkSupabase(s.Client, issues, op.Action, assignee, result)
+		return result
+	}
+
+	for _, is := range issues {
+		var err error
+		switch op.Action {
+		case BulkSetStatus:
+			req := TransitionRequest{IssueID: is.ID, Status: op.Value, Force: op.Force}
+			_, err = store.TransitionIssue(ctx, req, userID)
+		case BulkAddLabel, BulkRemoveLabel:
+			labels := withLabel(is.Labels, op.Value, op.Action == BulkAddLabel)
+			_, err = store.SetLabels(ctx, is.ID, labels)
+		case BulkAssign:
+			_, err = store.SetAssignee(ctx, is.ID, assignee)
+		case BulkDelete:
+			err = store.DeleteIssue(ctx, is.ID)
+		}
+		if err != nil {
+			result.Failures = append(result.Failures, BulkFailure{is.ID, err})
+		} else {
+			result.Succeeded = append(result.Succeeded, is.ID)
+		}
+	}
+	return result
+}
+
+// bulkSupabase assigns or deletes issues with one request.
+func bulkSupabase(client *supabase.Client, issues []Issue, action BulkAction, assignee string, result *BulkResult) {
+	patch := map[string]interface{}{"assignee_id": nil}
+	if assignee != "" {
+		patch["assignee_id"] = assignee
+	}
+	apply := func(ids []int) error {
+		if action == BulkDelete {
+			return deleteIssues(client, ids, result)
+		}
+		return updateIssues(client, ids, patch, result)
+	}
+
+	ids := make([]int, 0, len(issues))
+	for _, is := range issues {
+		ids = append(ids, is.ID)
+	}
+	if err := apply(ids); err == nil || len(ids) == 1 {
+		if err != nil {
+			result.Failures = append(result.Failures, BulkFailure{ids[0], err})
+		}
+		return
+	}
+	for _, id := range ids {
+		if err := apply([]int{id}); err != nil {
+			result.Failures = append(result.Failures, BulkFailure{id, err})
+		}
+	}
+}
+
+func updateIssues(client *supabase.Client, ids []int, patch map[string]interface{}, result *BulkResult) error {
+	var changed []Issue
+	_, err := client.From("issues").
+		Update(patch, "representation", "").
+		In("id", idStrings(ids)).
+		ExecuteTo(&changed)
+	if err != nil {
+		return fmt.Errorf("failed to update issues: %w", err)
+	}
+	recordChanged(ids, changed, result)
+	return nil
+}
+
+func deleteIssues(client *supabase.Client, ids []int, result *BulkResult) error {
+	var deleted []Issue
+	_, err := client.From("issues").
+		Delete("representation", "").
+		In("id", idStrings(ids)).
+		ExecuteTo(&deleted)
+	if err != nil {
+		return fmt.Errorf("failed to delete issues: %w", err)
+	}
+	recordChanged(ids, deleted, result)
+	return nil
+}
+
+// recordChanged compares the rows a request returned with the ids it
+// targeted.
+func recordChanged(ids []int, changed []Issue, result *BulkResult) {
+	seen := make(map[int]bool, len(changed))
+	for _, is := range changed {
+		seen[is.ID] = true
+	}
+	for _, id := range ids {
+		if seen[id] {
+			result.Succeeded = append(result.Succeeded, id)
+		} else {
+			result.Failures = append(result.Failures, BulkFailure{id, errNotChanged})
+		}
+	}
+}
+
+func withLabel(labels []string, label string, add bool) []string {
+	out := make([]string, 0, len(labels)+1)
+	for _, l := range labels {
+		if l != label {
+			out = append(out, l)
+		}
+	}
+	if add {
+		out = append(out, label)
+	}
+	return out
+}
+
+func idStrings(ids []int) []string {
+	out := make([]string, len(ids))
+	for i, id := range ids {
+		out[i] = strconv.Itoa(id)
+	}
+	return out
+}
@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+
+	"zel/lo/supabase/supabasetest"
+)
+
+func TestBulkUpdateIssues(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(
+		Issue{ID: 1, Title: "Free", Status: "open"},
+		Issue{ID: 2, Title: "Blocked", Status: "open", Labels: []string{"bug"}},
+		Issue{ID: 3, Title: "Blocker", Status: "open"},
+	)
+	if _, err := store.LinkIssues(ctx, 3, 2, LinkBlocks); err != nil {
+		t.Fatal(err)
+	}
+	all := func() []Issue {
+		issues, err := store.ListIssues(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return issues
+	}
+
+	res := BulkUpdateIssues(ctx, store, all(), BulkOp{Action: BulkSetStatus, Value: "closed"}, LocalUserID)
+	if !slices.Equal(res.Succeeded, []int{1, 3}) || len(res.Failures) != 1 {
+		t.Fatalf("close = %+v", res)
+	}
+	var be *BlockedError
+	if f := res.Failures[0]; f.IssueID != 2 || !errors.As(f.Err, &be) || !slices.Equal(be.Blockers, []int{3}) {
+		t.Errorf("blocked failure = %+v", f)
+	}
+
+	res = BulkUpdateIssues(ctx, store, all(), BulkOp{Action: BulkAddLabel, Value: "bug"}, LocalUserID)
+	if len(res.Succeeded) != 3 || len(res.Failures) != 0 {
+		t.Errorf("+label = %+v", res)
+	}
+	if is, _ := store.GetIssue(ctx, 2); !slices.Equal(is.Labels, []string{"bug"}) {
+		t.Errorf("labels after adding one already there = %v", is.Labels)
+	}
+
+	BulkUpdateIssues(ctx, store, all(), BulkOp{Action: BulkAssign, Value: "me"}, LocalUserID)
+	if is, _ := store.GetIssue(ctx, 1); is.AssigneeID != LocalUserID {
+		t.Errorf("assignee after assign me = %q", is.AssigneeID)
+	}
+
+	res = BulkUpdateIssues(ctx, store, all()[:2], BulkOp{Action: BulkDelete}, LocalUserID)
+	if len(res.Succeeded) != 2 || len(all()) != 1 {
+		t.Errorf("delete = %+v, left %v", res, all())
+	}
+}
+
+func TestBulkStatusGoesThroughTransitions(t *testing.T) {
+	srv := supabasetest.NewServer()
+	defer srv.Close()
+
+	var requests []TransitionRequest
+	srv.HandleFunction(functionTransitionIssue, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req TransitionRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+		w.Header().Set("Content-Type", "application/json")
+		if req.IssueID == 2 {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]interface{}{"error": "blocked", "code": "blocked", "blockers": []int{7}})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"issue": map[string]interface{}{"id": req.IssueID, "status": req.Status}})
+	}))
+
+	store := NewSupabaseStore(srv.Client())
+	issues := []Issue{{ID: 1}, {ID: 2}}
+	res := BulkUpdateIssues(context.Background(), store, issues, BulkOp{Action: BulkSetStatus, Value: "done"}, "")
+	if len(requests) != 2 || requests[0].Status != "done" || requests[0].Force {
+		t.Errorf("transition requests = %+v", requests)
+	}
+	if !slices.Equal(res.Succeeded, []int{1}) || len(res.Failures) != 1 {
+		t.Fatalf("result = %+v", res)
+	}
+	var be *BlockedError
+	if !errors.As(res.Failures[0].Err, &be) || !slices.Equal(be.Blockers, []int{7}) {
+		t.Errorf("failure = %v, want blocked by #7", res.Failures[0].Err)
+	}
+}
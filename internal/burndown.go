@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+)
+
+// BurndownPoint is the remaining work at the end of one sprint day next to
+// the ideal straight line from the sprint's starting scope to zero.
+type BurndownPoint struct {
+	Day       time.Time
+	Remaining float64
+	Ideal     float64
+}
+
+// Burndown replays the status history of a sprint's issues and counts how
+// many were still open at the end of each sprint day. Days after now are
+// left out of the actual line by setting Remaining to -1.
+func Burndown(sprint Sprint, issues []Issue, events []IssueEvent, now time.Time) ([]BurndownPoint, error) {
+	start, err := time.Parse(dateLayout, sprint.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sprint start %q", sprint.StartDate)
+	}
+	end, err := time.Parse(dateLayout, sprint.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sprint end %q", sprint.EndDate)
+	}
+
+	byIssue := make(map[int][]IssueEvent)
+	for _, e := range events {
+		byIssue[e.IssueID] = append(byIssue[e.IssueID], e)
+	}
+
+	days := int(end.Sub(start).Hours()/24) + 1
+	total := float64(len(issues))
+	points := make([]BurndownPoint, 0, days)
+	for i := 0; i < days; i++ {
+		day := start.AddDate(0, 0, i)
+		cutoff := day.AddDate(0, 0, 1)
+		ideal := total
+		if days > 1 {
+			ideal = total * float64(days-1-i) / float64(days-1)
+		}
+		p := BurndownPoint{Day: day, Ideal: ideal, Remaining: -1}
+		if !day.After(now) {
+			p.Remaining = 0
+			for _, is := range issues {
+				if !isClosedStatus(statusAt(is, byIssue[is.ID], cutoff)) {
+					p.Remaining++
+				}
+			}
+		}
+		points = append(points, p)
+	}
+	return points, nil
+}
+
+// statusAt works out an issue's status just before t from its ordered
+// status events.
+func statusAt(is Issue, events []IssueEvent, t time.Time) string {
+	status := ""
+	for _, e := range events {
+		at, err := time.Parse(time.RFC3339Nano, e.CreatedAt)
+		if err != nil {
+			continue
+		}
+		if !at.Before(t) {
+			// The first change after t tells us what the status was before it.
+			if status == "" {
+				return e.FromValue
+			}
+			return status
+		}
+		status = e.ToValue
+	}
+	if status == "" {
+		return is.Status
+	}
+	return status
+}
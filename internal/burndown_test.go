@@ -0,0 +1,118 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBurndown(t *testing.T) {
+	week := Sprint{StartDate: "2026-03-02", EndDate: "2026-03-04"}
+	closed := func(id int, at string) IssueEvent {
+		return IssueEvent{IssueID: id, Kind: EventStatus, FromValue: "open", ToValue: "closed", CreatedAt: at}
+	}
+	after := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		sprint    Sprint
+		issues    []Issue
+		events    []IssueEvent
+		now       time.Time
+		remaining []float64
+		ideal     []float64
+	}{
+		{
+			name:      "single day",
+			sprint:    Sprint{StartDate: "2026-03-02", EndDate: "2026-03-02"},
+			issues:    []Issue{{ID: 1, Status: "closed"}, {ID: 2, Status: "open"}},
+			events:    []IssueEvent{closed(1, "2026-03-02T15:00:00Z")},
+			now:       after,
+			remaining: []float64{1},
+			ideal:     []float64{2},
+		},
+		{
+			name:      "closed during the sprint",
+			sprint:    week,
+			issues:    []Issue{{ID: 1, Status: "closed"}, {ID: 2, Status: "done"}},
+			events:    []IssueEvent{closed(1, "2026-03-03T09:00:00Z"), {IssueID: 2, FromValue: "open", ToValue: "done", CreatedAt: "2026-03-04T23:59:59.5Z"}},
+			now:       after,
+			remaining: []float64{2, 1, 0},
+			ideal:     []float64{2, 1, 0},
+		},
+		{
+			name:      "future days",
+			sprint:    week,
+			issues:    []Issue{{ID: 1, Status: "closed"}, {ID: 2, Status: "open"}},
+			events:    []IssueEvent{closed(1, "2026-03-02T09:00:00Z")},
+			now:       time.Date(2026, 3, 3, 12, 0, 0, 0, time.UTC),
+			remaining: []float64{1, 1, -1},
+			ideal:     []float64{2, 1, 0},
+		},
+		{
+			name:   "events before the sprint",
+			sprint: week,
+			issues: []Issue{{ID: 1, Status: "closed"}, {ID: 2, Status: "closed"}},
+			events: []IssueEvent{
+				closed(1, "2026-02-20T09:00:00Z"),
+				closed(2, "2026-02-20T09:00:00Z"),
+				{IssueID: 2, FromValue: "closed", ToValue: "open", CreatedAt: "2026-02-25T09:00:00Z"},
+				closed(2, "2026-03-03T09:00:00Z"),
+			},
+			now:       after,
+			remaining: []float64{1, 0, 0},
+			ideal:     []float64{2, 1, 0},
+		},
+		{
+			name:   "unparsable timestamps",
+			sprint: week,
+			issues: []Issue{{ID: 1, Status: "closed"}, {ID: 2, Status: "open"}},
+			events: []IssueEvent{
+				closed(1, "yesterday"),
+				closed(1, "2026-03-03T09:00:00Z"),
+				closed(2, ""),
+			},
+			now:       after,
+			remaining: []float64{2, 1, 1},
+			ideal:     []float64{2, 1, 0},
+		},
+		{
+			name:      "no issues",
+			sprint:    week,
+			now:       after,
+			remaining: []float64{0, 0, 0},
+			ideal:     []float64{0, 0, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points, err := Burndown(tt.sprint, tt.issues, tt.events, tt.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var remaining, ideal []float64
+			for _, p := range points {
+				remaining = append(remaining, p.Remaining)
+				ideal = append(ideal, p.Ideal)
+			}
+			if fmt.Sprint(remaining) != fmt.Sprint(tt.remaining) || fmt.Sprint(ideal) != fmt.Sprint(tt.ideal) {
+				t.Errorf("remaining = %v, ideal = %v; want %v, %v", remaining, ideal, tt.remaining, tt.ideal)
+			}
+			if start := points[0].Day.Format(dateLayout); start != tt.sprint.StartDate {
+				t.Errorf("first day = %s, want %s", start, tt.sprint.StartDate)
+			}
+		})
+	}
+}
+
+func TestBurndownInvalidDates(t *testing.T) {
+	for _, sprint := range []Sprint{
+		{StartDate: "March 2", EndDate: "2026-03-04"},
+		{StartDate: "2026-03-02", EndDate: ""},
+	} {
+		if _, err := Burndown(sprint, nil, nil, time.Now()); err == nil || !strings.Contains(err.Error(), "invalid sprint") {
+			t.Errorf("Burndown(%+v) error = %v", sprint, err)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/supabase-community/postgrest-go"
+
+	"zel/lo/supabase"
+)
+
+// ListComments returns an issue's comments, oldest first.
+func ListComments(client *supabase.Client, issueID int) ([]Comment, error) {
+	var comments []Comment
+
+	_, err := client.From("comments").
+		Select("*", "", false).
+		Eq("issue_id", strconv.Itoa(issueID)).
+		Order("created_at", &postgrest.OrderOpts{Ascending: true}).
+		ExecuteTo(&comments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch comments: %w", err)
+	}
+
+	return comments, nil
+}
+
+func CreateComment(client *supabase.Client, issueID int, userID, body string) (*Comment, error) {
+	var comments []Comment
+
+	commentData := map[string]interface{}{
+		"issue_id": issueID,
+		"user_id":  userID,
+		"body":     body,
+	}
+
+	_, err := client.From("comments").
+		Insert([]map[string]interface{}{commentData}, false, "", "representation", "").
+		ExecuteTo(&comments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create comment: %w", err)
+	}
+
+	if len(comments) == 0 {
+		return nil, fmt.Errorf("insert succeeded but no comment returned")
+	}
+
+	return &comments[0], nil
+}
@@ -0,0 +1,52 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"zel/lo/supabase"
+)
+
+// LinkCommit records a commit on an issue as a comment and, when close is
+// set, closes the issue. Commits already recorded on the issue are skipped so
+// the same history can be processed repeatedly. It reports whether anything
+// changed.
+func LinkCommit(client *supabase.Client, issueID int, userID, hash, subject string, close bool) (bool, error) {
+	comments, err := ListComments(client, issueID)
+	if err != nil {
+		return false, err
+	}
+	for _, c := range comments {
+		if strings.Contains(c.Body, hash) {
+			return false, nil
+		}
+	}
+
+	verb := "Referenced"
+	if close {
+		verb = "Closed"
+	}
+	body := fmt.Sprintf("%s in commit %s: %s", verb, hash, subject)
+
+	if close {
+		issue, err := GetIssue(client, issueID)
+		if err != nil {
+			return false, err
+		}
+		if !issue.Closed() {
+			// Close and comment in one transaction so a failed close
+			// leaves no comment claiming otherwise.
+			req := TransitionRequest{IssueID: issueID, Status: "closed", Comment: body}
+			if _, err := TransitionIssue(context.Background(), client, req); err != nil {
+				return false, err
+			}
+			return true, nil
+		}
+	}
+
+	if _, err := CreateComment(client, issueID, userID, body); err != nil {
+		return false, err
+	}
+	return true, nil
+}
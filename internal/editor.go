@@ -0,0 +1,72 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// EditorCommand builds the command that opens path in the user's editor,
+// taken from $VISUAL, then $EDITOR, falling back to vi. The variables may
+// carry arguments, e.g. "code --wait".
+func EditorCommand(path string) (*exec.Cmd, error) {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("no editor configured, set $VISUAL or $EDITOR")
+	}
+	cmd := exec.Command(args[0], append(args[1:], path)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd, nil
+}
+
+// WriteEditorFile writes text to a new temporary Markdown file for editing
+// and returns its path. The caller removes it with ReadEditorFile.
+func WriteEditorFile(text string) (string, error) {
+	f, err := os.CreateTemp("", "zello-*.md")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(text); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write temp file: %w", err)
+	}
+	return f.Name(), nil
+}
+
+// ReadEditorFile reads back an edited temp file and removes it.
+func ReadEditorFile(path string) (string, error) {
+	defer os.Remove(path)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read edited file: %w", err)
+	}
+	return strings.TrimRight(string(b), "\n"), nil
+}
+
+// EditText opens text in the user's editor, waits for it to exit and
+// returns the edited result.
+func EditText(text string) (string, error) {
+	path, err := WriteEditorFile(text)
+	if err != nil {
+		return "", err
+	}
+	cmd, err := EditorCommand(path)
+	if err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	if err := cmd.Run(); err != nil {
+		os.Remove(path)
+		return "", fmt.Errorf("editor exited with error: %w", err)
+	}
+	return ReadEditorFile(path)
+}
@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/supabase-community/postgrest-go"
+
+	"zel/lo/supabase"
+)
+
+// ExportVersion is bumped whenever the export format changes incompatibly.
+const ExportVersion = 1
+
+// ExportFile is the document written by zello export. It carries everything
+// needed to recreate the issues elsewhere, including their comments and
+// labels, and reads back with ParseZelloExport.
+type ExportFile struct {
+	Version    int             `json:"version"`
+	ExportedAt string          `json:"exported_at"`
+	Board      *Board          `json:"board,omitempty"`
+	Issues     []ExportedIssue `json:"issues"`
+}
+
+type ExportedIssue struct {
+	Issue
+	Comments []Comment `json:"comments"`
+}
+
+// ExportIssues collects the issues on a board, or every issue visible to the
+// user when board is nil, together with their comments.
+func ExportIssues(client *supabase.Client, board *Board) (*ExportFile, error) {
+	var issues []Issue
+
+	q := client.From("issues").Select("*", "", false)
+	if board != nil {
+		q = q.Eq("board_id", strconv.Itoa(board.ID))
+	}
+	_, err := q.Order("id", &postgrest.OrderOpts{Ascending: true}).ExecuteTo(&issues)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch issues: %w", err)
+	}
+
+	ids := make([]int, len(issues))
+	for i, is := range issues {
+		ids[i] = is.ID
+	}
+	byIssue := make(map[int][]Comment)
+	for _, chunk := range chunkInts(ids, importBatchSize) {
+		var comments []Comment
+		_, err := client.From("comments").
+			Select("*", "", false).
+			In("issue_id", idStrings(chunk)).
+			Order("created_at", &postgrest.OrderOpts{Ascending: true}).
+			ExecuteTo(&comments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch comments: %w", err)
+		}
+		for _, c := range comments {
+			byIssue[c.IssueID] = append(byIssue[c.IssueID], c)
+		}
+	}
+
+	file := &ExportFile{
+		Version:    ExportVersion,
+		ExportedAt: time.Now().UTC().Format(time.RFC3339),
+		Board:      board,
+		Issues:     make([]ExportedIssue, len(issues)),
+	}
+	for i, is := range issues {
+		comments := byIssue[is.ID]
+		if comments == nil {
+			comments = []Comment{}
+		}
+		file.Issues[i] = ExportedIssue{Issue: is, Comments: comments}
+	}
+	return file, nil
+}
+
+func chunkInts(ids []int, size int) [][]int {
+	var chunks [][]int
+	for len(ids) > size {
+		chunks = append(chunks, ids[:size])
+		ids = ids[size:]
+	}
+	if len(ids) > 0 {
+		chunks = append(chunks, ids)
+	}
+	return chunks
+}
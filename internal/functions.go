@@ -0,0 +1,103 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"zel/lo/supabase"
+)
+
+// Edge functions shipped in supabase/functions. They run operations that
+// must happen atomically on the server.
+const (
+	functionCreateIssue     = "create-issue"
+	functionTransitionIssue = "transition-issue"
+)
+
+// Key returns the issue's board-scoped key, e.g. ZEL-42, falling back to
+// its global number for issues created before boards numbered them.
+func (is Issue) Key(board *Board) string {
+	if board != nil && is.Number > 0 && is.BoardID != nil && *is.BoardID == board.ID {
+		return fmt.Sprintf("%s-%d", board.Key, is.Number)
+	}
+	return fmt.Sprintf("#%d", is.ID)
+}
+
+// GetBoardIssue fetches the issue with number on a board, the N of a key
+// such as ZEL-N.
+func GetBoardIssue(client *supabase.Client, board *Board, number int) (*Issue, error) {
+	var issues []Issue
+	_, err := client.From("issues").
+		Select("*", "", false).
+		Eq("board_id", strconv.Itoa(board.ID)).
+		Eq("number", strconv.Itoa(number)).
+		ExecuteTo(&issues)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch issue: %w", err)
+	}
+	if len(issues) == 0 {
+		return nil, fmt.Errorf("issue %s-%d not found", board.Key, number)
+	}
+	return &issues[0], nil
+}
+
+// CreateBoardIssue creates an issue through the create-issue edge function,
+// which allocates the next number on the board in the same transaction as
+// the insert so concurrent creators never share a number.
+func CreateBoardIssue(ctx context.Context, client *supabase.Client, req CreateIssueRequest) (*Issue, error) {
+	if req.BoardID == nil {
+		return nil, fmt.Errorf("a board is required to number issues")
+	}
+
+	var resp struct {
+		Issue Issue `json:"issue"`
+	}
+	if err := client.Invoke(ctx, functionCreateIssue, req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to create issue: %w", err)
+	}
+	if resp.Issue.ID == 0 {
+		return nil, fmt.Errorf("create succeeded but no issue returned")
+	}
+	return &resp.Issue, nil
+}
+
+type TransitionRequest struct {
+	IssueID int    `json:"issue_id"`
+	Status  string `json:"status"`
+	Force   bool   `json:"force,omitempty"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// TransitionResult is the issue after a transition. CompletedParent is set
+// when the transition closed the last open child of the issue's parent.
+type TransitionResult struct {
+	Issue           Issue    `json:"issue"`
+	Comment         *Comment `json:"comment,omitempty"`
+	CompletedParent *int     `json:"completed_parent,omitempty"`
+}
+
+// TransitionIssue changes an issue's status through the transition-issue
+// edge function. The status change, the blocker check and the optional
+// comment are applied in one transaction. Open blockers are reported as a
+// *BlockedError unless Force is set.
+func TransitionIssue(ctx context.Context, client *supabase.Client, req TransitionRequest) (*TransitionResult, error) {
+	var resp TransitionResult
+	err := client.Invoke(ctx, functionTransitionIssue, req, &resp)
+
+	var fnErr *supabase.FunctionError
+	if errors.As(err, &fnErr) && fnErr.Code == "blocked" {
+		var details struct {
+			Blockers []int `json:"blockers"`
+		}
+		if json.Unmarshal(fnErr.Details, &details) == nil {
+			return nil, &BlockedError{IssueID: req.IssueID, Blockers: details.Blockers}
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update status: %w", err)
+	}
+	return &resp, nil
+}
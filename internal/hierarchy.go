@@ -0,0 +1,135 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+
+	"zel/lo/supabase"
+)
+
+// maxIssueDepth bounds the ancestor walk in case the stored hierarchy is
+// already corrupt.
+const maxIssueDepth = 100
+
+// Closed reports whether the issue counts as complete for its parent's
+// progress.
+func (is Issue) Closed() bool {
+	return isClosedStatus(is.Status)
+}
+
+// ListChildIssues returns the direct children of an issue.
+func ListChildIssues(client *supabase.Client, parentID int) ([]Issue, error) {
+	var issues []Issue
+
+	_, err := client.From("issues").
+		Select("*", "", false).
+		Eq("parent_id", strconv.Itoa(parentID)).
+		ExecuteTo(&issues)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch child issues: %w", err)
+	}
+
+	return issues, nil
+}
+
+// SetIssueParent moves an issue under parentID, or makes it a top-level
+// issue when parentID is nil. It refuses moves that would make an issue its
+// own ancestor.
+func SetIssueParent(client *supabase.Client, issueID int, parentID *int) (*Issue, error) {
+	if parentID != nil {
+		get := func(id int) (*Issue, error) { return GetIssue(client, id) }
+		if err := checkParentCycle(issueID, *parentID, get); err != nil {
+			return nil, err
+		}
+	}
+
+	var issues []Issue
+	_, err := client.From("issues").
+		Update(map[string]interface{}{"parent_id": parentID}, "representation", "").
+		Eq("id", strconv.Itoa(issueID)).
+		ExecuteTo(&issues)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set parent: %w", err)
+	}
+	if len(issues) == 0 {
+		return nil, fmt.Errorf("issue #%d not found", issueID)
+	}
+	return &issues[0], nil
+}
+
+// checkParentCycle walks up from parentID, reading issues with get, and
+// fails if it reaches issueID.
+func checkParentCycle(issueID, parentID int, get func(id int) (*Issue, error)) error {
+	current := parentID
+	for depth := 0; depth < maxIssueDepth; depth++ {
+		if current == issueID {
+			return fmt.Errorf("issue #%d cannot be placed under #%d: that would create a cycle", issueID, parentID)
+		}
+		parent, err := get(current)
+		if err != nil {
+			return err
+		}
+		if parent.ParentID == nil {
+			return nil
+		}
+		current = *parent.ParentID
+	}
+	return fmt.Errorf("issue hierarchy above #%d is deeper than %d levels", parentID, maxIssueDepth)
+}
+
+// ChildProgress counts how many of the children are closed.
+func ChildProgress(children []Issue) (done, total int) {
+	for _, c := range children {
+		if c.Closed() {
+			done++
+		}
+	}
+	return done, len(children)
+}
+
+type IssueTreeRow struct {
+	Issue Issue
+	Depth int
+}
+
+// IssueTree orders issues depth first so children follow their parent.
+// Siblings keep their relative order, and issues whose parent is not in the
+// slice are treated as roots.
+func IssueTree(issues []Issue) []IssueTreeRow {
+	present := make(map[int]bool, len(issues))
+	for _, is := range issues {
+		present[is.ID] = true
+	}
+	children := make(map[int][]Issue)
+	var roots []Issue
+	for _, is := range issues {
+		if is.ParentID != nil && present[*is.ParentID] && *is.ParentID != is.ID {
+			children[*is.ParentID] = append(children[*is.ParentID], is)
+		} else {
+			roots = append(roots, is)
+		}
+	}
+
+	rows := make([]IssueTreeRow, 0, len(issues))
+	visited := make(map[int]bool, len(issues))
+	var walk func(is Issue, depth int)
+	walk = func(is Issue, depth int) {
+		if visited[is.ID] {
+			return
+		}
+		visited[is.ID] = true
+		rows = append(rows, IssueTreeRow{is, depth})
+		for _, c := range children[is.ID] {
+			walk(c, depth+1)
+		}
+	}
+	for _, is := range roots {
+		walk(is, 0)
+	}
+	// Issues caught in a stored cycle are unreachable from any root; list
+	// them at the top level rather than dropping them.
+	for _, is := range issues {
+		walk(is, 0)
+	}
+	return rows
+}
@@ -0,0 +1,567 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"zel/lo/supabase"
+
+	"github.com/google/uuid"
+)
+
+// importBatchSize bounds how many ids go into a single in.() filter or
+// insert so request URLs and bodies stay a reasonable size.
+const importBatchSize = 100
+
+// ImportIssue is an issue read from a zello export or another tracker,
+// ready to be written by ImportIssues. ExternalID identifies it in the
+// source system and is what makes re-running an import safe. Issues from a
+// zello export that were never imported themselves also carry their ID, so
+// importing the export back matches the issue it came from.
+type ImportIssue struct {
+	ID               int
+	ExternalID       string
+	ParentExternalID string
+	Title            string
+	Description      string
+	Status           string
+	Labels           []string
+	AuthorID         string
+	AssigneeID       string
+	SprintID         *int
+	Estimate         *float64
+	CreatedAt        string
+	Comments         []ImportComment
+}
+
+// ImportComment is a comment on an ImportIssue. Author is the name the
+// source system shows and AuthorID the zello user who wrote it. Comments are
+// always stored as the importing user, so anyone else's are prefixed with
+// their name.
+type ImportComment struct {
+	ID         int
+	ExternalID string
+	Author     string
+	AuthorID   string
+	Body       string
+	CreatedAt  string
+}
+
+type ImportOptions struct {
+	BoardID *int
+	UserID  string
+	DryRun  bool
+}
+
+// ImportReport lists the external IDs of the issues an import created,
+// changed or left alone, and how many comments it added. In a dry run it
+// describes what would have happened.
+type ImportReport struct {
+	Created   []string
+	Updated   []string
+	Unchanged []string
+	Comments  int
+	DryRun    bool
+}
+
+// ImportIssues writes issues, matching them to earlier imports by external
+// ID, or to the issue a zello export was taken from by ID: unknown issues
+// are created, known ones are updated in place, and comments that were
+// imported before are skipped. Parent links are resolved against both the
+// imported issues and issues already in zello. Authors are kept, and so are
+// sprints that exist on the board.
+func ImportIssues(client *supabase.Client, items []ImportIssue, opts ImportOptions) (*ImportReport, error) {
+	report := &ImportReport{DryRun: opts.DryRun}
+
+	seen := make(map[string]bool, len(items))
+	lookup := make([]string, 0, len(items))
+	for _, it := range items {
+		if it.ExternalID == "" {
+			return nil, fmt.Errorf("issue %q has no external id", it.Title)
+		}
+		if it.Title == "" {
+			return nil, fmt.Errorf("issue %s has no title", it.ExternalID)
+		}
+		if seen[it.ExternalID] {
+			return nil, fmt.Errorf("external id %s appears more than once", it.ExternalID)
+		}
+		seen[it.ExternalID] = true
+		lookup = append(lookup, it.ExternalID)
+	}
+	for _, it := range items {
+		if it.ParentExternalID != "" && !seen[it.ParentExternalID] {
+			seen[it.ParentExternalID] = true
+			lookup = append(lookup, it.ParentExternalID)
+		}
+	}
+
+	existing, err := issuesByExternalID(client, lookup)
+	if err != nil {
+		return nil, err
+	}
+	if err := matchExportedIssues(client, items, existing); err != nil {
+		return nil, err
+	}
+	items, err = dropUnknownSprints(client, items, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var inserts []map[string]interface{}
+	for _, it := range items {
+		is, ok := existing[it.ExternalID]
+		switch {
+		case !ok:
+			report.Created = append(report.Created, it.ExternalID)
+			inserts = append(inserts, importRow(it, opts))
+		case importChanged(is, it, opts):
+			report.Updated = append(report.Updated, it.ExternalID)
+		default:
+			report.Unchanged = append(report.Unchanged, it.ExternalID)
+		}
+	}
+
+	if opts.DryRun {
+		n, err := countNewComments(client, items, existing)
+		if err != nil {
+			return nil, err
+		}
+		report.Comments = n
+		return report, nil
+	}
+
+	numbers, err := reserveImportNumbers(client, items, existing, report, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range inserts {
+		if n, ok := numbers[row["external_id"].(string)]; ok {
+			row["number"] = n
+		}
+	}
+
+	for start := 0; start < len(inserts); start += importBatchSize {
+		batch := inserts[start:min(start+importBatchSize, len(inserts))]
+		var created []Issue
+		_, err := client.From("issues").
+			Insert(batch, false, "", "representation", "").
+			ExecuteTo(&created)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import issues: %w", err)
+		}
+		if len(created) != len(batch) {
+			return nil, fmt.Errorf("insert succeeded but only %d of %d issues returned", len(created), len(batch))
+		}
+		for _, is := range created {
+			existing[is.ExternalID] = is
+		}
+	}
+
+	for _, it := range items {
+		if !slices.Contains(report.Updated, it.ExternalID) {
+			continue
+		}
+		row := importRow(it, opts)
+		delete(row, "user_id")
+		delete(row, "created_at")
+		if n, ok := numbers[it.ExternalID]; ok {
+			row["number"] = n
+		}
+		if existing[it.ExternalID].ExternalID == "" {
+			// Matched by ID: the issue is the original, not an import.
+			delete(row, "external_id")
+		}
+		var updated []Issue
+		_, err := client.From("issues").
+			Update(row, "representation", "").
+			Eq("id", strconv.Itoa(existing[it.ExternalID].ID)).
+			ExecuteTo(&updated)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update issue %s: %w", it.ExternalID, err)
+		}
+		if len(updated) > 0 {
+			existing[it.ExternalID] = updated[0]
+		}
+	}
+
+	for _, it := range items {
+		is := existing[it.ExternalID]
+		var parentID *int
+		if it.ParentExternalID != "" {
+			parent, ok := existing[it.ParentExternalID]
+			if !ok {
+				return nil, fmt.Errorf("issue %s: parent %s not found", it.ExternalID, it.ParentExternalID)
+			}
+			if parent.ID != is.ID {
+				parentID = &parent.ID
+			}
+		}
+		if sameParent(is.ParentID, parentID) {
+			continue
+		}
+		_, _, err := client.From("issues").
+			Update(map[string]interface{}{"parent_id": parentID}, "minimal", "").
+			Eq("id", strconv.Itoa(is.ID)).
+			Execute()
+		if err != nil {
+			return nil, fmt.Errorf("failed to set parent of issue %s: %w", it.ExternalID, err)
+		}
+	}
+
+	n, err := importComments(client, items, existing, opts.UserID)
+	if err != nil {
+		return nil, err
+	}
+	report.Comments = n
+	return report, nil
+}
+
+// reserveImportNumbers numbers the issues an import puts on opts.BoardID,
+// created or moved there, by external ID. The numbers are taken from the
+// board in one block, so issues created on it meanwhile keep theirs.
+func reserveImportNumbers(client *supabase.Client, items []ImportIssue, existing map[string]Issue, report *ImportReport, opts ImportOptions) (map[string]int, error) {
+	if opts.BoardID == nil {
+		return nil, nil
+	}
+	var arriving []string
+	for _, it := range items {
+		is, ok := existing[it.ExternalID]
+		if !ok || slices.Contains(report.Updated, it.ExternalID) && !sameParent(is.BoardID, opts.BoardID) {
+			arriving = append(arriving, it.ExternalID)
+		}
+	}
+	if len(arriving) == 0 {
+		return nil, nil
+	}
+
+	params := map[string]interface{}{"p_board_id": *opts.BoardID, "p_count": len(arriving)}
+	first, _, err := supabase.Rpc[int](context.Background(), client, "reserve_board_numbers", params, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to number imported issues: %w", err)
+	}
+	numbers := make(map[string]int, len(arriving))
+	for i, id := range arriving {
+		numbers[id] = first + i
+	}
+	return numbers, nil
+}
+
+func importRow(it ImportIssue, opts ImportOptions) map[string]interface{} {
+	status := it.Status
+	if status == "" {
+		status = "open"
+	}
+	labels := it.Labels
+	if labels == nil {
+		labels = []string{}
+	}
+	row := map[string]interface{}{
+		"external_id": it.ExternalID,
+		"title":       it.Title,
+		"description": it.Description,
+		"status":      status,
+		"labels":      labels,
+		"user_id":     opts.UserID,
+	}
+	if it.AuthorID != "" {
+		row["user_id"] = it.AuthorID
+	}
+	if it.AssigneeID != "" {
+		row["assignee_id"] = it.AssigneeID
+	}
+	if it.SprintID != nil {
+		row["sprint_id"] = *it.SprintID
+	}
+	if it.Estimate != nil {
+		row["estimate"] = *it.Estimate
+	}
+	if it.CreatedAt != "" {
+		row["created_at"] = it.CreatedAt
+	}
+	if opts.BoardID != nil {
+		row["board_id"] = *opts.BoardID
+	}
+	return row
+}
+
+// importChanged reports whether writing it over the stored issue would
+// change anything the import controls.
+func importChanged(is Issue, it ImportIssue, opts ImportOptions) bool {
+	status := it.Status
+	if status == "" {
+		status = "open"
+	}
+	if is.Title != it.Title || is.Description != it.Description || is.Status != status {
+		return true
+	}
+	if !slices.Equal(is.Labels, it.Labels) {
+		return true
+	}
+	if it.AssigneeID != "" && is.AssigneeID != it.AssigneeID {
+		return true
+	}
+	if it.SprintID != nil && !sameParent(is.SprintID, it.SprintID) {
+		return true
+	}
+	if it.Estimate != nil && (is.Estimate == nil || *is.Estimate != *it.Estimate) {
+		return true
+	}
+	return opts.BoardID != nil && !sameParent(is.BoardID, opts.BoardID)
+}
+
+func sameParent(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func issuesByExternalID(client *supabase.Client, ids []string) (map[string]Issue, error) {
+	found := make(map[string]Issue, len(ids))
+	for start := 0; start < len(ids); start += importBatchSize {
+		var issues []Issue
+		_, err := client.From("issues").
+			Select("*", "", false).
+			In("external_id", ids[start:min(start+importBatchSize, len(ids))]).
+			ExecuteTo(&issues)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up imported issues: %w", err)
+		}
+		for _, is := range issues {
+			found[is.ExternalID] = is
+		}
+	}
+	return found, nil
+}
+
+// matchExportedIssues adds the issues a zello export was taken from to
+// existing, keyed by the external ID the export gave them. An issue only
+// matches when its creation time agrees too, so an export from another
+// database does not land on whatever issue has the same ID here.
+func matchExportedIssues(client *supabase.Client, items []ImportIssue, existing map[string]Issue) error {
+	var ids []int
+	for _, it := range items {
+		if _, ok := existing[it.ExternalID]; !ok && it.ID > 0 {
+			ids = append(ids, it.ID)
+		}
+	}
+	byID := make(map[int]Issue, len(ids))
+	for _, chunk := range chunkInts(ids, importBatchSize) {
+		var issues []Issue
+		_, err := client.From("issues").
+			Select("*", "", false).
+			In("id", idStrings(chunk)).
+			ExecuteTo(&issues)
+		if err != nil {
+			return fmt.Errorf("failed to look up exported issues: %w", err)
+		}
+		for _, is := range issues {
+			byID[is.ID] = is
+		}
+	}
+	for _, it := range items {
+		is, ok := byID[it.ID]
+		if ok && is.ExternalID == "" && sameInstant(is.CreatedAt, it.CreatedAt) {
+			existing[it.ExternalID] = is
+		}
+	}
+	return nil
+}
+
+// sameInstant reports whether two timestamps name the same moment, whatever
+// precision or zone they were written with.
+func sameInstant(a, b string) bool {
+	ta, errA := time.Parse(time.RFC3339Nano, a)
+	tb, errB := time.Parse(time.RFC3339Nano, b)
+	return errA == nil && errB == nil && ta.Equal(tb)
+}
+
+// dropUnknownSprints clears the sprint of items whose sprint does not exist,
+// or belongs to a board other than the one being imported into.
+func dropUnknownSprints(client *supabase.Client, items []ImportIssue, opts ImportOptions) ([]ImportIssue, error) {
+	var ids []int
+	for _, it := range items {
+		if it.SprintID != nil && !slices.Contains(ids, *it.SprintID) {
+			ids = append(ids, *it.SprintID)
+		}
+	}
+	if len(ids) == 0 {
+		return items, nil
+	}
+	known := make(map[int]Sprint, len(ids))
+	for _, chunk := range chunkInts(ids, importBatchSize) {
+		var sprints []Sprint
+		_, err := client.From("sprints").
+			Select("*", "", false).
+			In("id", idStrings(chunk)).
+			ExecuteTo(&sprints)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up sprints: %w", err)
+		}
+		for _, s := range sprints {
+			known[s.ID] = s
+		}
+	}
+	kept := slices.Clone(items)
+	for i, it := range kept {
+		if it.SprintID == nil {
+			continue
+		}
+		s, ok := known[*it.SprintID]
+		if !ok || opts.BoardID != nil && s.BoardID != *opts.BoardID {
+			kept[i].SprintID = nil
+		}
+	}
+	return kept, nil
+}
+
+// importedCommentIDs returns the external IDs of comments that are already
+// stored: imported before under that ID, or, for comments from a zello
+// export, the original comment on the issue it was matched to.
+func importedCommentIDs(client *supabase.Client, items []ImportIssue, issues map[string]Issue) (map[string]bool, error) {
+	var ids []string
+	var exported []int
+	for _, it := range items {
+		for _, c := range it.Comments {
+			ids = append(ids, c.ExternalID)
+			if _, ok := issues[it.ExternalID]; ok && c.ID > 0 {
+				exported = append(exported, c.ID)
+			}
+		}
+	}
+	found := make(map[string]bool)
+	for start := 0; start < len(ids); start += importBatchSize {
+		var comments []Comment
+		_, err := client.From("comments").
+			Select("external_id", "", false).
+			In("external_id", ids[start:min(start+importBatchSize, len(ids))]).
+			ExecuteTo(&comments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up imported comments: %w", err)
+		}
+		for _, c := range comments {
+			found[c.ExternalID] = true
+		}
+	}
+
+	onIssue := make(map[int]int, len(exported))
+	for _, chunk := range chunkInts(exported, importBatchSize) {
+		var comments []Comment
+		_, err := client.From("comments").
+			Select("id,issue_id", "", false).
+			In("id", idStrings(chunk)).
+			ExecuteTo(&comments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up exported comments: %w", err)
+		}
+		for _, c := range comments {
+			onIssue[c.ID] = c.IssueID
+		}
+	}
+	for _, it := range items {
+		for _, c := range it.Comments {
+			if id, ok := onIssue[c.ID]; ok && c.ID > 0 && id == issues[it.ExternalID].ID {
+				found[c.ExternalID] = true
+			}
+		}
+	}
+	return found, nil
+}
+
+// authorNames looks up the display names of the comment authors other than
+// userID, whose comments are stored in the importer's name.
+func authorNames(client *supabase.Client, items []ImportIssue, userID string) (map[string]string, error) {
+	var ids []string
+	for _, it := range items {
+		for _, c := range it.Comments {
+			if c.Author != "" || c.AuthorID == "" || c.AuthorID == userID || slices.Contains(ids, c.AuthorID) {
+				continue
+			}
+			if _, err := uuid.Parse(c.AuthorID); err == nil {
+				ids = append(ids, c.AuthorID)
+			}
+		}
+	}
+	names := make(map[string]string, len(ids))
+	for start := 0; start < len(ids); start += importBatchSize {
+		var profiles []Profile
+		_, err := client.From("profiles").
+			Select("id,display_name", "", false).
+			In("id", ids[start:min(start+importBatchSize, len(ids))]).
+			ExecuteTo(&profiles)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up comment authors: %w", err)
+		}
+		for _, p := range profiles {
+			if p.DisplayName != "" {
+				names[p.ID] = p.DisplayName
+			}
+		}
+	}
+	return names, nil
+}
+
+func countNewComments(client *supabase.Client, items []ImportIssue, issues map[string]Issue) (int, error) {
+	found, err := importedCommentIDs(client, items, issues)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, it := range items {
+		for _, c := range it.Comments {
+			if !found[c.ExternalID] {
+				n++
+			}
+		}
+	}
+	return n, nil
+}
+
+func importComments(client *supabase.Client, items []ImportIssue, issues map[string]Issue, userID string) (int, error) {
+	found, err := importedCommentIDs(client, items, issues)
+	if err != nil {
+		return 0, err
+	}
+	names, err := authorNames(client, items, userID)
+	if err != nil {
+		return 0, err
+	}
+	var rows []map[string]interface{}
+	for _, it := range items {
+		for _, c := range it.Comments {
+			if found[c.ExternalID] {
+				continue
+			}
+			body := c.Body
+			author := c.Author
+			if author == "" && c.AuthorID != "" && c.AuthorID != userID {
+				author = firstNonEmpty(names[c.AuthorID], c.AuthorID)
+			}
+			if author != "" {
+				body = fmt.Sprintf("%s wrote:\n\n%s", author, c.Body)
+			}
+			row := map[string]interface{}{
+				"issue_id":    issues[it.ExternalID].ID,
+				"user_id":     userID,
+				"body":        body,
+				"external_id": c.ExternalID,
+			}
+			if c.CreatedAt != "" {
+				row["created_at"] = c.CreatedAt
+			}
+			rows = append(rows, row)
+		}
+	}
+	for start := 0; start < len(rows); start += importBatchSize {
+		_, _, err := client.From("comments").
+			Insert(rows[start:min(start+importBatchSize, len(rows))], false, "", "minimal", "").
+			Execute()
+		if err != nil {
+			return 0, fmt.Errorf("failed to import comments: %w", err)
+		}
+	}
+	return len(rows), nil
+}
@@ -0,0 +1,401 @@
+package internal
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseZelloExport reads a file written by zello export. Issues keep the
+// external ID they were imported with; the rest are keyed by their zello ID,
+// which ImportIssues also uses to find the issue they were exported from, so
+// importing an export, or the same export twice, does not duplicate them.
+// Authors and sprints come along.
+func ParseZelloExport(data []byte) ([]ImportIssue, error) {
+	var file ExportFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to read export: %w", err)
+	}
+	if file.Version > ExportVersion {
+		return nil, fmt.Errorf("export version %d is newer than this zello supports (%d)", file.Version, ExportVersion)
+	}
+
+	external := make(map[int]string, len(file.Issues))
+	for _, is := range file.Issues {
+		external[is.ID] = zelloExternalID(is.Issue)
+	}
+
+	items := make([]ImportIssue, 0, len(file.Issues))
+	for _, is := range file.Issues {
+		it := ImportIssue{
+			ExternalID:  external[is.ID],
+			Title:       is.Title,
+			Description: is.Description,
+			Status:      is.Status,
+			Labels:      is.Labels,
+			AuthorID:    is.UserID,
+			AssigneeID:  is.AssigneeID,
+			SprintID:    is.SprintID,
+			Estimate:    is.Estimate,
+			CreatedAt:   is.CreatedAt,
+		}
+		if is.ExternalID == "" {
+			it.ID = is.ID
+		}
+		if is.ParentID != nil {
+			if ext, ok := external[*is.ParentID]; ok {
+				it.ParentExternalID = ext
+			} else {
+				it.ParentExternalID = "zello:" + strconv.Itoa(*is.ParentID)
+			}
+		}
+		for _, c := range is.Comments {
+			// Comments imported from elsewhere already name their author.
+			ic := ImportComment{ExternalID: c.ExternalID, Body: c.Body, CreatedAt: c.CreatedAt}
+			if ic.ExternalID == "" {
+				ic.ID = c.ID
+				ic.ExternalID = "zello-comment:" + strconv.Itoa(c.ID)
+				ic.AuthorID = c.UserID
+			}
+			it.Comments = append(it.Comments, ic)
+		}
+		items = append(items, it)
+	}
+	return items, nil
+}
+
+func zelloExternalID(is Issue) string {
+	if is.ExternalID != "" {
+		return is.ExternalID
+	}
+	return "zello:" + strconv.Itoa(is.ID)
+}
+
+// CSVFields are the issue fields a CSV column can be mapped to.
+var CSVFields = []string{"external_id", "title", "description", "status", "labels", "assignee", "estimate", "created", "parent"}
+
+// ParseColumnMap parses a CSV column mapping such as
+// "external_id=Key,title=Summary,labels=Tags". Fields left out are read from
+// a column of the same name.
+func ParseColumnMap(spec string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		field, column, ok := strings.Cut(part, "=")
+		field, column = strings.TrimSpace(field), strings.TrimSpace(column)
+		if !ok || column == "" {
+			return nil, fmt.Errorf("invalid column mapping %q, expected field=column", part)
+		}
+		if !contains(CSVFields, field) {
+			return nil, fmt.Errorf("unknown field %q, expected one of %s", field, strings.Join(CSVFields, ", "))
+		}
+		mapping[field] = column
+	}
+	return mapping, nil
+}
+
+// ParseCSV reads issues from a CSV file with a header row. mapping names the
+// column for each field; unmapped fields use the column named after the
+// field, matched case-insensitively. Labels may be separated by commas or
+// semicolons, and parent refers to another row's external ID.
+func ParseCSV(r io.Reader, mapping map[string]string) ([]ImportIssue, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	index := make(map[string]int, len(CSVFields))
+	for _, field := range CSVFields {
+		column := field
+		if c, ok := mapping[field]; ok {
+			column = c
+		}
+		found := false
+		for i, h := range header {
+			if strings.EqualFold(strings.TrimSpace(h), column) {
+				index[field] = i
+				found = true
+				break
+			}
+		}
+		if !found && mapping[field] != "" {
+			return nil, fmt.Errorf("column %q mapped to %s is not in the CSV header", column, field)
+		}
+	}
+	for _, field := range []string{"external_id", "title"} {
+		if _, ok := index[field]; !ok {
+			return nil, fmt.Errorf("CSV has no %s column; map one with --map %s=COLUMN", field, field)
+		}
+	}
+
+	var items []ImportIssue
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		get := func(field string) string {
+			if i, ok := index[field]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		it := ImportIssue{
+			ExternalID:       get("external_id"),
+			ParentExternalID: get("parent"),
+			Title:            get("title"),
+			Description:      get("description"),
+			Status:           strings.ToLower(get("status")),
+			AssigneeID:       get("assignee"),
+			CreatedAt:        get("created"),
+		}
+		if it.ExternalID == "" {
+			return nil, fmt.Errorf("line %d: empty external_id", line)
+		}
+		it.Labels = splitLabels(get("labels"))
+		if v := get("estimate"); v != "" {
+			est, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid estimate %q", line, v)
+			}
+			it.Estimate = &est
+		}
+		items = append(items, it)
+	}
+	return items, nil
+}
+
+func splitLabels(s string) []string {
+	var labels []string
+	for _, l := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' }) {
+		if l = strings.TrimSpace(l); l != "" {
+			labels = append(labels, l)
+		}
+	}
+	return labels
+}
+
+func contains(values []string, v string) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+type githubUser struct {
+	Login string `json:"login"`
+}
+
+type githubComment struct {
+	ID        json.RawMessage `json:"id"`
+	Body      string          `json:"body"`
+	CreatedAt string          `json:"created_at"`
+	Created   string          `json:"createdAt"`
+	User      *githubUser     `json:"user"`
+	Author    *githubUser     `json:"author"`
+}
+
+type githubIssue struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	State  string `json:"state"`
+	Labels []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	HTMLURL     string          `json:"html_url"`
+	URL         string          `json:"url"`
+	CreatedAt   string          `json:"created_at"`
+	Created     string          `json:"createdAt"`
+	PullRequest json.RawMessage `json:"pull_request"`
+	Comments    json.RawMessage `json:"comments"`
+}
+
+var githubIssueURL = regexp.MustCompile(`([^/]+/[^/]+)/issues/(\d+)$`)
+
+// ParseGitHubIssues reads a JSON array of issues as returned by the GitHub
+// REST API (/repos/OWNER/REPO/issues) or by gh issue list --json. Pull
+// requests are skipped. Comments are imported when the dump includes them
+// as an array, as gh does with --json comments.
+func ParseGitHubIssues(data []byte) ([]ImportIssue, error) {
+	var issues []githubIssue
+	if err := json.Unmarshal(data, &issues); err != nil {
+		return nil, fmt.Errorf("failed to read GitHub issues: %w", err)
+	}
+
+	var items []ImportIssue
+	for _, gh := range issues {
+		if len(gh.PullRequest) > 0 && string(gh.PullRequest) != "null" {
+			continue
+		}
+		ext := fmt.Sprintf("github:#%d", gh.Number)
+		for _, u := range []string{gh.HTMLURL, gh.URL} {
+			if m := githubIssueURL.FindStringSubmatch(u); m != nil {
+				ext = fmt.Sprintf("github:%s#%s", m[1], m[2])
+				break
+			}
+		}
+		it := ImportIssue{
+			ExternalID:  ext,
+			Title:       gh.Title,
+			Description: gh.Body,
+			Status:      strings.ToLower(gh.State),
+			CreatedAt:   firstNonEmpty(gh.CreatedAt, gh.Created),
+		}
+		for _, l := range gh.Labels {
+			it.Labels = append(it.Labels, l.Name)
+		}
+
+		var comments []githubComment
+		if len(gh.Comments) > 0 && gh.Comments[0] == '[' {
+			if err := json.Unmarshal(gh.Comments, &comments); err != nil {
+				return nil, fmt.Errorf("failed to read comments of %s: %w", ext, err)
+			}
+		}
+		for i, c := range comments {
+			id := strings.Trim(string(c.ID), `"`)
+			if id == "" || id == "null" {
+				id = fmt.Sprintf("%s/%d", ext, i+1)
+			}
+			author := ""
+			if c.User != nil {
+				author = c.User.Login
+			} else if c.Author != nil {
+				author = c.Author.Login
+			}
+			it.Comments = append(it.Comments, ImportComment{
+				ExternalID: "github-comment:" + id,
+				Author:     author,
+				Body:       c.Body,
+				CreatedAt:  firstNonEmpty(c.CreatedAt, c.Created),
+			})
+		}
+		items = append(items, it)
+	}
+	return items, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+type trelloBoard struct {
+	Lists []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"lists"`
+	Cards []struct {
+		ID     string `json:"id"`
+		Name   string `json:"name"`
+		Desc   string `json:"desc"`
+		IDList string `json:"idList"`
+		Closed bool   `json:"closed"`
+		Labels []struct {
+			Name  string `json:"name"`
+			Color string `json:"color"`
+		} `json:"labels"`
+	} `json:"cards"`
+	Actions []struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+		Date string `json:"date"`
+		Data struct {
+			Text string `json:"text"`
+			Card struct {
+				ID string `json:"id"`
+			} `json:"card"`
+		} `json:"data"`
+		MemberCreator struct {
+			FullName string `json:"fullName"`
+			Username string `json:"username"`
+		} `json:"memberCreator"`
+	} `json:"actions"`
+}
+
+// ParseTrelloBoard reads a Trello board JSON export. Each card becomes an
+// issue whose status is the name of its list, e.g. "In Progress" becomes
+// in_progress; archived cards are closed. Unnamed labels are imported by
+// colour.
+func ParseTrelloBoard(data []byte) ([]ImportIssue, error) {
+	var board trelloBoard
+	if err := json.Unmarshal(data, &board); err != nil {
+		return nil, fmt.Errorf("failed to read Trello board: %w", err)
+	}
+
+	lists := make(map[string]string, len(board.Lists))
+	for _, l := range board.Lists {
+		lists[l.ID] = strings.Join(strings.Fields(strings.ToLower(l.Name)), "_")
+	}
+
+	comments := make(map[string][]ImportComment)
+	for _, a := range board.Actions {
+		if a.Type != "commentCard" {
+			continue
+		}
+		author := firstNonEmpty(a.MemberCreator.FullName, a.MemberCreator.Username)
+		comments[a.Data.Card.ID] = append(comments[a.Data.Card.ID], ImportComment{
+			ExternalID: "trello-comment:" + a.ID,
+			Author:     author,
+			Body:       a.Data.Text,
+			CreatedAt:  a.Date,
+		})
+	}
+
+	items := make([]ImportIssue, 0, len(board.Cards))
+	for _, card := range board.Cards {
+		it := ImportIssue{
+			ExternalID:  "trello:" + card.ID,
+			Title:       card.Name,
+			Description: card.Desc,
+			Status:      lists[card.IDList],
+			CreatedAt:   trelloCreatedAt(card.ID),
+			Comments:    comments[card.ID],
+		}
+		if card.Closed {
+			it.Status = "closed"
+		}
+		for _, l := range card.Labels {
+			if name := firstNonEmpty(l.Name, l.Color); name != "" {
+				it.Labels = append(it.Labels, name)
+			}
+		}
+		// Trello exports actions newest first.
+		sort.SliceStable(it.Comments, func(i, j int) bool { return it.Comments[i].CreatedAt < it.Comments[j].CreatedAt })
+		items = append(items, it)
+	}
+	return items, nil
+}
+
+// trelloCreatedAt recovers a card's creation time from its id, whose first
+// eight hex digits are a Unix timestamp.
+func trelloCreatedAt(id string) string {
+	if len(id) < 8 {
+		return ""
+	}
+	secs, err := strconv.ParseInt(id[:8], 16, 64)
+	if err != nil {
+		return ""
+	}
+	return time.Unix(secs, 0).UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,284 @@
+package internal
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"zel/lo/supabase/supabasetest"
+)
+
+const (
+	importer = "7d8f3c1e-2b4a-4c6d-9e0f-1a2b3c4d5e6f"
+	author   = "0b1c2d3e-4f50-4617-8293-a4b5c6d7e8f9"
+)
+
+// handleReserveNumbers stands in for reserve_board_numbers, handing out
+// numbers from next on.
+func handleReserveNumbers(srv *supabasetest.Server, next int) {
+	srv.HandleRPC("reserve_board_numbers", func(p map[string]interface{}) (interface{}, error) {
+		first := next
+		next += int(p["p_count"].(float64))
+		return first, nil
+	})
+}
+
+// exportFrom seeds a board with an issue written by author in a sprint, a
+// comment from each user, and returns the board exported and parsed back.
+func exportFrom(t *testing.T, srv *supabasetest.Server) []ImportIssue {
+	t.Helper()
+	srv.Seed("boards", map[string]interface{}{"id": 1, "key": "ZEL", "name": "Zello"})
+	srv.Seed("sprints", map[string]interface{}{"id": 3, "board_id": 1, "name": "Sprint 3", "start_date": "2026-10-01", "end_date": "2026-10-14"})
+	srv.Seed("profiles", map[string]interface{}{"id": author, "display_name": "Ada"})
+	srv.Seed("issues",
+		map[string]interface{}{"id": 10, "title": "Parent", "status": "open", "user_id": author, "board_id": 1, "sprint_id": 3, "number": 1, "labels": []string{"bug"}},
+		map[string]interface{}{"id": 11, "title": "Child", "status": "open", "user_id": importer, "board_id": 1, "parent_id": 10, "number": 2, "labels": []string{}},
+	)
+	srv.Seed("comments",
+		map[string]interface{}{"issue_id": 10, "user_id": author, "body": "Seen on Safari"},
+		map[string]interface{}{"issue_id": 10, "user_id": importer, "body": "Fixed"},
+	)
+
+	client := srv.Client()
+	file, err := ExportIssues(client, &Board{ID: 1, Key: "ZEL", Name: "Zello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, err := ParseZelloExport(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return items
+}
+
+func TestImportZelloExportBack(t *testing.T) {
+	srv := supabasetest.NewServer()
+	defer srv.Close()
+	items := exportFrom(t, srv)
+
+	board := 1
+	for _, dryRun := range []bool{true, false} {
+		report, err := ImportIssues(srv.Client(), items, ImportOptions{BoardID: &board, UserID: importer, DryRun: dryRun})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(report.Created) != 0 || len(report.Updated) != 0 || report.Comments != 0 {
+			t.Errorf("dry run %v: re-import changed something: %+v", dryRun, report)
+		}
+		if len(report.Unchanged) != 2 {
+			t.Errorf("dry run %v: unchanged = %v, want both issues", dryRun, report.Unchanged)
+		}
+	}
+	if n := len(srv.Rows("issues")); n != 2 {
+		t.Errorf("%d issues after re-import, want 2", n)
+	}
+	if n := len(srv.Rows("comments")); n != 2 {
+		t.Errorf("%d comments after re-import, want 2", n)
+	}
+
+	// An edited export updates the original in place.
+	items[0].Title = "Parent, renamed"
+	report, err := ImportIssues(srv.Client(), items, ImportOptions{BoardID: &board, UserID: importer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Updated) != 1 || report.Updated[0] != "zello:10" {
+		t.Errorf("updated = %v, want zello:10", report.Updated)
+	}
+	original, err := GetIssue(srv.Client(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if original.Title != "Parent, renamed" || original.ExternalID != "" || original.UserID != author {
+		t.Errorf("original after update = %+v", original)
+	}
+}
+
+func TestImportZelloExportElsewhere(t *testing.T) {
+	source := supabasetest.NewServer()
+	defer source.Close()
+	items := exportFrom(t, source)
+
+	tests := []struct {
+		name       string
+		sprint     bool
+		profile    bool
+		wantSprint bool
+		wantAuthor string
+	}{
+		{"sprint exists", true, false, true, author},
+		{"sprint missing", false, false, false, author},
+		{"author has a profile", false, true, false, "Ada"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := supabasetest.NewServer()
+			defer srv.Close()
+			srv.Seed("boards", map[string]interface{}{"id": 1, "key": "ZEL", "name": "Zello"})
+			if tt.sprint {
+				srv.Seed("sprints", map[string]interface{}{"id": 3, "board_id": 1, "name": "Sprint 3", "start_date": "2026-10-01", "end_date": "2026-10-14"})
+			}
+			if tt.profile {
+				srv.Seed("profiles", map[string]interface{}{"id": author, "display_name": "Ada"})
+			}
+			// Issue 10 here is someone else's, created at another time.
+			srv.Seed("issues", map[string]interface{}{"id": 10, "title": "Unrelated", "status": "open", "created_at": "2020-01-01T00:00:00Z"})
+			handleReserveNumbers(srv, 4)
+
+			board := 1
+			report, err := ImportIssues(srv.Client(), items, ImportOptions{BoardID: &board, UserID: importer})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(report.Created) != 2 || report.Comments != 2 {
+				t.Fatalf("report = %+v, want 2 issues and 2 comments created", report)
+			}
+
+			issues := map[string]map[string]interface{}{}
+			for _, r := range srv.Rows("issues") {
+				if ext, _ := r["external_id"].(string); ext != "" {
+					issues[ext] = r
+				} else if r["title"] != "Unrelated" {
+					t.Errorf("import touched issue %v", r)
+				}
+			}
+			parent := issues["zello:10"]
+			if parent["user_id"] != author {
+				t.Errorf("parent author = %v, want %s", parent["user_id"], author)
+			}
+			if got := parent["sprint_id"] != nil; got != tt.wantSprint {
+				t.Errorf("parent sprint = %v, want kept %v", parent["sprint_id"], tt.wantSprint)
+			}
+			if parent["number"] != float64(4) || issues["zello:11"]["number"] != float64(5) {
+				t.Errorf("numbers = %v, %v, want 4 and 5", parent["number"], issues["zello:11"]["number"])
+			}
+			if issues["zello:11"]["parent_id"] != parent["id"] {
+				t.Errorf("child parent = %v, want %v", issues["zello:11"]["parent_id"], parent["id"])
+			}
+
+			var bodies []string
+			for _, c := range srv.Rows("comments") {
+				if c["user_id"] != importer {
+					t.Errorf("comment stored as %v", c["user_id"])
+				}
+				bodies = append(bodies, c["body"].(string))
+			}
+			want := tt.wantAuthor + " wrote:\n\nSeen on Safari|Fixed"
+			if got := strings.Join(bodies, "|"); got != want {
+				t.Errorf("comments = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestImportIssues(t *testing.T) {
+	srv := supabasetest.NewServer()
+	defer srv.Close()
+	srv.Unique("issues", "external_id")
+	srv.Unique("comments", "external_id")
+	client := srv.Client()
+	board := 1
+	opts := ImportOptions{BoardID: &board, UserID: importer}
+	handleReserveNumbers(srv, 1)
+
+	items := []ImportIssue{
+		{ExternalID: "gh:1", Title: "Epic", Status: "open", Labels: []string{"epic"}},
+		{ExternalID: "gh:2", ParentExternalID: "gh:1", Title: "Task", Comments: []ImportComment{
+			{ExternalID: "ghc:1", Author: "octocat", Body: "On it"},
+		}},
+	}
+
+	steps := []struct {
+		name    string
+		edit    func([]ImportIssue)
+		dryRun  bool
+		created int
+		updated int
+		same    int
+		comms   int
+		issues  int
+	}{
+		{"dry run", nil, true, 2, 0, 0, 1, 0},
+		{"first import", nil, false, 2, 0, 0, 1, 2},
+		{"again", nil, false, 0, 0, 2, 0, 2},
+		{"edited", func(items []ImportIssue) {
+			items[1].Status = "closed"
+			items[1].Comments = append(items[1].Comments, ImportComment{ExternalID: "ghc:2", Body: "Done"})
+		}, false, 0, 1, 1, 1, 2},
+	}
+	for _, st := range steps {
+		if st.edit != nil {
+			st.edit(items)
+		}
+		opts.DryRun = st.dryRun
+		report, err := ImportIssues(client, items, opts)
+		if err != nil {
+			t.Fatalf("%s: %v", st.name, err)
+		}
+		if len(report.Created) != st.created || len(report.Updated) != st.updated || len(report.Unchanged) != st.same || report.Comments != st.comms {
+			t.Errorf("%s: report = %+v", st.name, report)
+		}
+		if n := len(srv.Rows("issues")); n != st.issues {
+			t.Errorf("%s: %d issues stored, want %d", st.name, n, st.issues)
+		}
+	}
+
+	rows := srv.Rows("issues")
+	if rows[1]["parent_id"] != rows[0]["id"] || rows[1]["status"] != "closed" || rows[1]["board_id"] != float64(1) {
+		t.Errorf("task = %v", rows[1])
+	}
+	if rows[0]["number"] != float64(1) || rows[1]["number"] != float64(2) {
+		t.Errorf("numbers = %v, %v, want 1 and 2", rows[0]["number"], rows[1]["number"])
+	}
+	comments := srv.Rows("comments")
+	if len(comments) != 2 || comments[0]["body"] != "octocat wrote:\n\nOn it" || comments[1]["body"] != "Done" {
+		t.Errorf("comments = %v", comments)
+	}
+}
+
+func TestImportNumbersIssuesMovedToBoard(t *testing.T) {
+	srv := supabasetest.NewServer()
+	defer srv.Close()
+	srv.Seed("issues", map[string]interface{}{"id": 1, "external_id": "gh:1", "title": "Loose", "status": "open", "labels": []string{}})
+	handleReserveNumbers(srv, 7)
+
+	board := 2
+	items := []ImportIssue{{ExternalID: "gh:1", Title: "Loose", Status: "open"}}
+	report, err := ImportIssues(srv.Client(), items, ImportOptions{BoardID: &board, UserID: importer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Updated) != 1 {
+		t.Fatalf("report = %+v, want the issue moved", report)
+	}
+	if row := srv.Rows("issues")[0]; row["board_id"] != float64(2) || row["number"] != float64(7) {
+		t.Errorf("moved issue = %v, want number 7 on board 2", row)
+	}
+}
+
+func TestImportIssuesInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []ImportIssue
+		want  string
+	}{
+		{"no external id", []ImportIssue{{Title: "A"}}, "has no external id"},
+		{"no title", []ImportIssue{{ExternalID: "x:1"}}, "has no title"},
+		{"duplicate", []ImportIssue{{ExternalID: "x:1", Title: "A"}, {ExternalID: "x:1", Title: "B"}}, "appears more than once"},
+		{"unknown parent", []ImportIssue{{ExternalID: "x:1", Title: "A", ParentExternalID: "x:9"}}, "parent x:9 not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := supabasetest.NewServer()
+			defer srv.Close()
+			_, err := ImportIssues(srv.Client(), tt.items, ImportOptions{UserID: importer})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package internal
 
 import (
 	"fmt"
+	"strconv"
 
 	"zel/lo/supabase"
 )
@@ -13,19 +14,35 @@ type Issue struct {
     Description string    `json:"description"`
     Status      string    `json:"status"`
     UserID      string    `json:"user_id"`   
+    AssigneeID  string    `json:"assignee_id,omitempty"`
+    Labels      []string  `json:"labels,omitempty"`
+    ParentID    *int      `json:"parent_id,omitempty"`
+    BoardID     *int      `json:"board_id,omitempty"`
+    SprintID    *int      `json:"sprint_id,omitempty"`
+    Estimate    *float64  `json:"estimate,omitempty"`
+    ExternalID  string    `json:"external_id,omitempty"`
+    Number      int       `json:"number,omitempty"`
    CreatedAt string `json:"created_at"`
+    UpdatedAt   string    `json:"updated_at,omitempty"`
 }
 
 type CreateIssueRequest struct {
     Title       string `json:"title"`
     Description string `json:"description,omitempty"`
     Status      string `json:"status,omitempty"`
+    ParentID    *int   `json:"parent_id,omitempty"`
+    BoardID     *int   `json:"board_id,omitempty"`
+    Labels      []string `json:"labels,omitempty"`
 }
 
 
-type User struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
+type Comment struct {
+	ID         int    `json:"id"`
+	IssueID    int    `json:"issue_id"`
+	UserID     string `json:"user_id"`
+	Body       string `json:"body"`
+	ExternalID string `json:"external_id,omitempty"`
+	CreatedAt  string `json:"created_at"`
 }
 
 
@@ -40,6 +57,22 @@ func ListIssues(client *supabase.Client) ([]Issue, error) {
 	return issues, nil
 }
 
+// GetIssue fetches a single issue by id.
+func GetIssue(client *supabase.Client, id int) (*Issue, error) {
+	var issues []Issue
+
+	_, err := client.From("issues").Select("*", "", false).Eq("id", strconv.Itoa(id)).ExecuteTo(&issues)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch issue: %w", err)
+	}
+
+	if len(issues) == 0 {
+		return nil, fmt.Errorf("issue #%d not found", id)
+	}
+
+	return &issues[0], nil
+}
+
 
 
 func CreateIssue(client *supabase.Client, issueRequest CreateIssueRequest, userID string) (*Issue, error) {
@@ -59,7 +92,19 @@ func CreateIssue(client *supabase.Client, issueRequest CreateIssueRequest, userI
 		issueData["status"] = "open" 
 	}
 
-	_, err := client.From("issues").Insert([]map[string]interface{}{issueData}, false, "", "minimal", "").ExecuteTo(&issues)
+	if issueRequest.ParentID != nil {
+		issueData["parent_id"] = *issueRequest.ParentID
+	}
+
+	if issueRequest.BoardID != nil {
+		issueData["board_id"] = *issueRequest.BoardID
+	}
+
+	if len(issueRequest.Labels) > 0 {
+		issueData["labels"] = issueRequest.Labels
+	}
+
+	_, err := client.From("issues").Insert([]map[string]interface{}{issueData}, false, "", "representation", "").ExecuteTo(&issues)
 
 	if err != nil {
 		return nil, fmt.Errorf("error while creating a issue %w", err)
@@ -70,4 +115,76 @@ func CreateIssue(client *supabase.Client, issueRequest CreateIssueRequest, userI
 	}
 
 	return &issues[0], nil
-}
\ No newline at end of file
+}
+// UpdateIssue changes an issue's title and description.
+func UpdateIssue(client *supabase.Client, id int, title, description string) (*Issue, error) {
+	var issues []Issue
+
+	_, err := client.From("issues").
+		Update(map[string]interface{}{"title": title, "description": description}, "representation", "").
+		Eq("id", strconv.Itoa(id)).
+		ExecuteTo(&issues)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update issue: %w", err)
+	}
+
+	if len(issues) == 0 {
+		return nil, fmt.Errorf("issue #%d not found", id)
+	}
+
+	return &issues[0], nil
+}
+
+// SetIssueLabels replaces an issue's labels.
+func SetIssueLabels(client *supabase.Client, id int, labels []string) (*Issue, error) {
+	if labels == nil {
+		labels = []string{}
+	}
+
+	var issues []Issue
+	_, err := client.From("issues").
+		Update(map[string]interface{}{"labels": labels}, "representation", "").
+		Eq("id", strconv.Itoa(id)).
+		ExecuteTo(&issues)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set labels: %w", err)
+	}
+	if len(issues) == 0 {
+		return nil, fmt.Errorf("issue #%d not found", id)
+	}
+	return &issues[0], nil
+}
+
+// SetIssueAssignee assigns an issue to a user; an empty assigneeID
+// unassigns it.
+func SetIssueAssignee(client *supabase.Client, id int, assigneeID string) (*Issue, error) {
+	var assignee interface{}
+	if assigneeID != "" {
+		assignee = assigneeID
+	}
+
+	var issues []Issue
+	_, err := client.From("issues").
+		Update(map[string]interface{}{"assignee_id": assignee}, "representation", "").
+		Eq("id", strconv.Itoa(id)).
+		ExecuteTo(&issues)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assign issue: %w", err)
+	}
+	if len(issues) == 0 {
+		return nil, fmt.Errorf("issue #%d not found", id)
+	}
+	return &issues[0], nil
+}
+
+// DeleteIssue removes an issue. Its comments, links and worklogs go with it.
+func DeleteIssue(client *supabase.Client, id int) error {
+	_, _, err := client.From("issues").
+		Delete("minimal", "").
+		Eq("id", strconv.Itoa(id)).
+		Execute()
+	if err != nil {
+		return fmt.Errorf("failed to delete issue: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package internal
+
+import (
+	"testing"
+
+	"zel/lo/supabase/supabasetest"
+)
+
+func TestListIssues(t *testing.T) {
+	tests := []struct {
+		name string
+		seed []map[string]interface{}
+		want []string
+	}{
+		{"empty", nil, nil},
+		{"all issues", []map[string]interface{}{
+			{"title": "First", "status": "open"},
+			{"title": "Second", "status": "closed", "labels": []string{"bug"}},
+		}, []string{"First", "Second"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := supabasetest.NewServer()
+			defer srv.Close()
+			srv.Seed("issues", tt.seed...)
+
+			issues, err := ListIssues(srv.Client())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(issues) != len(tt.want) {
+				t.Fatalf("got %d issues, want %d", len(issues), len(tt.want))
+			}
+			for i, is := range issues {
+				if is.Title != tt.want[i] || is.ID == 0 {
+					t.Errorf("issue %d = %+v, want %s", i, is, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGetIssue(t *testing.T) {
+	srv := supabasetest.NewServer()
+	defer srv.Close()
+	srv.Seed("issues", map[string]interface{}{"id": 5, "title": "Crash", "status": "open", "board_id": 1, "number": 2})
+
+	is, err := GetIssue(srv.Client(), 5)
+	if err != nil || is.Title != "Crash" {
+		t.Errorf("GetIssue(5) = %+v, %v", is, err)
+	}
+	if _, err := GetIssue(srv.Client(), 6); err == nil || err.Error() != "issue #6 not found" {
+		t.Errorf("GetIssue(6) error = %v", err)
+	}
+
+	board := &Board{ID: 1, Key: "ZEL"}
+	is, err = GetBoardIssue(srv.Client(), board, 2)
+	if err != nil || is.ID != 5 {
+		t.Errorf("GetBoardIssue(ZEL-2) = %+v, %v", is, err)
+	}
+	if _, err := GetBoardIssue(srv.Client(), board, 5); err == nil || err.Error() != "issue ZEL-5 not found" {
+		t.Errorf("GetBoardIssue(ZEL-5) error = %v", err)
+	}
+}
+
+func TestListIssuesQuery(t *testing.T) {
+	srv := supabasetest.NewServer()
+	defer srv.Close()
+	srv.Seed("issues",
+		map[string]interface{}{"id": 1, "title": "Login fails on Safari", "status": "open", "labels": []string{"bug"}, "assignee_id": "me-id", "user_id": "other", "created_at": "2026-01-05T10:00:00Z"},
+		map[string]interface{}{"id": 2, "title": "Dark mode", "description": "Follow the system theme", "status": "in_progress", "labels": []string{"feature"}, "user_id": "me-id", "created_at": "2026-02-10T10:00:00Z"},
+		map[string]interface{}{"id": 3, "title": "Crash on start", "status": "closed", "labels": []string{"bug", "wontfix"}, "assignee_id": "other", "user_id": "other", "created_at": "2026-03-01T10:00:00Z"},
+	)
+
+	tests := []struct {
+		query string
+		sort  string
+		want  []int
+	}{
+		{"", "id", []int{1, 2, 3}},
+		{"", "id.desc", []int{3, 2, 1}},
+		{"status:open", "", []int{1}},
+		{"label:bug", "id", []int{1, 3}},
+		{"label:bug -label:wontfix", "", []int{1}},
+		{"assignee:me", "", []int{1}},
+		{"assignee:none", "", []int{2}},
+		{"author:me", "", []int{2}},
+		{"(status:open OR status:in_progress)", "title", []int{2, 1}},
+		{"-(status:open OR status:closed)", "", []int{2}},
+		{"title:crash", "", []int{3}},
+		{"theme", "", []int{2}},
+		{"id:3", "", []int{3}},
+		{"created:>2026-02-01", "id", []int{2, 3}},
+		{"created:2026-01-01..2026-02-10", "id", []int{1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query+" "+tt.sort, func(t *testing.T) {
+			issues, err := ListIssuesQuery(srv.Client(), tt.query, tt.sort, "me-id")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []int
+			for _, is := range issues {
+				got = append(got, is.ID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	for _, bad := range []struct{ query, sort string }{
+		{"status:", ""},
+		{"(status:open", ""},
+		{"colour:red", ""},
+		{"", "priority"},
+		{"", "id.sideways"},
+	} {
+		if _, err := ListIssuesQuery(srv.Client(), bad.query, bad.sort, "me-id"); err == nil {
+			t.Errorf("ListIssuesQuery(%q, %q) succeeded", bad.query, bad.sort)
+		}
+	}
+}
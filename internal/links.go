@@ -0,0 +1,216 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"zel/lo/supabase"
+)
+
+type LinkKind string
+
+// Links are stored in one direction only: the source blocks, duplicates or
+// relates to the target. "Blocked by" and "duplicated by" are the same
+// links read from the target's side.
+const (
+	LinkBlocks     LinkKind = "blocks"
+	LinkDuplicates LinkKind = "duplicates"
+	LinkRelatesTo  LinkKind = "relates_to"
+)
+
+type IssueLink struct {
+	ID        int      `json:"id,omitempty"`
+	SourceID  int      `json:"source_id"`
+	TargetID  int      `json:"target_id"`
+	Kind      LinkKind `json:"kind"`
+	CreatedAt string   `json:"created_at,omitempty"`
+}
+
+// BlockedError is returned when closing an issue whose blockers are still
+// open.
+type BlockedError struct {
+	IssueID  int
+	Blockers []int
+}
+
+func (e *BlockedError) Error() string {
+	ids := make([]string, len(e.Blockers))
+	for i, id := range e.Blockers {
+		ids[i] = "#" + strconv.Itoa(id)
+	}
+	return fmt.Sprintf("issue #%d is blocked by open issues %s", e.IssueID, strings.Join(ids, ", "))
+}
+
+// Relation describes the link from issueID's point of view and returns the
+// issue on the other end, e.g. "blocked by", 7.
+func (l IssueLink) Relation(issueID int) (string, int) {
+	if l.SourceID == issueID {
+		if l.Kind == LinkRelatesTo {
+			return "relates to", l.TargetID
+		}
+		return string(l.Kind), l.TargetID
+	}
+	switch l.Kind {
+	case LinkBlocks:
+		return "blocked by", l.SourceID
+	case LinkDuplicates:
+		return "duplicated by", l.SourceID
+	}
+	return "relates to", l.SourceID
+}
+
+// isClosedStatus reports whether a status counts as done.
+func isClosedStatus(status string) bool {
+	return status == "closed" || status == "done"
+}
+
+// LinkIssues records that source blocks, duplicates or relates to target.
+func LinkIssues(client *supabase.Client, sourceID, targetID int, kind LinkKind) (*IssueLink, error) {
+	if err := checkLink(sourceID, targetID, kind); err != nil {
+		return nil, err
+	}
+
+	var links []IssueLink
+
+	linkData := map[string]interface{}{
+		"source_id": sourceID,
+		"target_id": targetID,
+		"kind":      kind,
+	}
+
+	_, err := client.From("issue_links").
+		Upsert([]map[string]interface{}{linkData}, "source_id,target_id,kind", "representation", "").
+		ExecuteTo(&links)
+	if err != nil {
+		return nil, fmt.Errorf("failed to link issues: %w", err)
+	}
+
+	if len(links) == 0 {
+		return nil, fmt.Errorf("link succeeded but no link returned")
+	}
+
+	return &links[0], nil
+}
+
+func checkLink(sourceID, targetID int, kind LinkKind) error {
+	switch kind {
+	case LinkBlocks, LinkDuplicates, LinkRelatesTo:
+	default:
+		return fmt.Errorf("unknown link kind %q", kind)
+	}
+	if sourceID == targetID {
+		return fmt.Errorf("an issue cannot be linked to itself")
+	}
+	return nil
+}
+
+// UnlinkIssues removes every link between two issues, in either direction.
+func UnlinkIssues(client *supabase.Client, issueID, otherID int) error {
+	a, b := strconv.Itoa(issueID), strconv.Itoa(otherID)
+	_, _, err := client.From("issue_links").
+		Delete("minimal", "").
+		Or(fmt.Sprintf("and(source_id.eq.%s,target_id.eq.%s),and(source_id.eq.%s,target_id.eq.%s)", a, b, b, a), "").
+		Execute()
+	if err != nil {
+		return fmt.Errorf("failed to unlink issues: %w", err)
+	}
+	return nil
+}
+
+// ListIssueLinks returns the links on either side of an issue.
+func ListIssueLinks(client *supabase.Client, issueID int) ([]IssueLink, error) {
+	var links []IssueLink
+
+	id := strconv.Itoa(issueID)
+	_, err := client.From("issue_links").
+		Select("*", "", false).
+		Or(fmt.Sprintf("source_id.eq.%s,target_id.eq.%s", id, id), "").
+		ExecuteTo(&links)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch issue links: %w", err)
+	}
+
+	return links, nil
+}
+
+// OpenBlockers maps each of the given issues to the open issues blocking it.
+// Issues without open blockers are left out.
+func OpenBlockers(client *supabase.Client, issueIDs []int) (map[int][]int, error) {
+	if len(issueIDs) == 0 {
+		return nil, nil
+	}
+
+	var links []IssueLink
+	_, err := client.From("issue_links").
+		Select("*", "", false).
+		Eq("kind", string(LinkBlocks)).
+		In("target_id", idStrings(issueIDs)).
+		ExecuteTo(&links)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch blockers: %w", err)
+	}
+	if len(links) == 0 {
+		return nil, nil
+	}
+
+	sources := make([]int, 0, len(links))
+	for _, l := range links {
+		sources = append(sources, l.SourceID)
+	}
+	var blockers []Issue
+	_, err = client.From("issues").
+		Select("id,status", "", false).
+		In("id", idStrings(sources)).
+		ExecuteTo(&blockers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch blockers: %w", err)
+	}
+	open := make(map[int]bool, len(blockers))
+	for _, b := range blockers {
+		if !isClosedStatus(b.Status) {
+			open[b.ID] = true
+		}
+	}
+
+	blocked := make(map[int][]int)
+	for _, l := range links {
+		if open[l.SourceID] {
+			blocked[l.TargetID] = append(blocked[l.TargetID], l.SourceID)
+		}
+	}
+	for _, ids := range blocked {
+		sort.Ints(ids)
+	}
+	return blocked, nil
+}
+
+// CloseAsDuplicate closes dupID as a duplicate of originalID: it links the
+// two, closes the duplicate with a duplicate label, and leaves a comment on
+// the original so its watchers see the new report. A duplicate is closed
+// even while issues block it.
+func CloseAsDuplicate(ctx context.Context, store IssueStore, dupID, originalID int, userID string) error {
+	if _, err := store.LinkIssues(ctx, dupID, originalID, LinkDuplicates); err != nil {
+		return err
+	}
+
+	dup, err := store.GetIssue(ctx, dupID)
+	if err != nil {
+		return err
+	}
+	if _, err := store.SetLabels(ctx, dupID, withLabel(dup.Labels, "duplicate", true)); err != nil {
+		return err
+	}
+	req := TransitionRequest{IssueID: dupID, Status: "closed", Force: true}
+	if _, err := store.TransitionIssue(ctx, req, userID); err != nil {
+		return fmt.Errorf("failed to close duplicate: %w", err)
+	}
+
+	body := fmt.Sprintf("#%d was closed as a duplicate of this issue.", dupID)
+	if _, err := store.CreateComment(ctx, originalID, userID, body); err != nil {
+		return err
+	}
+	return nil
+}
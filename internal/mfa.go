@@ -0,0 +1,104 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"zel/lo/supabase"
+
+	"github.com/google/uuid"
+	"github.com/skip2/go-qrcode"
+	"github.com/supabase-community/auth-go/types"
+)
+
+// mfaIssuer is the account name authenticator apps show next to the codes.
+const mfaIssuer = "Zello"
+
+// VerifiedFactors returns the authenticator apps user has finished
+// enrolling. Abandoned enrolments stay unverified and are left out.
+func VerifiedFactors(user types.User) []types.Factor {
+	var factors []types.Factor
+	for _, f := range user.Factors {
+		if f.Status == "verified" && f.FactorType == string(types.FactorTypeTOTP) {
+			factors = append(factors, f)
+		}
+	}
+	return factors
+}
+
+// PendingFactor returns the factor to challenge before session may be used,
+// or nil when the session already has the assurance its user can reach.
+func PendingFactor(session types.Session) *types.Factor {
+	current, next := supabase.AssuranceLevel(session)
+	if current == next {
+		return nil
+	}
+	factors := VerifiedFactors(session.User)
+	if len(factors) == 0 {
+		return nil
+	}
+	return &factors[0]
+}
+
+// VerifyMFA answers a challenge on factorID with a code from the
+// authenticator app.
+func VerifyMFA(client *supabase.Client, factorID uuid.UUID, code string) (types.Session, error) {
+	code = strings.TrimSpace(code)
+	if err := ValidateTOTPCode(code); err != nil {
+		return types.Session{}, err
+	}
+	session, err := client.VerifyFactor(factorID, code)
+	if err != nil {
+		return types.Session{}, fmt.Errorf("invalid or expired code: %w", err)
+	}
+	return session, nil
+}
+
+// ValidateTOTPCode reports whether code looks like an authenticator app code.
+func ValidateTOTPCode(code string) error {
+	if len(code) != 6 || strings.Trim(code, "0123456789") != "" {
+		return fmt.Errorf("code must be the 6 digits shown in your authenticator app")
+	}
+	return nil
+}
+
+// EnrollTOTP starts adding an authenticator app named name. The factor only
+// counts once ConfirmTOTP checks a code from the app; until then it can be
+// dropped with RemoveFactor.
+func EnrollTOTP(client *supabase.Client, name string) (*types.EnrollFactorResponse, error) {
+	resp, err := client.Auth.EnrollFactor(types.EnrollFactorRequest{
+		FriendlyName: strings.TrimSpace(name),
+		FactorType:   types.FactorTypeTOTP,
+		Issuer:       mfaIssuer,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add authenticator app: %w", err)
+	}
+	return resp, nil
+}
+
+// ConfirmTOTP finishes an enrolment with the first code from the app. The
+// session is upgraded to AAL2 on the way.
+func ConfirmTOTP(client *supabase.Client, factorID uuid.UUID, code string) error {
+	_, err := VerifyMFA(client, factorID, code)
+	return err
+}
+
+// RemoveFactor unenrolls an authenticator app. GoTrue only removes a
+// verified one from an AAL2 session.
+func RemoveFactor(client *supabase.Client, factorID uuid.UUID) error {
+	if _, err := client.Auth.UnenrollFactor(types.UnenrollFactorRequest{FactorID: factorID}); err != nil {
+		return fmt.Errorf("failed to remove authenticator app: %w", err)
+	}
+	return nil
+}
+
+// TerminalQR draws uri as a QR code of half-height blocks, light on a dark
+// terminal, for scanning an otpauth:// URI into an authenticator app.
+func TerminalQR(uri string) (string, error) {
+	qr, err := qrcode.New(uri, qrcode.Low)
+	if err != nil {
+		return "", fmt.Errorf("failed to draw QR code: %w", err)
+	}
+	return qr.ToSmallString(false), nil
+}
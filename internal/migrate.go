@@ -0,0 +1,197 @@
+package internal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// migrationLock is the advisory lock key held while migrating, so two
+// migrate runs against one database cannot interleave.
+const migrationLock = 7_236_611_000
+
+const migrationsTable = `create table if not exists schema_migrations (
+  version integer primary key,
+  name text not null,
+  checksum text not null,
+  applied_at timestamptz not null default now()
+)`
+
+// Migration is one versioned SQL file, NNNN_name.sql.
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+func (m Migration) String() string {
+	return fmt.Sprintf("%04d_%s", m.Version, m.Name)
+}
+
+func (m Migration) checksum() string {
+	sum := sha256.Sum256([]byte(m.SQL))
+	return hex.EncodeToString(sum[:])
+}
+
+// MigrationState is a migration and whether it has been applied. Modified
+// is set when the file changed after it was applied.
+type MigrationState struct {
+	Migration
+	AppliedAt *time.Time
+	Modified  bool
+}
+
+// LoadMigrations reads the .sql files at the root of fsys in version order.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	paths, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+	var migrations []Migration
+	seen := map[int]string{}
+	for _, p := range paths {
+		base := strings.TrimSuffix(path.Base(p), ".sql")
+		num, name, ok := strings.Cut(base, "_")
+		version, err := strconv.Atoi(num)
+		if !ok || err != nil || name == "" {
+			return nil, fmt.Errorf("migration %s is not named NNNN_name.sql", p)
+		}
+		if other, dup := seen[version]; dup {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", other, p, version)
+		}
+		seen[version] = p
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", p, err)
+		}
+		migrations = append(migrations, Migration{Version: version, Name: name, SQL: string(data)})
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// MigrationStatus reports which migrations have been applied to the
+// database. Versions recorded in the database without a file are returned
+// too, with an empty SQL. It only reads: a database without
+// schema_migrations has every migration pending.
+func MigrationStatus(ctx context.Context, conn *pgx.Conn, migrations []Migration) ([]MigrationState, error) {
+	var exists bool
+	if err := conn.QueryRow(ctx, "select to_regclass('schema_migrations') is not null").Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	done := map[int]appliedMigration{}
+	if exists {
+		var err error
+		if done, err = appliedMigrations(ctx, conn); err != nil {
+			return nil, err
+		}
+	}
+
+	states := make([]MigrationState, 0, len(migrations))
+	for _, m := range migrations {
+		st := MigrationState{Migration: m}
+		if a, ok := done[m.Version]; ok {
+			at := a.at
+			st.AppliedAt = &at
+			st.Modified = a.checksum != m.checksum()
+			delete(done, m.Version)
+		}
+		states = append(states, st)
+	}
+	for version, a := range done {
+		at := a.at
+		states = append(states, MigrationState{Migration: Migration{Version: version, Name: a.name}, AppliedAt: &at})
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Version < states[j].Version })
+	return states, nil
+}
+
+// Migrate applies the pending migrations in version order, each in its own
+// transaction, calling applied after each one. It stops at the first
+// failure; the migrations before it stay applied.
+func Migrate(ctx context.Context, conn *pgx.Conn, migrations []Migration, applied func(Migration)) error {
+	if _, err := conn.Exec(ctx, "select pg_advisory_lock($1)", migrationLock); err != nil {
+		return fmt.Errorf("failed to lock database for migration: %w", err)
+	}
+	defer conn.Exec(context.Background(), "select pg_advisory_unlock($1)", migrationLock)
+
+	if _, err := conn.Exec(ctx, migrationsTable); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	states, err := MigrationStatus(ctx, conn, migrations)
+	if err != nil {
+		return err
+	}
+	for _, st := range states {
+		if st.AppliedAt != nil {
+			if st.Modified {
+				return fmt.Errorf("migration %s was changed after it was applied", st.Migration)
+			}
+			continue
+		}
+		if err := applyMigration(ctx, conn, st.Migration); err != nil {
+			return err
+		}
+		if applied != nil {
+			applied(st.Migration)
+		}
+	}
+	return nil
+}
+
+type appliedMigration struct {
+	name, checksum string
+	at             time.Time
+}
+
+// appliedMigrations reads schema_migrations by version.
+func appliedMigrations(ctx context.Context, conn *pgx.Conn) (map[int]appliedMigration, error) {
+	rows, err := conn.Query(ctx, "select version, name, checksum, applied_at from schema_migrations order by version")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+	done := map[int]appliedMigration{}
+	for rows.Next() {
+		var version int
+		var a appliedMigration
+		if err := rows.Scan(&version, &a.name, &a.checksum, &a.at); err != nil {
+			return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+		}
+		done[version] = a
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	return done, nil
+}
+
+func applyMigration(ctx context.Context, conn *pgx.Conn, m Migration) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to apply migration %s: %w", m, err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, m.SQL); err != nil {
+		return fmt.Errorf("failed to apply migration %s: %w", m, err)
+	}
+	if _, err := tx.Exec(ctx,
+		"insert into schema_migrations (version, name, checksum) values ($1, $2, $3)",
+		m.Version, m.Name, m.checksum()); err != nil {
+		return fmt.Errorf("failed to record migration %s: %w", m, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to apply migration %s: %w", m, err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ZELLO_TEST_DATABASE_URL points the migration tests at a scratch Postgres
+// database; they are skipped without one.
+func testConn(t *testing.T) *pgx.Conn {
+	t.Helper()
+	url := os.Getenv("ZELLO_TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("ZELLO_TEST_DATABASE_URL is not set")
+	}
+	ctx := context.Background()
+	conn, err := pgx.Connect(ctx, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close(context.Background()) })
+	// Work in a schema of our own that disappears with the test.
+	for _, sql := range []string{"create schema zello_test", "set search_path = zello_test"} {
+		if _, err := conn.Exec(ctx, sql); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() { conn.Exec(context.Background(), "drop schema zello_test cascade") })
+	return conn
+}
+
+func TestMigrationStatusIsReadOnly(t *testing.T) {
+	conn := testConn(t)
+	ctx := context.Background()
+	migrations := []Migration{{Version: 1, Name: "first", SQL: "create table first (id int)"}}
+
+	states, err := MigrationStatus(ctx, conn, migrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(states) != 1 || states[0].AppliedAt != nil {
+		t.Errorf("states = %+v, want one pending", states)
+	}
+	var exists bool
+	if err := conn.QueryRow(ctx, "select to_regclass('schema_migrations') is not null").Scan(&exists); err != nil {
+		t.Fatal(err)
+	}
+	if exists {
+		t.Error("status created schema_migrations")
+	}
+
+	if err := Migrate(ctx, conn, migrations, nil); err != nil {
+		t.Fatal(err)
+	}
+	states, err = MigrationStatus(ctx, conn, migrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(states) != 1 || states[0].AppliedAt == nil || states[0].Modified {
+		t.Errorf("states after migrating = %+v", states)
+	}
+}
@@ -0,0 +1,77 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// minPasswordLength matches Supabase's default so accounts can move between
+// backends.
+const minPasswordLength = 6
+
+var ErrInvalidCredentials = errors.New("invalid email or password")
+
+// dummyHash is compared against when an email is unknown so that a failed
+// sign-in takes as long whether or not the account exists.
+var dummyHash = []byte("$2a$10$nHiM30gDy/FkG7k.aKmkEOwUgfKBgaM7PPjojmCxhm19cSMZtZ.K.")
+
+// SignUp creates a local account and its profile and returns the account
+// id.
+func (s *PostgresStore) SignUp(ctx context.Context, email, password, name string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if err := ValidateEmail(email); err != nil {
+		return "", err
+	}
+	if err := ValidatePassword(password); err != nil {
+		return "", err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	var id string
+	err = s.Pool.QueryRow(ctx, `with account as (
+			insert into users (email, password_hash) values ($1, $2) returning uid
+		)
+		insert into profiles (id, display_name) select uid, $3 from account
+		returning id::text`,
+		email, string(hash), strings.TrimSpace(name)).Scan(&id)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return "", fmt.Errorf("an account for %s already exists", email)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to create account: %w", err)
+	}
+	return id, nil
+}
+
+// SignIn checks a password against the stored hash and returns the user's
+// id, or ErrInvalidCredentials. Users without a hash, like those carried
+// over from the Supabase schema, cannot sign in with a password.
+func (s *PostgresStore) SignIn(ctx context.Context, email, password string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	var id string
+	var hash *string
+	err := s.Pool.QueryRow(ctx,
+		"select uid::text, password_hash from users where email = $1", email).Scan(&id, &hash)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && hash == nil) {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return "", ErrInvalidCredentials
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to sign in: %w", err)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(*hash), []byte(password)) != nil {
+		return "", ErrInvalidCredentials
+	}
+	return id, nil
+}
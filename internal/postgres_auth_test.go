@@ -0,0 +1,72 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// testPostgresStore returns a store on the schema testConn sets up, with
+// just the users and profiles tables accounts need.
+func testPostgresStore(t *testing.T) *PostgresStore {
+	t.Helper()
+	conn := testConn(t)
+	ctx := context.Background()
+	_, err := conn.Exec(ctx, `create table users (
+			uid uuid primary key default gen_random_uuid(),
+			email text unique,
+			password_hash text
+		);
+		create table profiles (id uuid primary key, display_name text not null default '')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := pgxpool.ParseConfig(conn.Config().ConnString())
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.ConnConfig.RuntimeParams["search_path"] = "zello_test"
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	return &PostgresStore{Pool: pool}
+}
+
+func TestPostgresSignIn(t *testing.T) {
+	s := testPostgresStore(t)
+	ctx := context.Background()
+	id, err := s.SignUp(ctx, "Ada@example.com", "correct horse", "Ada")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Accounts carried over from Supabase have no password hash.
+	if _, err := s.Pool.Exec(ctx, "insert into users (email) values ('bob@example.com')"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		email, password string
+		wantErr         error
+	}{
+		{"ada@example.com", "correct horse", nil},
+		{" ADA@example.com ", "correct horse", nil},
+		{"ada@example.com", "wrong horse", ErrInvalidCredentials},
+		{"bob@example.com", "", ErrInvalidCredentials},
+		{"bob@example.com", "anything", ErrInvalidCredentials},
+		{"cy@example.com", "correct horse", ErrInvalidCredentials},
+	}
+	for _, tt := range tests {
+		got, err := s.SignIn(ctx, tt.email, tt.password)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("SignIn(%q, %q) error = %v, want %v", tt.email, tt.password, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got != id {
+			t.Errorf("SignIn(%q) = %q, want %q", tt.email, got, id)
+		}
+	}
+}
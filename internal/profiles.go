@@ -0,0 +1,125 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"zel/lo/supabase"
+
+	storage_go "github.com/supabase-community/storage-go"
+)
+
+// AvatarBucket is the public storage bucket avatars are uploaded to, one
+// folder per user.
+const AvatarBucket = "avatars"
+
+// Profile is the public face of an account, keyed by its auth user id.
+type Profile struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"display_name"`
+	AvatarURL   string `json:"avatar_url"`
+	CreatedAt   string `json:"created_at,omitempty"`
+	UpdatedAt   string `json:"updated_at,omitempty"`
+}
+
+// ProfileUpdate holds the profile fields to change; nil fields are left
+// alone.
+type ProfileUpdate struct {
+	DisplayName *string
+	AvatarURL   *string
+}
+
+// CreateProfile creates the profile for a new account. The signup trigger
+// usually got there first, so an existing profile is updated instead.
+func CreateProfile(client *supabase.Client, userID, displayName string) (*Profile, error) {
+	var profiles []Profile
+
+	profileData := map[string]interface{}{
+		"id":           userID,
+		"display_name": strings.TrimSpace(displayName),
+	}
+
+	_, err := client.From("profiles").
+		Upsert([]map[string]interface{}{profileData}, "id", "representation", "").
+		ExecuteTo(&profiles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create profile: %w", err)
+	}
+
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("insert succeeded but no profile returned")
+	}
+
+	return &profiles[0], nil
+}
+
+func GetProfile(client *supabase.Client, userID string) (*Profile, error) {
+	var profiles []Profile
+
+	_, err := client.From("profiles").
+		Select("*", "", false).
+		Eq("id", userID).
+		ExecuteTo(&profiles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch profile: %w", err)
+	}
+
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("no profile for user %s", userID)
+	}
+
+	return &profiles[0], nil
+}
+
+func UpdateProfile(client *supabase.Client, userID string, update ProfileUpdate) (*Profile, error) {
+	updateData := map[string]interface{}{}
+	if update.DisplayName != nil {
+		updateData["display_name"] = strings.TrimSpace(*update.DisplayName)
+	}
+	if update.AvatarURL != nil {
+		updateData["avatar_url"] = *update.AvatarURL
+	}
+	if len(updateData) == 0 {
+		return GetProfile(client, userID)
+	}
+
+	var profiles []Profile
+
+	_, err := client.From("profiles").
+		Update(updateData, "representation", "").
+		Eq("id", userID).
+		ExecuteTo(&profiles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update profile: %w", err)
+	}
+
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("no profile for user %s", userID)
+	}
+
+	return &profiles[0], nil
+}
+
+// UploadAvatar stores an image as the user's avatar and points their
+// profile at its public URL. Uploads replace the previous avatar.
+func UploadAvatar(client *supabase.Client, userID string, data []byte) (*Profile, error) {
+	contentType := http.DetectContentType(data)
+	if !strings.HasPrefix(contentType, "image/") {
+		return nil, fmt.Errorf("avatar must be an image, got %s", contentType)
+	}
+
+	path := userID + "/avatar"
+	upsert := true
+	_, err := client.Storage.UploadFile(AvatarBucket, path, bytes.NewReader(data), storage_go.FileOptions{
+		ContentType: &contentType,
+		Upsert:      &upsert,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload avatar: %w", err)
+	}
+
+	url := client.Storage.GetPublicUrl(AvatarBucket, path).SignedURL
+	return UpdateProfile(client, userID, ProfileUpdate{AvatarURL: &url})
+}
@@ -0,0 +1,88 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	storage_go "github.com/supabase-community/storage-go"
+
+	"zel/lo/supabase"
+	"zel/lo/supabase/supabasetest"
+)
+
+// pngImage returns enough of a PNG for content sniffing to call it an
+// image, ending in b to tell uploads apart.
+func pngImage(b byte) []byte {
+	return append([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), b)
+}
+
+// avatarServer returns a fake with the avatars bucket and a profile for
+// ada.
+func avatarServer(t *testing.T) (*supabasetest.Server, *supabase.Client) {
+	t.Helper()
+	srv := supabasetest.NewServer()
+	t.Cleanup(srv.Close)
+	client := srv.Client()
+	if _, err := client.Storage.CreateBucket(AvatarBucket, storage_go.BucketOptions{Public: true}); err != nil {
+		t.Fatal(err)
+	}
+	srv.Seed("profiles", map[string]interface{}{"id": "ada", "display_name": "Ada"})
+	return srv, client
+}
+
+func TestUploadAvatar(t *testing.T) {
+	srv, client := avatarServer(t)
+
+	if _, err := UploadAvatar(client, "ada", []byte("not an image")); err == nil || !strings.Contains(err.Error(), "must be an image") {
+		t.Errorf("uploading text: err = %v", err)
+	}
+	if _, ok := srv.Object(AvatarBucket, "ada/avatar"); ok {
+		t.Error("stored a rejected avatar")
+	}
+
+	first := pngImage(1)
+	p, err := UploadAvatar(client, "ada", first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(p.AvatarURL, "/object/public/avatars/ada/avatar") {
+		t.Errorf("avatar url = %q", p.AvatarURL)
+	}
+
+	// A second upload replaces the first.
+	second := pngImage(2)
+	if _, err := UploadAvatar(client, "ada", second); err != nil {
+		t.Fatal(err)
+	}
+	if data, ok := srv.Object(AvatarBucket, "ada/avatar"); !ok || !bytes.Equal(data, second) {
+		t.Errorf("stored avatar = %q, %v; want the second upload", data, ok)
+	}
+}
+
+func TestDeleteAccountRemovesAvatar(t *testing.T) {
+	for _, uploaded := range []bool{true, false} {
+		srv, client := avatarServer(t)
+		deleted := false
+		srv.HandleRPC("delete_account", func(map[string]interface{}) (interface{}, error) {
+			deleted = true
+			return nil, nil
+		})
+		if uploaded {
+			if _, err := UploadAvatar(client, "ada", pngImage(0)); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if err := DeleteAccount(context.Background(), client, "ada"); err != nil {
+			t.Fatalf("uploaded = %v: %v", uploaded, err)
+		}
+		if !deleted {
+			t.Errorf("uploaded = %v: delete_account was not called", uploaded)
+		}
+		if _, ok := srv.Object(AvatarBucket, "ada/avatar"); ok {
+			t.Error("avatar survived the account")
+		}
+	}
+}
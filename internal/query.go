@@ -0,0 +1,665 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/supabase-community/postgrest-go"
+
+	"zel/lo/supabase"
+)
+
+// A query is a small filter language for issues, e.g.
+//
+//	status:open label:bug assignee:me
+//	(status:open OR status:in_progress) -label:wontfix created:>2026-01-01
+//
+// Terms separated by spaces must all match. OR between terms and
+// parentheses group alternatives, and a leading - negates a term or group.
+// A bare word matches the title or description.
+//
+// Supported fields:
+//
+//	status:<value>               issue status
+//	label:<value>                issue has the label
+//	assignee:<id|me|none>        assigned user
+//	author:<id|me>               user who created the issue
+//	title:<text>                 title contains text
+//	id:<number>                  issue id
+//	created:<date>, updated:<date>
+//
+// Dates are YYYY-MM-DD and may be prefixed with >, >=, < or <=, or given as
+// an inclusive range like 2026-01-01..2026-01-31.
+type Query struct {
+	root queryNode
+}
+
+type queryNode interface {
+	compile(userID string) (string, error)
+	match(is *Issue, userID string) (bool, error)
+	sql(userID string, args *[]interface{}) (string, error)
+}
+
+type (
+	andNode  []queryNode
+	orNode   []queryNode
+	notNode  struct{ node queryNode }
+	termNode struct{ field, value string }
+)
+
+// ParseQuery parses the query language described on Query.
+func ParseQuery(input string) (*Query, error) {
+	tokens, err := tokenizeQuery(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{tokens: tokens}
+	if len(tokens) == 0 {
+		return &Query{}, nil
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in query", p.tokens[p.pos].text)
+	}
+	return &Query{root: root}, nil
+}
+
+// Filter compiles the query into the body of a PostgREST and=(...) logic
+// tree. userID is substituted for the me shortcut. An empty query compiles
+// to an empty string.
+func (q *Query) Filter(userID string) (string, error) {
+	if q == nil || q.root == nil {
+		return "", nil
+	}
+	if and, ok := q.root.(andNode); ok {
+		return and.compileItems(userID)
+	}
+	return q.root.compile(userID)
+}
+
+// Match reports whether an issue satisfies the query, for stores that
+// filter in memory. It agrees with Filter and SQL: terms on the nullable
+// assignee and author columns compile to false rather than SQL null for
+// issues without one, so -assignee:x includes unassigned issues everywhere.
+func (q *Query) Match(is *Issue, userID string) (bool, error) {
+	if q == nil || q.root == nil {
+		return true, nil
+	}
+	return q.root.match(is, userID)
+}
+
+// SQL compiles the query into a Postgres where clause over the issues
+// table, appending its parameters to args so placeholders continue from any
+// already there. An empty query compiles to an empty string.
+func (q *Query) SQL(userID string, args *[]interface{}) (string, error) {
+	if q == nil || q.root == nil {
+		return "", nil
+	}
+	return q.root.sql(userID, args)
+}
+
+// ListIssuesQuery lists the issues matching a query string, ordered by sort
+// (see ParseSort); an empty sort leaves the order to the server.
+func ListIssuesQuery(client *supabase.Client, query, sort, userID string) ([]Issue, error) {
+	q, err := ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := q.Filter(userID)
+	if err != nil {
+		return nil, err
+	}
+	column, ascending, err := ParseSort(sort)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []Issue
+	builder := client.From("issues").Select("*", "", false)
+	if filter != "" {
+		builder = builder.And(filter, "")
+	}
+	if column != "" {
+		builder = builder.Order(column, &postgrest.OrderOpts{Ascending: ascending})
+	}
+	_, err = builder.ExecuteTo(&issues)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch issues: %w", err)
+	}
+
+	return issues, nil
+}
+
+// SortColumns are the issue columns a list can be ordered by.
+var SortColumns = []string{"created_at", "updated_at", "status", "title", "id"}
+
+// ParseSort splits a sort spec such as "created_at.desc" into its column and
+// direction. The direction defaults to ascending.
+func ParseSort(sort string) (column string, ascending bool, err error) {
+	if sort == "" {
+		return "", false, nil
+	}
+	column, dir, _ := strings.Cut(sort, ".")
+	switch dir {
+	case "", "asc":
+		ascending = true
+	case "desc":
+	default:
+		return "", false, fmt.Errorf("invalid sort direction %q, expected asc or desc", dir)
+	}
+	for _, c := range SortColumns {
+		if c == column {
+			return column, ascending, nil
+		}
+	}
+	return "", false, fmt.Errorf("cannot sort issues by %q", column)
+}
+
+func (n andNode) compileItems(userID string) (string, error) {
+	parts := make([]string, 0, len(n))
+	for _, child := range n {
+		s, err := child.compile(userID)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, ","), nil
+}
+
+func (n andNode) compile(userID string) (string, error) {
+	items, err := n.compileItems(userID)
+	if err != nil {
+		return "", err
+	}
+	return "and(" + items + ")", nil
+}
+
+func (n orNode) compile(userID string) (string, error) {
+	items, err := andNode(n).compileItems(userID)
+	if err != nil {
+		return "", err
+	}
+	return "or(" + items + ")", nil
+}
+
+func (n notNode) compile(userID string) (string, error) {
+	s, err := n.node.compile(userID)
+	if err != nil {
+		return "", err
+	}
+	// Logic trees negate as not.and(...)/not.or(...) while single filters
+	// take the form column.not.op.value.
+	if strings.HasPrefix(s, "and(") || strings.HasPrefix(s, "or(") {
+		return "not." + s, nil
+	}
+	column, rest, _ := strings.Cut(s, ".")
+	return column + ".not." + rest, nil
+}
+
+func (t termNode) compile(userID string) (string, error) {
+	switch t.field {
+	case "":
+		pattern := quoteFilterValue("*" + t.value + "*")
+		return fmt.Sprintf("or(title.ilike.%s,description.ilike.%s)", pattern, pattern), nil
+	case "status":
+		return "status.eq." + quoteFilterValue(t.value), nil
+	case "label":
+		return "labels.cs.{" + quoteArrayElement(t.value) + "}", nil
+	case "assignee", "author":
+		column := "assignee_id"
+		if t.field == "author" {
+			column = "user_id"
+		}
+		value := t.value
+		switch value {
+		case "me":
+			if userID == "" {
+				return "", fmt.Errorf("%s:me requires a signed-in user", t.field)
+			}
+			value = userID
+		case "none":
+			return column + ".is.null", nil
+		}
+		// Without the null check the term is null for issues without a
+		// value, and so is its negation, which would leave them out.
+		return fmt.Sprintf("and(%s.not.is.null,%s.eq.%s)", column, column, quoteFilterValue(value)), nil
+	case "title":
+		return "title.ilike." + quoteFilterValue("*"+t.value+"*"), nil
+	case "id":
+		if _, err := strconv.Atoi(t.value); err != nil {
+			return "", fmt.Errorf("id must be a number, got %q", t.value)
+		}
+		return "id.eq." + t.value, nil
+	case "created", "updated":
+		return compileDate(t.field+"_at", t.value)
+	}
+	return "", fmt.Errorf("unknown query field %q", t.field)
+}
+
+// compileDate turns a date term into filters at day granularity, so
+// created:>2026-01-01 starts at midnight on the 2nd.
+func compileDate(column, value string) (string, error) {
+	start, end, err := dateBounds(value)
+	if err != nil {
+		return "", err
+	}
+	const layout = "2006-01-02"
+	switch {
+	case start.IsZero():
+		return column + ".lt." + end.Format(layout), nil
+	case end.IsZero():
+		return column + ".gte." + start.Format(layout), nil
+	}
+	return fmt.Sprintf("and(%s.gte.%s,%s.lt.%s)", column, start.Format(layout), column, end.Format(layout)), nil
+}
+
+// dateBounds returns the half-open interval [start, end) of days a date
+// term covers. A zero bound is open.
+func dateBounds(value string) (start, end time.Time, err error) {
+	parse := func(s string) (time.Time, error) {
+		d, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", s)
+		}
+		return d, nil
+	}
+	next := func(d time.Time) time.Time { return d.AddDate(0, 0, 1) }
+
+	if from, to, ok := strings.Cut(value, ".."); ok {
+		if start, err = parse(from); err != nil {
+			return
+		}
+		if end, err = parse(to); err != nil {
+			return
+		}
+		return start, next(end), nil
+	}
+
+	op := ""
+	for _, prefix := range []string{">=", "<=", ">", "<"} {
+		if strings.HasPrefix(value, prefix) {
+			op = prefix
+			value = strings.TrimPrefix(value, prefix)
+			break
+		}
+	}
+	d, err := parse(value)
+	if err != nil {
+		return
+	}
+	switch op {
+	case ">":
+		return next(d), time.Time{}, nil
+	case ">=":
+		return d, time.Time{}, nil
+	case "<":
+		return time.Time{}, d, nil
+	case "<=":
+		return time.Time{}, next(d), nil
+	}
+	return d, next(d), nil
+}
+
+func (n andNode) match(is *Issue, userID string) (bool, error) {
+	for _, child := range n {
+		ok, err := child.match(is, userID)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (n orNode) match(is *Issue, userID string) (bool, error) {
+	for _, child := range n {
+		ok, err := child.match(is, userID)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+func (n notNode) match(is *Issue, userID string) (bool, error) {
+	ok, err := n.node.match(is, userID)
+	return !ok, err
+}
+
+func (t termNode) match(is *Issue, userID string) (bool, error) {
+	contains := func(s string) bool {
+		return strings.Contains(strings.ToLower(s), strings.ToLower(t.value))
+	}
+	switch t.field {
+	case "":
+		return contains(is.Title) || contains(is.Description), nil
+	case "status":
+		return is.Status == t.value, nil
+	case "label":
+		for _, l := range is.Labels {
+			if l == t.value {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "assignee", "author":
+		actual := is.AssigneeID
+		if t.field == "author" {
+			actual = is.UserID
+		}
+		switch t.value {
+		case "me":
+			if userID == "" {
+				return false, fmt.Errorf("%s:me requires a signed-in user", t.field)
+			}
+			return actual == userID, nil
+		case "none":
+			return actual == "", nil
+		}
+		return actual == t.value, nil
+	case "title":
+		return contains(is.Title), nil
+	case "id":
+		id, err := strconv.Atoi(t.value)
+		if err != nil {
+			return false, fmt.Errorf("id must be a number, got %q", t.value)
+		}
+		return is.ID == id, nil
+	case "created", "updated":
+		start, end, err := dateBounds(t.value)
+		if err != nil {
+			return false, err
+		}
+		stamp := is.CreatedAt
+		if t.field == "updated" {
+			stamp = is.UpdatedAt
+		}
+		at, err := time.Parse(time.RFC3339Nano, stamp)
+		if err != nil {
+			return false, nil
+		}
+		return (start.IsZero() || !at.Before(start)) && (end.IsZero() || at.Before(end)), nil
+	}
+	return false, fmt.Errorf("unknown query field %q", t.field)
+}
+
+func (n andNode) sql(userID string, args *[]interface{}) (string, error) {
+	return joinSQL(n, " and ", userID, args)
+}
+
+func (n orNode) sql(userID string, args *[]interface{}) (string, error) {
+	return joinSQL(n, " or ", userID, args)
+}
+
+func joinSQL(nodes []queryNode, sep, userID string, args *[]interface{}) (string, error) {
+	parts := make([]string, 0, len(nodes))
+	for _, child := range nodes {
+		s, err := child.sql(userID, args)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, s)
+	}
+	return "(" + strings.Join(parts, sep) + ")", nil
+}
+
+func (n notNode) sql(userID string, args *[]interface{}) (string, error) {
+	s, err := n.node.sql(userID, args)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(s, "(") {
+		s = "(" + s + ")"
+	}
+	return "not " + s, nil
+}
+
+func (t termNode) sql(userID string, args *[]interface{}) (string, error) {
+	param := func(v interface{}) string {
+		*args = append(*args, v)
+		return "$" + strconv.Itoa(len(*args))
+	}
+	pattern := func() string {
+		return param("%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(t.value) + "%")
+	}
+	switch t.field {
+	case "":
+		p := pattern()
+		return fmt.Sprintf("(title ilike %s or description ilike %s)", p, p), nil
+	case "status":
+		return "status = " + param(t.value), nil
+	case "label":
+		return param(t.value) + " = any(labels)", nil
+	case "assignee", "author":
+		column := "assignee_id"
+		if t.field == "author" {
+			column = "user_id"
+		}
+		value := t.value
+		switch value {
+		case "me":
+			if userID == "" {
+				return "", fmt.Errorf("%s:me requires a signed-in user", t.field)
+			}
+			value = userID
+		case "none":
+			return column + " is null", nil
+		}
+		// As in compile, the term is false rather than null without a value.
+		return fmt.Sprintf("(%s is not null and %s::text = %s)", column, column, param(value)), nil
+	case "title":
+		return "title ilike " + pattern(), nil
+	case "id":
+		id, err := strconv.Atoi(t.value)
+		if err != nil {
+			return "", fmt.Errorf("id must be a number, got %q", t.value)
+		}
+		return "id = " + param(id), nil
+	case "created", "updated":
+		start, end, err := dateBounds(t.value)
+		if err != nil {
+			return "", err
+		}
+		column := t.field + "_at"
+		switch {
+		case start.IsZero():
+			return column + " < " + param(end), nil
+		case end.IsZero():
+			return column + " >= " + param(start), nil
+		}
+		return fmt.Sprintf("(%s >= %s and %s < %s)", column, param(start), column, param(end)), nil
+	}
+	return "", fmt.Errorf("unknown query field %q", t.field)
+}
+
+// quoteFilterValue double-quotes values containing characters that are
+// reserved inside PostgREST logic trees.
+func quoteFilterValue(v string) string {
+	if !strings.ContainsAny(v, `,.:()" \`) {
+		return v
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(v) + `"`
+}
+
+func quoteArrayElement(v string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(v) + `"`
+}
+
+// Tokens and parser
+
+type queryTokenKind int
+
+const (
+	tokTerm queryTokenKind = iota
+	tokOr
+	tokNot
+	tokOpen
+	tokClose
+)
+
+type queryToken struct {
+	kind  queryTokenKind
+	text  string
+	field string
+}
+
+func tokenizeQuery(input string) ([]queryToken, error) {
+	var tokens []queryToken
+	r := []rune(input)
+	for i := 0; i < len(r); {
+		c := r[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(':
+			tokens = append(tokens, queryToken{kind: tokOpen, text: "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, queryToken{kind: tokClose, text: ")"})
+			i++
+		case c == '-' && (i+1 < len(r) && !unicode.IsSpace(r[i+1])):
+			tokens = append(tokens, queryToken{kind: tokNot, text: "-"})
+			i++
+		default:
+			var field, value strings.Builder
+			quoted := false
+			for i < len(r) {
+				c = r[i]
+				if c == '"' {
+					end := i + 1
+					for end < len(r) && r[end] != '"' {
+						end++
+					}
+					if end == len(r) {
+						return nil, fmt.Errorf("unterminated quote in query")
+					}
+					value.WriteString(string(r[i+1 : end]))
+					quoted = true
+					i = end + 1
+					continue
+				}
+				if unicode.IsSpace(c) || c == '(' || c == ')' {
+					break
+				}
+				if c == ':' && field.Len() == 0 && !quoted {
+					field.WriteString(value.String())
+					value.Reset()
+					if field.Len() == 0 {
+						return nil, fmt.Errorf("missing field name before ':'")
+					}
+					i++
+					continue
+				}
+				value.WriteRune(c)
+				i++
+			}
+			text := value.String()
+			if !quoted && field.Len() == 0 && text == "OR" {
+				tokens = append(tokens, queryToken{kind: tokOr, text: text})
+				continue
+			}
+			if field.Len() > 0 && text == "" {
+				return nil, fmt.Errorf("missing value for %s:", field.String())
+			}
+			tokens = append(tokens, queryToken{
+				kind:  tokTerm,
+				text:  text,
+				field: strings.ToLower(field.String()),
+			})
+		}
+	}
+	return tokens, nil
+}
+
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) peek() (queryToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return queryToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *queryParser) parseOr() (queryNode, error) {
+	var alternatives orNode
+	for {
+		node, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		alternatives = append(alternatives, node)
+		if t, ok := p.peek(); !ok || t.kind != tokOr {
+			break
+		}
+		p.pos++
+	}
+	if len(alternatives) == 1 {
+		return alternatives[0], nil
+	}
+	return alternatives, nil
+}
+
+func (p *queryParser) parseAnd() (queryNode, error) {
+	var terms andNode
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind == tokOr || t.kind == tokClose {
+			break
+		}
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, node)
+	}
+	switch len(terms) {
+	case 0:
+		if t, ok := p.peek(); ok {
+			return nil, fmt.Errorf("unexpected %q in query", t.text)
+		}
+		return nil, fmt.Errorf("unexpected end of query")
+	case 1:
+		return terms[0], nil
+	}
+	return terms, nil
+}
+
+func (p *queryParser) parseUnary() (queryNode, error) {
+	t, _ := p.peek()
+	switch t.kind {
+	case tokNot:
+		p.pos++
+		if _, ok := p.peek(); !ok {
+			return nil, fmt.Errorf("nothing to negate at end of query")
+		}
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{node}, nil
+	case tokOpen:
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t, ok := p.peek(); !ok || t.kind != tokClose {
+			return nil, fmt.Errorf("missing closing parenthesis in query")
+		}
+		p.pos++
+		return node, nil
+	case tokTerm:
+		p.pos++
+		return termNode{field: t.field, value: t.text}, nil
+	}
+	return nil, fmt.Errorf("unexpected %q in query", t.text)
+}
@@ -0,0 +1,106 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"status:", "missing value for status:"},
+		{":open", "missing field name"},
+		{`title:"unterminated`, "unterminated quote"},
+		{"(status:open", "missing closing parenthesis"},
+		{"status:open)", `unexpected ")"`},
+		{"status:open OR", "unexpected end of query"},
+		{"OR status:open", `unexpected "OR"`},
+	}
+	for _, tt := range tests {
+		if _, err := ParseQuery(tt.query); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseQuery(%q) error = %v, want %q", tt.query, err, tt.want)
+		}
+	}
+}
+
+func TestQueryFilterAndSQL(t *testing.T) {
+	tests := []struct {
+		query  string
+		filter string
+		sql    string
+	}{
+		{"", "", ""},
+		{"status:open label:bug", "status.eq.open,labels.cs.{\"bug\"}", "(status = $1 and $2 = any(labels))"},
+		{"assignee:me", "and(assignee_id.not.is.null,assignee_id.eq.u1)", "(assignee_id is not null and assignee_id::text = $1)"},
+		{"-assignee:x", "not.and(assignee_id.not.is.null,assignee_id.eq.x)", "not (assignee_id is not null and assignee_id::text = $1)"},
+		{"-author:me", "not.and(user_id.not.is.null,user_id.eq.u1)", "not (user_id is not null and user_id::text = $1)"},
+		{"assignee:none", "assignee_id.is.null", "assignee_id is null"},
+		{"-status:closed", "status.not.eq.closed", "not (status = $1)"},
+		{"status:open OR -label:bug", "or(status.eq.open,labels.not.cs.{\"bug\"})", "(status = $1 or not ($2 = any(labels)))"},
+	}
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.query)
+		if err != nil {
+			t.Fatalf("ParseQuery(%q): %v", tt.query, err)
+		}
+		if got, err := q.Filter("u1"); err != nil || got != tt.filter {
+			t.Errorf("Filter(%q) = %q, %v; want %q", tt.query, got, err, tt.filter)
+		}
+		var args []interface{}
+		if got, err := q.SQL("u1", &args); err != nil || got != tt.sql {
+			t.Errorf("SQL(%q) = %q, %v; want %q", tt.query, got, err, tt.sql)
+		}
+	}
+}
+
+func TestQueryMatch(t *testing.T) {
+	unassigned := Issue{ID: 1, Title: "Login fails", Status: "open", Labels: []string{"bug"}, CreatedAt: "2026-01-05T10:00:00Z"}
+	assigned := Issue{ID: 2, Title: "Dark mode", Status: "closed", UserID: "u1", AssigneeID: "x", CreatedAt: "2026-02-10T10:00:00Z"}
+	undated := Issue{ID: 3, Title: "Imported", Status: "open"}
+	issues := []Issue{unassigned, assigned, undated}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", "1,2,3"},
+		{"assignee:x", "2"},
+		// Issues without an assignee or author match the negation, as
+		// they do through Filter and SQL.
+		{"-assignee:x", "1,3"},
+		{"-author:me", "1,3"},
+		{"-(assignee:x OR label:bug)", "3"},
+		{"assignee:none", "1,3"},
+		{"login OR status:closed", "1,2"},
+		{"created:>=2026-02-01", "2"},
+		{"-created:>=2026-02-01", "1,3"},
+		{"id:3", "3"},
+	}
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.query)
+		if err != nil {
+			t.Fatalf("ParseQuery(%q): %v", tt.query, err)
+		}
+		var got []string
+		for i := range issues {
+			ok, err := q.Match(&issues[i], "u1")
+			if err != nil {
+				t.Fatalf("Match(%q): %v", tt.query, err)
+			}
+			if ok {
+				got = append(got, fmt.Sprint(issues[i].ID))
+			}
+		}
+		if strings.Join(got, ",") != tt.want {
+			t.Errorf("Match(%q) = %v, want %s", tt.query, got, tt.want)
+		}
+	}
+
+	q, _ := ParseQuery("assignee:me")
+	if _, err := q.Match(&unassigned, ""); err == nil {
+		t.Error("assignee:me matched without a user")
+	}
+}
@@ -0,0 +1,109 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"zel/lo/supabase"
+)
+
+// searchLimit bounds how many issues a search returns.
+const searchLimit = 50
+
+type SearchResult struct {
+	Issue Issue `json:"issue"`
+	// Rank orders results; matches in the title weigh more than matches in
+	// the description, which weigh more than matches in comments.
+	Rank float64 `json:"rank"`
+	// Snippet is the matching comment body when the issue only matched
+	// through one of its comments.
+	Snippet string `json:"snippet"`
+}
+
+// SearchIssues runs a full-text search over issue titles, descriptions and
+// comments and returns the best matching issues ranked best first. The
+// search_issues function matches and ranks them in Postgres with ts_rank.
+func SearchIssues(ctx context.Context, client *supabase.Client, query string) ([]SearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, nil
+	}
+	params := map[string]interface{}{"p_query": query, "p_limit": searchLimit}
+	results, _, err := supabase.Rpc[[]SearchResult](ctx, client, "search_issues", params, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search issues: %w", err)
+	}
+	return results, nil
+}
+
+// Weights of title, description and comment matches in searchLocal, the
+// defaults ts_rank gives the A, B and C weights search_issues uses.
+const (
+	titleWeight       = 1.0
+	descriptionWeight = 0.4
+	commentWeight     = 0.2
+)
+
+// searchLocal serves SearchIssues for stores without full-text search. An
+// issue matches when every word of the query appears in its title and
+// description together, or in one of its comments. Words are matched
+// case-insensitively as substrings rather than stemmed.
+func searchLocal(query string, issues []Issue, comments []Comment) []SearchResult {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return nil
+	}
+	containsAll := func(text string) bool {
+		text = strings.ToLower(text)
+		for _, w := range words {
+			if !strings.Contains(text, w) {
+				return false
+			}
+		}
+		return true
+	}
+
+	commentRank := map[int]float64{}
+	snippet := map[int]string{}
+	for _, c := range comments {
+		if containsAll(c.Body) {
+			commentRank[c.IssueID] += commentWeight
+			if _, ok := snippet[c.IssueID]; !ok {
+				snippet[c.IssueID] = c.Body
+			}
+		}
+	}
+
+	var results []SearchResult
+	for _, is := range issues {
+		r := SearchResult{Issue: is, Rank: commentRank[is.ID]}
+		if containsAll(is.Title + " " + is.Description) {
+			title, desc := strings.ToLower(is.Title), strings.ToLower(is.Description)
+			for _, w := range words {
+				if strings.Contains(title, w) {
+					r.Rank += titleWeight
+				}
+				if strings.Contains(desc, w) {
+					r.Rank += descriptionWeight
+				}
+			}
+		} else if r.Rank > 0 {
+			r.Snippet = snippet[is.ID]
+		} else {
+			continue
+		}
+		results = append(results, r)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].Issue.ID > results[j].Issue.ID
+	})
+	if len(results) > searchLimit {
+		results = results[:searchLimit]
+	}
+	return results
+}
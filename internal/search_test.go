@@ -0,0 +1,59 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"zel/lo/supabase/supabasetest"
+)
+
+func TestSearchIssues(t *testing.T) {
+	srv := supabasetest.NewServer()
+	defer srv.Close()
+
+	var calls []map[string]interface{}
+	srv.HandleRPC("search_issues", func(p map[string]interface{}) (interface{}, error) {
+		calls = append(calls, p)
+		if p["p_query"] == "(" {
+			return nil, errors.New("syntax error in tsquery")
+		}
+		return []map[string]interface{}{
+			{"issue": map[string]interface{}{"id": 3, "title": "Login fails"}, "rank": 0.6, "snippet": nil},
+			{"issue": map[string]interface{}{"id": 9, "title": "Session bug"}, "rank": 0.1, "snippet": "login loops forever"},
+		}, nil
+	})
+
+	tests := []struct {
+		query   string
+		want    string
+		calls   int
+		wantErr bool
+	}{
+		{"  login  ", "3:0.6:,9:0.1:login loops forever", 1, false},
+		{"   ", "", 0, false},
+		{"(", "", 1, true},
+	}
+	for _, tt := range tests {
+		calls = nil
+		results, err := SearchIssues(context.Background(), srv.Client(), tt.query)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("SearchIssues(%q) error = %v", tt.query, err)
+		}
+		var got []string
+		for _, r := range results {
+			got = append(got, strings.Join([]string{strconv.Itoa(r.Issue.ID), strconv.FormatFloat(r.Rank, 'f', -1, 64), r.Snippet}, ":"))
+		}
+		if strings.Join(got, ",") != tt.want {
+			t.Errorf("SearchIssues(%q) = %v, want %s", tt.query, got, tt.want)
+		}
+		if len(calls) != tt.calls {
+			t.Fatalf("SearchIssues(%q) made %d calls, want %d", tt.query, len(calls), tt.calls)
+		}
+		if tt.calls > 0 && (calls[0]["p_query"] != strings.TrimSpace(tt.query) || calls[0]["p_limit"] != float64(searchLimit)) {
+			t.Errorf("SearchIssues(%q) params = %v", tt.query, calls[0])
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/supabase-community/postgrest-go"
+
+	"zel/lo/supabase"
+)
+
+// dateLayout is the format of sprint start and end dates.
+const dateLayout = "2006-01-02"
+
+type Sprint struct {
+	ID        int    `json:"id,omitempty"`
+	BoardID   int    `json:"board_id"`
+	Name      string `json:"name"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	Goal      string `json:"goal"`
+}
+
+// ListSprints returns a board's sprints ordered by start date.
+func ListSprints(client *supabase.Client, boardID int) ([]Sprint, error) {
+	var sprints []Sprint
+
+	_, err := client.From("sprints").
+		Select("*", "", false).
+		Eq("board_id", strconv.Itoa(boardID)).
+		Order("start_date", &postgrest.OrderOpts{Ascending: true}).
+		ExecuteTo(&sprints)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sprints: %w", err)
+	}
+
+	return sprints, nil
+}
+
+func CreateSprint(client *supabase.Client, sprint Sprint) (*Sprint, error) {
+	if sprint.Name == "" {
+		return nil, fmt.Errorf("sprint name required")
+	}
+	start, err := time.Parse(dateLayout, sprint.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date %q, expected YYYY-MM-DD", sprint.StartDate)
+	}
+	end, err := time.Parse(dateLayout, sprint.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end date %q, expected YYYY-MM-DD", sprint.EndDate)
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("sprint ends before it starts")
+	}
+
+	var sprints []Sprint
+
+	sprintData := map[string]interface{}{
+		"board_id":   sprint.BoardID,
+		"name":       sprint.Name,
+		"start_date": sprint.StartDate,
+		"end_date":   sprint.EndDate,
+		"goal":       sprint.Goal,
+	}
+
+	_, err = client.From("sprints").
+		Insert([]map[string]interface{}{sprintData}, false, "", "representation", "").
+		ExecuteTo(&sprints)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sprint: %w", err)
+	}
+
+	if len(sprints) == 0 {
+		return nil, fmt.Errorf("insert succeeded but no sprint returned")
+	}
+
+	return &sprints[0], nil
+}
+
+// ListSprintIssues returns the issues planned into a sprint.
+func ListSprintIssues(client *supabase.Client, sprintID int) ([]Issue, error) {
+	var issues []Issue
+
+	_, err := client.From("issues").
+		Select("*", "", false).
+		Eq("sprint_id", strconv.Itoa(sprintID)).
+		ExecuteTo(&issues)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sprint issues: %w", err)
+	}
+
+	return issues, nil
+}
+
+// ListBacklog returns a board's open issues that are not in any sprint.
+func ListBacklog(client *supabase.Client, boardID int) ([]Issue, error) {
+	var issues []Issue
+
+	_, err := client.From("issues").
+		Select("*", "", false).
+		Eq("board_id", strconv.Itoa(boardID)).
+		Is("sprint_id", "null").
+		Not("status", "in", "(closed,done)").
+		ExecuteTo(&issues)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch backlog: %w", err)
+	}
+
+	return issues, nil
+}
+
+// MoveIssueToSprint plans an issue into a sprint, or returns it to the
+// backlog when sprintID is nil.
+func MoveIssueToSprint(client *supabase.Client, issueID int, sprintID *int) error {
+	var issues []Issue
+	_, err := client.From("issues").
+		Update(map[string]interface{}{"sprint_id": sprintID}, "representation", "").
+		Eq("id", strconv.Itoa(issueID)).
+		ExecuteTo(&issues)
+	if err != nil {
+		return fmt.Errorf("failed to move issue: %w", err)
+	}
+	if len(issues) == 0 {
+		return fmt.Errorf("issue #%d not found", issueID)
+	}
+	return nil
+}
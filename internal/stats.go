@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"zel/lo/supabase"
+)
+
+// StatusCount is one row of issue_status_counts.
+type StatusCount struct {
+	Status string `json:"status"`
+	Count  int    `json:"count"`
+}
+
+// IssueStatusCounts counts issues per status on a board, or across every
+// board when boardID is 0. The counting runs in Postgres so the issues
+// themselves are never fetched. Rows are ordered by count, largest first.
+func IssueStatusCounts(ctx context.Context, client *supabase.Client, boardID int) ([]StatusCount, error) {
+	params := map[string]interface{}{"p_board_id": nil}
+	if boardID != 0 {
+		params["p_board_id"] = boardID
+	}
+	counts, _, err := supabase.Rpc[[]StatusCount](ctx, client, "issue_status_counts", params, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count issues: %w", err)
+	}
+	sort.SliceStable(counts, func(i, j int) bool { return counts[i].Count > counts[j].Count })
+	return counts, nil
+}
+
+type worklogTotal struct {
+	IssueID int    `json:"issue_id"`
+	UserID  string `json:"user_id"`
+	Minutes int    `json:"minutes"`
+}
+
+// WorklogTotals sums logged time per issue and per user with the
+// worklog_totals function, for one issue or every issue when issueID is 0.
+func WorklogTotals(ctx context.Context, client *supabase.Client, issueID int) (TimeTotals, error) {
+	totals := TimeTotals{ByIssue: map[int]time.Duration{}, ByUser: map[string]time.Duration{}}
+
+	params := map[string]interface{}{"p_issue_id": nil}
+	if issueID != 0 {
+		params["p_issue_id"] = issueID
+	}
+	rows, _, err := supabase.Rpc[[]worklogTotal](ctx, client, "worklog_totals", params, nil)
+	if err != nil {
+		return totals, fmt.Errorf("failed to total worklogs: %w", err)
+	}
+	for _, r := range rows {
+		d := time.Duration(r.Minutes) * time.Minute
+		totals.Total += d
+		totals.ByIssue[r.IssueID] += d
+		totals.ByUser[r.UserID] += d
+	}
+	return totals, nil
+}
@@ -0,0 +1,154 @@
+package internal
+
+import (
+	"context"
+
+	"zel/lo/supabase"
+)
+
+// IssueStore is where issues live. The TUI works against it so it can run
+// on Supabase, another database or plain memory.
+type IssueStore interface {
+	// ListIssues returns every issue in the store's natural order.
+	ListIssues(ctx context.Context) ([]Issue, error)
+	// GetIssue returns one issue or an error if it does not exist.
+	GetIssue(ctx context.Context, id int) (*Issue, error)
+	// CreateIssue adds an issue owned by userID. Issues on a board are
+	// numbered on it.
+	CreateIssue(ctx context.Context, req CreateIssueRequest, userID string) (*Issue, error)
+	// UpdateIssue changes an issue's title and description.
+	UpdateIssue(ctx context.Context, id int, title, description string) (*Issue, error)
+	// DeleteIssue removes an issue.
+	DeleteIssue(ctx context.Context, id int) error
+	// QueryIssues lists the issues matching a query string (see Query),
+	// ordered by sort (see ParseSort).
+	QueryIssues(ctx context.Context, query, sort, userID string) ([]Issue, error)
+	// ListChildIssues returns the direct children of an issue.
+	ListChildIssues(ctx context.Context, parentID int) ([]Issue, error)
+	// SetLabels replaces an issue's labels.
+	SetLabels(ctx context.Context, id int, labels []string) (*Issue, error)
+	// SetParent moves an issue under parentID, or makes it top-level when
+	// parentID is nil. Moves that would create a cycle fail.
+	SetParent(ctx context.Context, id int, parentID *int) (*Issue, error)
+	// SetEstimate sets an issue's estimate in story points; nil clears it.
+	SetEstimate(ctx context.Context, id int, points *float64) (*Issue, error)
+	// SetAssignee assigns an issue to a user; an empty assigneeID
+	// unassigns it.
+	SetAssignee(ctx context.Context, id int, assigneeID string) (*Issue, error)
+	// TransitionIssue changes an issue's status and adds req.Comment by
+	// userID. Closing an issue whose blockers are open fails with a
+	// *BlockedError unless req.Force is set.
+	TransitionIssue(ctx context.Context, req TransitionRequest, userID string) (*TransitionResult, error)
+	// ListLinks returns the links on either side of an issue.
+	ListLinks(ctx context.Context, issueID int) ([]IssueLink, error)
+	// LinkIssues records that source blocks, duplicates or relates to
+	// target.
+	LinkIssues(ctx context.Context, sourceID, targetID int, kind LinkKind) (*IssueLink, error)
+	// UnlinkIssues removes every link between two issues.
+	UnlinkIssues(ctx context.Context, issueID, otherID int) error
+	// SearchIssues returns the issues matching a full-text query, best
+	// first.
+	SearchIssues(ctx context.Context, query string) ([]SearchResult, error)
+	// ListComments returns an issue's comments, oldest first.
+	ListComments(ctx context.Context, issueID int) ([]Comment, error)
+	// CreateComment adds a comment by userID to an issue.
+	CreateComment(ctx context.Context, issueID int, userID, body string) (*Comment, error)
+	// CurrentBoard returns the board with the given key, or the default
+	// board when key is empty. Stores without boards return nil.
+	CurrentBoard(ctx context.Context, key string) (*Board, error)
+}
+
+// SupabaseStore keeps issues in the Supabase issues table.
+type SupabaseStore struct {
+	Client *supabase.Client
+}
+
+func NewSupabaseStore(client *supabase.Client) *SupabaseStore {
+	return &SupabaseStore{Client: client}
+}
+
+func (s *SupabaseStore) ListIssues(ctx context.Context) ([]Issue, error) {
+	return ListIssues(s.Client)
+}
+
+func (s *SupabaseStore) GetIssue(ctx context.Context, id int) (*Issue, error) {
+	return GetIssue(s.Client, id)
+}
+
+// CreateIssue goes through the create-issue edge function for board issues
+// so they get a number, and inserts directly otherwise.
+func (s *SupabaseStore) CreateIssue(ctx context.Context, req CreateIssueRequest, userID string) (*Issue, error) {
+	if req.BoardID != nil {
+		return CreateBoardIssue(ctx, s.Client, req)
+	}
+	return CreateIssue(s.Client, req, userID)
+}
+
+func (s *SupabaseStore) UpdateIssue(ctx context.Context, id int, title, description string) (*Issue, error) {
+	return UpdateIssue(s.Client, id, title, description)
+}
+
+func (s *SupabaseStore) DeleteIssue(ctx context.Context, id int) error {
+	return DeleteIssue(s.Client, id)
+}
+
+func (s *SupabaseStore) ListChildIssues(ctx context.Context, parentID int) ([]Issue, error) {
+	return ListChildIssues(s.Client, parentID)
+}
+
+func (s *SupabaseStore) SetLabels(ctx context.Context, id int, labels []string) (*Issue, error) {
+	return SetIssueLabels(s.Client, id, labels)
+}
+
+func (s *SupabaseStore) SetParent(ctx context.Context, id int, parentID *int) (*Issue, error) {
+	return SetIssueParent(s.Client, id, parentID)
+}
+
+func (s *SupabaseStore) SetEstimate(ctx context.Context, id int, points *float64) (*Issue, error) {
+	return SetIssueEstimate(s.Client, id, points)
+}
+
+func (s *SupabaseStore) SetAssignee(ctx context.Context, id int, assigneeID string) (*Issue, error) {
+	return SetIssueAssignee(s.Client, id, assigneeID)
+}
+
+// TransitionIssue goes through the transition-issue edge function, which
+// records the comment as the signed-in user.
+func (s *SupabaseStore) TransitionIssue(ctx context.Context, req TransitionRequest, userID string) (*TransitionResult, error) {
+	return TransitionIssue(ctx, s.Client, req)
+}
+
+func (s *SupabaseStore) ListLinks(ctx context.Context, issueID int) ([]IssueLink, error) {
+	return ListIssueLinks(s.Client, issueID)
+}
+
+func (s *SupabaseStore) LinkIssues(ctx context.Context, sourceID, targetID int, kind LinkKind) (*IssueLink, error) {
+	return LinkIssues(s.Client, sourceID, targetID, kind)
+}
+
+func (s *SupabaseStore) UnlinkIssues(ctx context.Context, issueID, otherID int) error {
+	return UnlinkIssues(s.Client, issueID, otherID)
+}
+
+func (s *SupabaseStore) SearchIssues(ctx context.Context, query string) ([]SearchResult, error) {
+	return SearchIssues(ctx, s.Client, query)
+}
+
+func (s *SupabaseStore) ListComments(ctx context.Context, issueID int) ([]Comment, error) {
+	return ListComments(s.Client, issueID)
+}
+
+func (s *SupabaseStore) CreateComment(ctx context.Context, issueID int, userID, body string) (*Comment, error) {
+	return CreateComment(s.Client, issueID, userID, body)
+}
+
+func (s *SupabaseStore) CurrentBoard(ctx context.Context, key string) (*Board, error) {
+	return CurrentBoard(s.Client, key)
+}
+
+func (s *SupabaseStore) QueryIssues(ctx context.Context, query, sort, userID string) ([]Issue, error) {
+	if query == "" && sort == "" {
+		return ListIssues(s.Client)
+	}
+	return ListIssuesQuery(s.Client, query, sort, userID)
+}
@@ -0,0 +1,433 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps issues in memory. It is meant for demos and for driving
+// the TUI without a database; nothing is persisted.
+type MemoryStore struct {
+	mu       sync.Mutex
+	issues   []Issue
+	nextID   int
+	comments []Comment
+	links    []IssueLink
+	now      func() time.Time
+}
+
+// NewMemoryStore returns a store holding copies of issues. Issues without an
+// id are given one.
+func NewMemoryStore(issues ...Issue) *MemoryStore {
+	s := &MemoryStore{now: time.Now}
+	for _, is := range issues {
+		if is.ID > s.nextID {
+			s.nextID = is.ID
+		}
+	}
+	for _, is := range issues {
+		if is.ID == 0 {
+			s.nextID++
+			is.ID = s.nextID
+		}
+		s.issues = append(s.issues, copyIssue(is))
+	}
+	return s
+}
+
+func (s *MemoryStore) ListIssues(ctx context.Context) ([]Issue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Issue, len(s.issues))
+	for i, is := range s.issues {
+		out[i] = copyIssue(is)
+	}
+	return out, nil
+}
+
+func (s *MemoryStore) ListChildIssues(ctx context.Context, parentID int) ([]Issue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Issue
+	for _, is := range s.issues {
+		if is.ParentID != nil && *is.ParentID == parentID {
+			out = append(out, copyIssue(is))
+		}
+	}
+	return out, nil
+}
+
+func (s *MemoryStore) GetIssue(ctx context.Context, id int) (*Issue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.index(id)
+	if i < 0 {
+		return nil, fmt.Errorf("issue #%d not found", id)
+	}
+	is := copyIssue(s.issues[i])
+	return &is, nil
+}
+
+func (s *MemoryStore) CreateIssue(ctx context.Context, req CreateIssueRequest, userID string) (*Issue, error) {
+	if strings.TrimSpace(req.Title) == "" {
+		return nil, fmt.Errorf("title required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now().UTC().Format(time.RFC3339Nano)
+	s.nextID++
+	is := Issue{
+		ID:          s.nextID,
+		Title:       req.Title,
+		Description: req.Description,
+		Status:      req.Status,
+		UserID:      userID,
+		Labels:      append([]string(nil), req.Labels...),
+		ParentID:    req.ParentID,
+		BoardID:     req.BoardID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if is.Status == "" {
+		is.Status = "open"
+	}
+	if is.BoardID != nil {
+		for _, other := range s.issues {
+			if other.BoardID != nil && *other.BoardID == *is.BoardID && other.Number > is.Number {
+				is.Number = other.Number
+			}
+		}
+		is.Number++
+	}
+	s.issues = append(s.issues, is)
+	is = copyIssue(is)
+	return &is, nil
+}
+
+func (s *MemoryStore) UpdateIssue(ctx context.Context, id int, title, description string) (*Issue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.index(id)
+	if i < 0 {
+		return nil, fmt.Errorf("issue #%d not found", id)
+	}
+	s.issues[i].Title = title
+	s.issues[i].Description = description
+	s.issues[i].UpdatedAt = s.now().UTC().Format(time.RFC3339Nano)
+	is := copyIssue(s.issues[i])
+	return &is, nil
+}
+
+func (s *MemoryStore) SetLabels(ctx context.Context, id int, labels []string) (*Issue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.index(id)
+	if i < 0 {
+		return nil, fmt.Errorf("issue #%d not found", id)
+	}
+	s.issues[i].Labels = append([]string(nil), labels...)
+	s.issues[i].UpdatedAt = s.now().UTC().Format(time.RFC3339Nano)
+	is := copyIssue(s.issues[i])
+	return &is, nil
+}
+
+func (s *MemoryStore) DeleteIssue(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := s.index(id); i >= 0 {
+		s.issues = append(s.issues[:i], s.issues[i+1:]...)
+	}
+	// Links go with the issue, like the foreign keys cascade in a database.
+	s.links = slices.DeleteFunc(s.links, func(l IssueLink) bool {
+		return l.SourceID == id || l.TargetID == id
+	})
+	return nil
+}
+
+func (s *MemoryStore) SetParent(ctx context.Context, id int, parentID *int) (*Issue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if parentID != nil {
+		if err := checkParentCycle(id, *parentID, s.get); err != nil {
+			return nil, err
+		}
+		p := *parentID
+		parentID = &p
+	}
+	return s.update(id, func(is *Issue) { is.ParentID = parentID })
+}
+
+func (s *MemoryStore) SetEstimate(ctx context.Context, id int, points *float64) (*Issue, error) {
+	if err := checkEstimate(points); err != nil {
+		return nil, err
+	}
+	if points != nil {
+		p := *points
+		points = &p
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.update(id, func(is *Issue) { is.Estimate = points })
+}
+
+func (s *MemoryStore) SetAssignee(ctx context.Context, id int, assigneeID string) (*Issue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.update(id, func(is *Issue) { is.AssigneeID = assigneeID })
+}
+
+func (s *MemoryStore) TransitionIssue(ctx context.Context, req TransitionRequest, userID string) (*TransitionResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.index(req.IssueID) < 0 {
+		return nil, fmt.Errorf("issue #%d not found", req.IssueID)
+	}
+
+	closing := isClosedStatus(req.Status)
+	if closing && !req.Force {
+		var blockers []int
+		for _, l := range s.links {
+			if l.Kind != LinkBlocks || l.TargetID != req.IssueID {
+				continue
+			}
+			if b, err := s.get(l.SourceID); err == nil && !b.Closed() {
+				blockers = append(blockers, b.ID)
+			}
+		}
+		if len(blockers) > 0 {
+			sort.Ints(blockers)
+			return nil, &BlockedError{IssueID: req.IssueID, Blockers: blockers}
+		}
+	}
+
+	is, err := s.update(req.IssueID, func(is *Issue) { is.Status = req.Status })
+	if err != nil {
+		return nil, err
+	}
+	result := &TransitionResult{Issue: *is}
+	if req.Comment != "" {
+		c := s.addComment(req.IssueID, userID, req.Comment)
+		result.Comment = &c
+	}
+	if closing && is.ParentID != nil {
+		var siblings []Issue
+		for _, other := range s.issues {
+			if other.ParentID != nil && *other.ParentID == *is.ParentID {
+				siblings = append(siblings, other)
+			}
+		}
+		if done, total := ChildProgress(siblings); done == total {
+			parentID := *is.ParentID
+			result.CompletedParent = &parentID
+		}
+	}
+	return result, nil
+}
+
+func (s *MemoryStore) ListLinks(ctx context.Context, issueID int) ([]IssueLink, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []IssueLink
+	for _, l := range s.links {
+		if l.SourceID == issueID || l.TargetID == issueID {
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+func (s *MemoryStore) LinkIssues(ctx context.Context, sourceID, targetID int, kind LinkKind) (*IssueLink, error) {
+	if err := checkLink(sourceID, targetID, kind); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range []int{sourceID, targetID} {
+		if s.index(id) < 0 {
+			return nil, fmt.Errorf("issue #%d not found", id)
+		}
+	}
+	for _, l := range s.links {
+		if l.SourceID == sourceID && l.TargetID == targetID && l.Kind == kind {
+			return &l, nil
+		}
+	}
+	l := IssueLink{
+		SourceID:  sourceID,
+		TargetID:  targetID,
+		Kind:      kind,
+		CreatedAt: s.now().UTC().Format(time.RFC3339Nano),
+	}
+	for _, other := range s.links {
+		if other.ID > l.ID {
+			l.ID = other.ID
+		}
+	}
+	l.ID++
+	s.links = append(s.links, l)
+	return &l, nil
+}
+
+func (s *MemoryStore) UnlinkIssues(ctx context.Context, issueID, otherID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.links = slices.DeleteFunc(s.links, func(l IssueLink) bool {
+		return l.SourceID == issueID && l.TargetID == otherID || l.SourceID == otherID && l.TargetID == issueID
+	})
+	return nil
+}
+
+func (s *MemoryStore) SearchIssues(ctx context.Context, query string) ([]SearchResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	issues := make([]Issue, len(s.issues))
+	for i, is := range s.issues {
+		issues[i] = copyIssue(is)
+	}
+	return searchLocal(query, issues, s.comments), nil
+}
+
+func (s *MemoryStore) ListComments(ctx context.Context, issueID int) ([]Comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Comment
+	for _, c := range s.comments {
+		if c.IssueID == issueID {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func (s *MemoryStore) CreateComment(ctx context.Context, issueID int, userID, body string) (*Comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.index(issueID) < 0 {
+		return nil, fmt.Errorf("issue #%d not found", issueID)
+	}
+	c := s.addComment(issueID, userID, body)
+	return &c, nil
+}
+
+// addComment appends a comment; the caller holds s.mu.
+func (s *MemoryStore) addComment(issueID int, userID, body string) Comment {
+	c := Comment{
+		ID:        len(s.comments) + 1,
+		IssueID:   issueID,
+		UserID:    userID,
+		Body:      body,
+		CreatedAt: s.now().UTC().Format(time.RFC3339Nano),
+	}
+	s.comments = append(s.comments, c)
+	return c
+}
+
+// CurrentBoard returns nil: issues in memory are not on a board unless
+// they were created with one.
+func (s *MemoryStore) CurrentBoard(ctx context.Context, key string) (*Board, error) {
+	return nil, nil
+}
+
+func (s *MemoryStore) QueryIssues(ctx context.Context, query, sortSpec, userID string) ([]Issue, error) {
+	q, err := ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	column, ascending, err := ParseSort(sortSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	var issues []Issue
+	for _, is := range s.issues {
+		ok, err := q.Match(&is, userID)
+		if err != nil {
+			s.mu.Unlock()
+			return nil, err
+		}
+		if ok {
+			issues = append(issues, copyIssue(is))
+		}
+	}
+	s.mu.Unlock()
+
+	if column != "" {
+		sortIssues(issues, column, ascending)
+	}
+	return issues, nil
+}
+
+func (s *MemoryStore) index(id int) int {
+	for i, is := range s.issues {
+		if is.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// get returns a copy of the issue with id; the caller holds s.mu.
+func (s *MemoryStore) get(id int) (*Issue, error) {
+	i := s.index(id)
+	if i < 0 {
+		return nil, fmt.Errorf("issue #%d not found", id)
+	}
+	is := copyIssue(s.issues[i])
+	return &is, nil
+}
+
+// update applies change to the issue with id and returns a copy of the
+// result; the caller holds s.mu.
+func (s *MemoryStore) update(id int, change func(is *Issue)) (*Issue, error) {
+	i := s.index(id)
+	if i < 0 {
+		return nil, fmt.Errorf("issue #%d not found", id)
+	}
+	change(&s.issues[i])
+	s.issues[i].UpdatedAt = s.now().UTC().Format(time.RFC3339Nano)
+	is := copyIssue(s.issues[i])
+	return &is, nil
+}
+
+// sortIssues orders issues by one of SortColumns. Like the Supabase store,
+// issues without a value sort last in either direction.
+func sortIssues(issues []Issue, column string, ascending bool) {
+	value := func(is Issue) string {
+		switch column {
+		case "created_at":
+			return is.CreatedAt
+		case "updated_at":
+			return is.UpdatedAt
+		case "status":
+			return is.Status
+		case "title":
+			return is.Title
+		}
+		return ""
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		if column == "id" {
+			return (issues[i].ID < issues[j].ID) == ascending
+		}
+		a, b := value(issues[i]), value(issues[j])
+		if a == "" || b == "" {
+			return a != "" && b == ""
+		}
+		if a == b {
+			return false
+		}
+		return (a < b) == ascending
+	})
+}
+
+func copyIssue(is Issue) Issue {
+	is.Labels = append([]string(nil), is.Labels...)
+	return is
+}
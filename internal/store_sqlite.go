@@ -0,0 +1,709 @@
+package internal
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// LocalUserID owns everything in a local database, which has a single user
+// and no sign-in.
+const LocalUserID = "local"
+
+// localBoardKey is the key of the board local issues are created on.
+const localBoardKey = "LOCAL"
+
+const sqliteSchema = `
+create table if not exists meta (
+  key text primary key,
+  value text not null
+);
+create table if not exists boards (
+  id integer primary key,
+  key text not null unique,
+  name text not null,
+  next_number integer not null default 1
+);
+create table if not exists issues (
+  id integer primary key,
+  title text not null,
+  description text not null default '',
+  status text not null default 'open',
+  user_id text not null,
+  parent_id integer references issues (id) on delete set null,
+  board_id integer references boards (id),
+  number integer,
+  assignee_id text,
+  estimate real,
+  created_at text not null,
+  updated_at text not null
+);
+create table if not exists issue_labels (
+  issue_id integer not null references issues (id) on delete cascade,
+  label text not null,
+  primary key (issue_id, label)
+);
+create table if not exists comments (
+  id integer primary key,
+  issue_id integer not null references issues (id) on delete cascade,
+  user_id text not null,
+  body text not null,
+  created_at text not null
+);
+create table if not exists issue_links (
+  id integer primary key,
+  source_id integer not null references issues (id) on delete cascade,
+  target_id integer not null references issues (id) on delete cascade,
+  kind text not null,
+  created_at text not null,
+  unique (source_id, target_id, kind)
+);
+`
+
+// sqliteAddedColumns were added to issues after the first local databases
+// were created, which create table if not exists leaves without them.
+var sqliteAddedColumns = []string{"assignee_id text", "estimate real"}
+
+// SQLiteStore keeps boards, issues, labels and comments in a SQLite file for
+// single-user use without a server. Issues are filtered in memory, which is
+// fine at the size of a personal tracker.
+type SQLiteStore struct {
+	DB    *sql.DB
+	id    string
+	board Board
+}
+
+// OpenSQLiteStore opens or creates the database at path. A new database
+// gets a LOCAL board that issues are created on.
+func OpenSQLiteStore(ctx context.Context, path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open local database: %w", err)
+	}
+	s := &SQLiteStore{DB: db}
+	if err := s.init(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open local database: %w", err)
+	}
+	return s, nil
+}
+
+func (s *SQLiteStore) init(ctx context.Context) error {
+	if _, err := s.DB.ExecContext(ctx, sqliteSchema); err != nil {
+		return err
+	}
+	for _, column := range sqliteAddedColumns {
+		name, _, _ := strings.Cut(column, " ")
+		var n int
+		if err := s.DB.QueryRowContext(ctx,
+			"select count(*) from pragma_table_info('issues') where name = ?", name).Scan(&n); err != nil {
+			return err
+		}
+		if n > 0 {
+			continue
+		}
+		if _, err := s.DB.ExecContext(ctx, "alter table issues add column "+column); err != nil {
+			return err
+		}
+	}
+
+	// The database id keeps issues from different local databases apart
+	// when they are synced to the same board.
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	if _, err := s.DB.ExecContext(ctx,
+		"insert or ignore into meta (key, value) values ('id', ?)", hex.EncodeToString(buf)); err != nil {
+		return err
+	}
+	if err := s.DB.QueryRowContext(ctx, "select value from meta where key = 'id'").Scan(&s.id); err != nil {
+		return err
+	}
+
+	if _, err := s.DB.ExecContext(ctx,
+		"insert or ignore into boards (key, name) values (?, 'Local')", localBoardKey); err != nil {
+		return err
+	}
+	return s.DB.QueryRowContext(ctx,
+		"select id, key, name from boards where key = ?", localBoardKey).Scan(&s.board.ID, &s.board.Key, &s.board.Name)
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.DB.Close()
+}
+
+const sqliteIssueColumns = `id, title, description, status, user_id, parent_id, board_id,
+	coalesce(number, 0), coalesce(assignee_id, ''), estimate, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSQLiteIssue(row rowScanner) (Issue, error) {
+	var is Issue
+	var parentID, boardID sql.NullInt64
+	var estimate sql.NullFloat64
+	err := row.Scan(&is.ID, &is.Title, &is.Description, &is.Status, &is.UserID,
+		&parentID, &boardID, &is.Number, &is.AssigneeID, &estimate, &is.CreatedAt, &is.UpdatedAt)
+	if err != nil {
+		return Issue{}, err
+	}
+	if parentID.Valid {
+		id := int(parentID.Int64)
+		is.ParentID = &id
+	}
+	if boardID.Valid {
+		id := int(boardID.Int64)
+		is.BoardID = &id
+	}
+	if estimate.Valid {
+		is.Estimate = &estimate.Float64
+	}
+	return is, nil
+}
+
+// withLabels fills in the labels of issues.
+func (s *SQLiteStore) withLabels(ctx context.Context, issues []Issue) error {
+	rows, err := s.DB.QueryContext(ctx, "select issue_id, label from issue_labels order by issue_id, label")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	labels := map[int][]string{}
+	for rows.Next() {
+		var id int
+		var label string
+		if err := rows.Scan(&id, &label); err != nil {
+			return err
+		}
+		labels[id] = append(labels[id], label)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for i := range issues {
+		issues[i].Labels = labels[issues[i].ID]
+	}
+	return nil
+}
+
+func (s *SQLiteStore) ListIssues(ctx context.Context) ([]Issue, error) {
+	issues, err := s.queryIssues(ctx, "select "+sqliteIssueColumns+" from issues order by id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch issues: %w", err)
+	}
+	return issues, nil
+}
+
+func (s *SQLiteStore) ListChildIssues(ctx context.Context, parentID int) ([]Issue, error) {
+	issues, err := s.queryIssues(ctx, "select "+sqliteIssueColumns+" from issues where parent_id = ? order by id", parentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch child issues: %w", err)
+	}
+	return issues, nil
+}
+
+// queryIssues runs a select of sqliteIssueColumns and fills in labels.
+func (s *SQLiteStore) queryIssues(ctx context.Context, query string, args ...interface{}) ([]Issue, error) {
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var issues []Issue
+	for rows.Next() {
+		is, err := scanSQLiteIssue(rows)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, is)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := s.withLabels(ctx, issues); err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
+
+func (s *SQLiteStore) GetIssue(ctx context.Context, id int) (*Issue, error) {
+	is, err := scanSQLiteIssue(s.DB.QueryRowContext(ctx, "select "+sqliteIssueColumns+" from issues where id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("issue #%d not found", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch issue: %w", err)
+	}
+	rows, err := s.DB.QueryContext(ctx, "select label from issue_labels where issue_id = ? order by label", id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch labels: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var label string
+		if err := rows.Scan(&label); err != nil {
+			return nil, fmt.Errorf("failed to fetch labels: %w", err)
+		}
+		is.Labels = append(is.Labels, label)
+	}
+	return &is, rows.Err()
+}
+
+// CreateIssue adds an issue to the local board, or to req.BoardID when set,
+// and gives it the board's next number.
+func (s *SQLiteStore) CreateIssue(ctx context.Context, req CreateIssueRequest, userID string) (*Issue, error) {
+	status := req.Status
+	if status == "" {
+		status = "open"
+	}
+	boardID := s.board.ID
+	if req.BoardID != nil {
+		boardID = *req.BoardID
+	}
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating a issue %w", err)
+	}
+	defer tx.Rollback()
+
+	var number int
+	err = tx.QueryRowContext(ctx,
+		"update boards set next_number = next_number + 1 where id = ? returning next_number - 1", boardID).Scan(&number)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("board %d not found", boardID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while creating a issue %w", err)
+	}
+
+	res, err := tx.ExecContext(ctx, `insert into issues
+		(title, description, status, user_id, parent_id, board_id, number, created_at, updated_at)
+		values (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		req.Title, req.Description, status, userID, req.ParentID, boardID, number, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating a issue %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("error while creating a issue %w", err)
+	}
+	for _, label := range req.Labels {
+		if _, err := tx.ExecContext(ctx,
+			"insert or ignore into issue_labels (issue_id, label) values (?, ?)", id, label); err != nil {
+			return nil, fmt.Errorf("error while creating a issue %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error while creating a issue %w", err)
+	}
+	return s.GetIssue(ctx, int(id))
+}
+
+func (s *SQLiteStore) UpdateIssue(ctx context.Context, id int, title, description string) (*Issue, error) {
+	res, err := s.DB.ExecContext(ctx,
+		"update issues set title = ?, description = ?, updated_at = ? where id = ?",
+		title, description, time.Now().UTC().Format(time.RFC3339Nano), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update issue: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, fmt.Errorf("issue #%d not found", id)
+	}
+	return s.GetIssue(ctx, id)
+}
+
+func (s *SQLiteStore) SetLabels(ctx context.Context, id int, labels []string) (*Issue, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set labels: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		"update issues set updated_at = ? where id = ?", time.Now().UTC().Format(time.RFC3339Nano), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set labels: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, fmt.Errorf("issue #%d not found", id)
+	}
+	if _, err := tx.ExecContext(ctx, "delete from issue_labels where issue_id = ?", id); err != nil {
+		return nil, fmt.Errorf("failed to set labels: %w", err)
+	}
+	for _, label := range labels {
+		if _, err := tx.ExecContext(ctx,
+			"insert or ignore into issue_labels (issue_id, label) values (?, ?)", id, label); err != nil {
+			return nil, fmt.Errorf("failed to set labels: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to set labels: %w", err)
+	}
+	return s.GetIssue(ctx, id)
+}
+
+func (s *SQLiteStore) DeleteIssue(ctx context.Context, id int) error {
+	if _, err := s.DB.ExecContext(ctx, "delete from issues where id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete issue: %w", err)
+	}
+	return nil
+}
+
+// setIssueColumn sets one column of an issue, which the caller names.
+func (s *SQLiteStore) setIssueColumn(ctx context.Context, id int, column string, value interface{}) (*Issue, error) {
+	res, err := s.DB.ExecContext(ctx,
+		"update issues set "+column+" = ?, updated_at = ? where id = ?",
+		value, time.Now().UTC().Format(time.RFC3339Nano), id)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, fmt.Errorf("issue #%d not found", id)
+	}
+	return s.GetIssue(ctx, id)
+}
+
+func (s *SQLiteStore) SetParent(ctx context.Context, id int, parentID *int) (*Issue, error) {
+	if parentID != nil {
+		get := func(id int) (*Issue, error) { return s.GetIssue(ctx, id) }
+		if err := checkParentCycle(id, *parentID, get); err != nil {
+			return nil, err
+		}
+	}
+	is, err := s.setIssueColumn(ctx, id, "parent_id", parentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set parent: %w", err)
+	}
+	return is, nil
+}
+
+func (s *SQLiteStore) SetEstimate(ctx context.Context, id int, points *float64) (*Issue, error) {
+	if err := checkEstimate(points); err != nil {
+		return nil, err
+	}
+	is, err := s.setIssueColumn(ctx, id, "estimate", points)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set estimate: %w", err)
+	}
+	return is, nil
+}
+
+func (s *SQLiteStore) SetAssignee(ctx context.Context, id int, assigneeID string) (*Issue, error) {
+	assignee := sql.NullString{String: assigneeID, Valid: assigneeID != ""}
+	is, err := s.setIssueColumn(ctx, id, "assignee_id", assignee)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assign issue: %w", err)
+	}
+	return is, nil
+}
+
+// TransitionIssue applies a transition in one transaction, like the
+// transition_issue function does in Postgres.
+func (s *SQLiteStore) TransitionIssue(ctx context.Context, req TransitionRequest, userID string) (*TransitionResult, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update status: %w", err)
+	}
+	defer tx.Rollback()
+
+	var parentID sql.NullInt64
+	err = tx.QueryRowContext(ctx, "select parent_id from issues where id = ?", req.IssueID).Scan(&parentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("issue #%d not found", req.IssueID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update status: %w", err)
+	}
+
+	closing := isClosedStatus(req.Status)
+	if closing && !req.Force {
+		blockers, err := sqliteOpenBlockers(ctx, tx, req.IssueID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch blockers: %w", err)
+		}
+		if len(blockers) > 0 {
+			return nil, &BlockedError{IssueID: req.IssueID, Blockers: blockers}
+		}
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	if _, err := tx.ExecContext(ctx,
+		"update issues set status = ?, updated_at = ? where id = ?", req.Status, now, req.IssueID); err != nil {
+		return nil, fmt.Errorf("failed to update status: %w", err)
+	}
+
+	result := &TransitionResult{}
+	if req.Comment != "" {
+		c := Comment{IssueID: req.IssueID, UserID: userID, Body: req.Comment, CreatedAt: now}
+		res, err := tx.ExecContext(ctx,
+			"insert into comments (issue_id, user_id, body, created_at) values (?, ?, ?, ?)",
+			c.IssueID, c.UserID, c.Body, c.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create comment: %w", err)
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create comment: %w", err)
+		}
+		c.ID = int(id)
+		result.Comment = &c
+	}
+
+	if closing && parentID.Valid {
+		var open int
+		err := tx.QueryRowContext(ctx,
+			"select count(*) from issues where parent_id = ? and status not in ('closed', 'done')",
+			parentID.Int64).Scan(&open)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update status: %w", err)
+		}
+		if open == 0 {
+			id := int(parentID.Int64)
+			result.CompletedParent = &id
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to update status: %w", err)
+	}
+	is, err := s.GetIssue(ctx, req.IssueID)
+	if err != nil {
+		return nil, err
+	}
+	result.Issue = *is
+	return result, nil
+}
+
+// sqliteOpenBlockers returns the open issues that block issueID.
+func sqliteOpenBlockers(ctx context.Context, tx *sql.Tx, issueID int) ([]int, error) {
+	rows, err := tx.QueryContext(ctx, `select b.id from issue_links l join issues b on b.id = l.source_id
+		where l.target_id = ? and l.kind = ? and b.status not in ('closed', 'done') order by b.id`,
+		issueID, LinkBlocks)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+const sqliteLinkColumns = "id, source_id, target_id, kind, created_at"
+
+func (s *SQLiteStore) ListLinks(ctx context.Context, issueID int) ([]IssueLink, error) {
+	rows, err := s.DB.QueryContext(ctx,
+		"select "+sqliteLinkColumns+" from issue_links where source_id = ? or target_id = ? order by id",
+		issueID, issueID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch issue links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []IssueLink
+	for rows.Next() {
+		var l IssueLink
+		if err := rows.Scan(&l.ID, &l.SourceID, &l.TargetID, &l.Kind, &l.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to fetch issue links: %w", err)
+		}
+		links = append(links, l)
+	}
+	return links, rows.Err()
+}
+
+func (s *SQLiteStore) LinkIssues(ctx context.Context, sourceID, targetID int, kind LinkKind) (*IssueLink, error) {
+	if err := checkLink(sourceID, targetID, kind); err != nil {
+		return nil, err
+	}
+	if _, err := s.DB.ExecContext(ctx, `insert into issue_links (source_id, target_id, kind, created_at)
+		values (?, ?, ?, ?) on conflict (source_id, target_id, kind) do nothing`,
+		sourceID, targetID, kind, time.Now().UTC().Format(time.RFC3339Nano)); err != nil {
+		return nil, fmt.Errorf("failed to link issues: %w", err)
+	}
+	var l IssueLink
+	err := s.DB.QueryRowContext(ctx,
+		"select "+sqliteLinkColumns+" from issue_links where source_id = ? and target_id = ? and kind = ?",
+		sourceID, targetID, kind).Scan(&l.ID, &l.SourceID, &l.TargetID, &l.Kind, &l.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to link issues: %w", err)
+	}
+	return &l, nil
+}
+
+func (s *SQLiteStore) UnlinkIssues(ctx context.Context, issueID, otherID int) error {
+	_, err := s.DB.ExecContext(ctx, `delete from issue_links
+		where (source_id = ? and target_id = ?) or (source_id = ? and target_id = ?)`,
+		issueID, otherID, otherID, issueID)
+	if err != nil {
+		return fmt.Errorf("failed to unlink issues: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) SearchIssues(ctx context.Context, query string) ([]SearchResult, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, nil
+	}
+	issues, err := s.ListIssues(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search issues: %w", err)
+	}
+	rows, err := s.DB.QueryContext(ctx, "select id, issue_id, user_id, body, created_at from comments order by id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to search issues: %w", err)
+	}
+	defer rows.Close()
+
+	var comments []Comment
+	for rows.Next() {
+		var c Comment
+		if err := rows.Scan(&c.ID, &c.IssueID, &c.UserID, &c.Body, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to search issues: %w", err)
+		}
+		comments = append(comments, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to search issues: %w", err)
+	}
+	return searchLocal(query, issues, comments), nil
+}
+
+func (s *SQLiteStore) QueryIssues(ctx context.Context, query, sortSpec, userID string) ([]Issue, error) {
+	q, err := ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	column, ascending, err := ParseSort(sortSpec)
+	if err != nil {
+		return nil, err
+	}
+	all, err := s.ListIssues(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []Issue
+	for i := range all {
+		ok, err := q.Match(&all[i], userID)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			issues = append(issues, all[i])
+		}
+	}
+	if column != "" {
+		sortIssues(issues, column, ascending)
+	}
+	return issues, nil
+}
+
+// ListComments returns an issue's comments, oldest first.
+func (s *SQLiteStore) ListComments(ctx context.Context, issueID int) ([]Comment, error) {
+	rows, err := s.DB.QueryContext(ctx,
+		"select id, issue_id, user_id, body, created_at from comments where issue_id = ? order by id", issueID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch comments: %w", err)
+	}
+	defer rows.Close()
+
+	var comments []Comment
+	for rows.Next() {
+		var c Comment
+		if err := rows.Scan(&c.ID, &c.IssueID, &c.UserID, &c.Body, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to fetch comments: %w", err)
+		}
+		comments = append(comments, c)
+	}
+	return comments, rows.Err()
+}
+
+func (s *SQLiteStore) CreateComment(ctx context.Context, issueID int, userID, body string) (*Comment, error) {
+	c := Comment{IssueID: issueID, UserID: userID, Body: body, CreatedAt: time.Now().UTC().Format(time.RFC3339Nano)}
+	res, err := s.DB.ExecContext(ctx,
+		"insert into comments (issue_id, user_id, body, created_at) values (?, ?, ?, ?)",
+		c.IssueID, c.UserID, c.Body, c.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create comment: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create comment: %w", err)
+	}
+	c.ID = int(id)
+	return &c, nil
+}
+
+// CurrentBoard returns the LOCAL board when key is empty.
+func (s *SQLiteStore) CurrentBoard(ctx context.Context, key string) (*Board, error) {
+	if key == "" {
+		b := s.board
+		return &b, nil
+	}
+	var b Board
+	err := s.DB.QueryRowContext(ctx, "select id, key, name from boards where key = ?", key).Scan(&b.ID, &b.Key, &b.Name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("board %q not found", key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch boards: %w", err)
+	}
+	return &b, nil
+}
+
+// SyncItems returns every local issue with its comments in the form
+// ImportIssues takes. External IDs are local:<database id>/<issue id>, so
+// pushing the same database again updates the issues it created before.
+func (s *SQLiteStore) SyncItems(ctx context.Context) ([]ImportIssue, error) {
+	issues, err := s.ListIssues(ctx)
+	if err != nil {
+		return nil, err
+	}
+	externalID := func(id int) string { return "local:" + s.id + "/" + strconv.Itoa(id) }
+
+	items := make([]ImportIssue, 0, len(issues))
+	for _, is := range issues {
+		it := ImportIssue{
+			ExternalID:  externalID(is.ID),
+			Title:       is.Title,
+			Description: is.Description,
+			Status:      is.Status,
+			Labels:      is.Labels,
+			CreatedAt:   is.CreatedAt,
+		}
+		if is.ParentID != nil {
+			it.ParentExternalID = externalID(*is.ParentID)
+		}
+		comments, err := s.ListComments(ctx, is.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range comments {
+			it.Comments = append(it.Comments, ImportComment{
+				ExternalID: externalID(is.ID) + "#" + strconv.Itoa(c.ID),
+				Body:       c.Body,
+				CreatedAt:  c.CreatedAt,
+			})
+		}
+		items = append(items, it)
+	}
+	return items, nil
+}
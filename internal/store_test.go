@@ -0,0 +1,367 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// localStores returns a fresh store of each kind that needs no server.
+func localStores(t *testing.T) map[string]IssueStore {
+	t.Helper()
+	sqlite, err := OpenSQLiteStore(context.Background(), filepath.Join(t.TempDir(), "local.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlite.Close() })
+	return map[string]IssueStore{
+		"memory": NewMemoryStore(),
+		"sqlite": sqlite,
+	}
+}
+
+func TestStoreListChildIssues(t *testing.T) {
+	for name, store := range localStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			parent, err := store.CreateIssue(ctx, CreateIssueRequest{Title: "Epic"}, LocalUserID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, title := range []string{"First", "Second"} {
+				req := CreateIssueRequest{Title: title, ParentID: &parent.ID, Labels: []string{"sub"}}
+				if _, err := store.CreateIssue(ctx, req, LocalUserID); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if _, err := store.CreateIssue(ctx, CreateIssueRequest{Title: "Unrelated"}, LocalUserID); err != nil {
+				t.Fatal(err)
+			}
+
+			children, err := store.ListChildIssues(ctx, parent.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(children) != 2 || children[0].Title != "First" || children[1].Title != "Second" {
+				t.Fatalf("children = %+v", children)
+			}
+			if len(children[0].Labels) != 1 || children[0].Labels[0] != "sub" {
+				t.Errorf("child labels = %v", children[0].Labels)
+			}
+			if none, err := store.ListChildIssues(ctx, children[0].ID); err != nil || len(none) != 0 {
+				t.Errorf("children of a leaf = %v, %v", none, err)
+			}
+		})
+	}
+}
+
+func TestStoreComments(t *testing.T) {
+	for name, store := range localStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			is, err := store.CreateIssue(ctx, CreateIssueRequest{Title: "Discussed"}, LocalUserID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			other, err := store.CreateIssue(ctx, CreateIssueRequest{Title: "Quiet"}, LocalUserID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, body := range []string{"first", "second"} {
+				c, err := store.CreateComment(ctx, is.ID, LocalUserID, body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if c.ID == 0 || c.IssueID != is.ID || c.UserID != LocalUserID || c.CreatedAt == "" {
+					t.Errorf("created comment = %+v", c)
+				}
+			}
+
+			comments, err := store.ListComments(ctx, is.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(comments) != 2 || comments[0].Body != "first" || comments[1].Body != "second" {
+				t.Fatalf("comments = %+v", comments)
+			}
+			if none, err := store.ListComments(ctx, other.ID); err != nil || len(none) != 0 {
+				t.Errorf("comments of another issue = %v, %v", none, err)
+			}
+		})
+	}
+}
+
+func TestStoreSetLabels(t *testing.T) {
+	for name, store := range localStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			is, err := store.CreateIssue(ctx, CreateIssueRequest{Title: "Tagged", Labels: []string{"old"}}, LocalUserID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			updated, err := store.SetLabels(ctx, is.ID, []string{"bug", "ui"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(updated.Labels) != 2 || updated.Labels[0] != "bug" || updated.Labels[1] != "ui" {
+				t.Errorf("labels after set = %v", updated.Labels)
+			}
+			cleared, err := store.SetLabels(ctx, is.ID, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(cleared.Labels) != 0 {
+				t.Errorf("labels after clearing = %v", cleared.Labels)
+			}
+			if _, err := store.SetLabels(ctx, is.ID+100, []string{"bug"}); err == nil {
+				t.Error("setting labels on a missing issue succeeded")
+			}
+		})
+	}
+}
+
+func TestSQLiteCurrentBoard(t *testing.T) {
+	ctx := context.Background()
+	store, err := OpenSQLiteStore(ctx, filepath.Join(t.TempDir(), "local.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	board, err := store.CurrentBoard(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if board.Key != "LOCAL" {
+		t.Fatalf("default board = %+v", board)
+	}
+	if same, err := store.CurrentBoard(ctx, "LOCAL"); err != nil || same.ID != board.ID {
+		t.Errorf("board by key = %+v, %v", same, err)
+	}
+	if _, err := store.CurrentBoard(ctx, "NOPE"); err == nil {
+		t.Error("found a board that does not exist")
+	}
+
+	is, err := store.CreateIssue(ctx, CreateIssueRequest{Title: "Numbered", BoardID: &board.ID}, LocalUserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key := is.Key(board); key != "LOCAL-1" {
+		t.Errorf("key = %q, want LOCAL-1", key)
+	}
+}
+
+func TestSQLiteAddsColumns(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "local.db")
+	// An issues table from before assignees and estimates.
+	db, err := sql.Open("sqlite", "file:"+path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`create table issues (
+		id integer primary key, title text not null, description text not null default '',
+		status text not null default 'open', user_id text not null, parent_id integer,
+		board_id integer, number integer, created_at text not null, updated_at text not null);
+		insert into issues (title, user_id, created_at, updated_at) values ('Old', 'local', '', '')`)
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := OpenSQLiteStore(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	points := 2.0
+	if is, err := store.SetEstimate(ctx, 1, &points); err != nil || is.Title != "Old" || *is.Estimate != 2 {
+		t.Fatalf("SetEstimate on an old database = %+v, %v", is, err)
+	}
+	if _, err := store.SetAssignee(ctx, 1, LocalUserID); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStoreTransitionIssue(t *testing.T) {
+	for name, store := range localStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			parent, err := store.CreateIssue(ctx, CreateIssueRequest{Title: "Epic"}, LocalUserID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			blocked, err := store.CreateIssue(ctx, CreateIssueRequest{Title: "Blocked", ParentID: &parent.ID}, LocalUserID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			blocker, err := store.CreateIssue(ctx, CreateIssueRequest{Title: "Blocker"}, LocalUserID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := store.LinkIssues(ctx, blocker.ID, blocked.ID, LinkBlocks); err != nil {
+				t.Fatal(err)
+			}
+
+			close := TransitionRequest{IssueID: blocked.ID, Status: "closed", Comment: "done"}
+			_, err = store.TransitionIssue(ctx, close, LocalUserID)
+			var be *BlockedError
+			if !errors.As(err, &be) || len(be.Blockers) != 1 || be.Blockers[0] != blocker.ID {
+				t.Fatalf("closing a blocked issue: err = %v", err)
+			}
+			if is, _ := store.GetIssue(ctx, blocked.ID); is.Status != "open" {
+				t.Errorf("status after a blocked close = %q", is.Status)
+			}
+			if comments, _ := store.ListComments(ctx, blocked.ID); len(comments) != 0 {
+				t.Errorf("comments after a blocked close = %+v", comments)
+			}
+
+			if _, err := store.TransitionIssue(ctx, TransitionRequest{IssueID: blocker.ID, Status: "done"}, LocalUserID); err != nil {
+				t.Fatal(err)
+			}
+			res, err := store.TransitionIssue(ctx, close, LocalUserID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Issue.Status != "closed" || res.Comment == nil || res.Comment.Body != "done" {
+				t.Errorf("result = %+v", res)
+			}
+			if res.CompletedParent == nil || *res.CompletedParent != parent.ID {
+				t.Errorf("completed parent = %v, want %d", res.CompletedParent, parent.ID)
+			}
+
+			// Forcing skips the blocker check.
+			if _, err := store.TransitionIssue(ctx, TransitionRequest{IssueID: blocker.ID, Status: "open"}, LocalUserID); err != nil {
+				t.Fatal(err)
+			}
+			force := TransitionRequest{IssueID: blocked.ID, Status: "closed", Force: true}
+			if _, err := store.TransitionIssue(ctx, force, LocalUserID); err != nil {
+				t.Errorf("forced close: %v", err)
+			}
+			if _, err := store.TransitionIssue(ctx, TransitionRequest{IssueID: 999, Status: "closed"}, LocalUserID); err == nil {
+				t.Error("transitioning a missing issue succeeded")
+			}
+		})
+	}
+}
+
+func TestStoreLinks(t *testing.T) {
+	for name, store := range localStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			a, _ := store.CreateIssue(ctx, CreateIssueRequest{Title: "A"}, LocalUserID)
+			b, _ := store.CreateIssue(ctx, CreateIssueRequest{Title: "B"}, LocalUserID)
+
+			first, err := store.LinkIssues(ctx, a.ID, b.ID, LinkRelatesTo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			again, err := store.LinkIssues(ctx, a.ID, b.ID, LinkRelatesTo)
+			if err != nil || again.ID != first.ID {
+				t.Errorf("linking twice = %+v, %v; want link %d", again, err, first.ID)
+			}
+			if _, err := store.LinkIssues(ctx, a.ID, a.ID, LinkBlocks); err == nil {
+				t.Error("linking an issue to itself succeeded")
+			}
+			if _, err := store.LinkIssues(ctx, a.ID, b.ID, "follows"); err == nil {
+				t.Error("linking with an unknown kind succeeded")
+			}
+
+			links, err := store.ListLinks(ctx, b.ID)
+			if err != nil || len(links) != 1 || links[0].SourceID != a.ID || links[0].Kind != LinkRelatesTo {
+				t.Fatalf("links of B = %+v, %v", links, err)
+			}
+			if err := store.UnlinkIssues(ctx, b.ID, a.ID); err != nil {
+				t.Fatal(err)
+			}
+			if links, _ := store.ListLinks(ctx, a.ID); len(links) != 0 {
+				t.Errorf("links after unlinking = %+v", links)
+			}
+		})
+	}
+}
+
+func TestStoreSetFields(t *testing.T) {
+	for name, store := range localStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			parent, _ := store.CreateIssue(ctx, CreateIssueRequest{Title: "Parent"}, LocalUserID)
+			child, _ := store.CreateIssue(ctx, CreateIssueRequest{Title: "Child"}, LocalUserID)
+
+			is, err := store.SetParent(ctx, child.ID, &parent.ID)
+			if err != nil || is.ParentID == nil || *is.ParentID != parent.ID {
+				t.Fatalf("SetParent = %+v, %v", is, err)
+			}
+			if _, err := store.SetParent(ctx, parent.ID, &child.ID); err == nil {
+				t.Error("making a parent its child's child succeeded")
+			}
+			if is, err := store.SetParent(ctx, child.ID, nil); err != nil || is.ParentID != nil {
+				t.Errorf("clearing the parent = %+v, %v", is, err)
+			}
+
+			points := 3.5
+			if is, err := store.SetEstimate(ctx, child.ID, &points); err != nil || is.Estimate == nil || *is.Estimate != 3.5 {
+				t.Errorf("SetEstimate = %+v, %v", is, err)
+			}
+			negative := -1.0
+			if _, err := store.SetEstimate(ctx, child.ID, &negative); err == nil {
+				t.Error("negative estimate accepted")
+			}
+			if is, err := store.SetEstimate(ctx, child.ID, nil); err != nil || is.Estimate != nil {
+				t.Errorf("clearing the estimate = %+v, %v", is, err)
+			}
+
+			if is, err := store.SetAssignee(ctx, child.ID, LocalUserID); err != nil || is.AssigneeID != LocalUserID {
+				t.Errorf("SetAssignee = %+v, %v", is, err)
+			}
+			mine, err := store.QueryIssues(ctx, "assignee:me", "", LocalUserID)
+			if err != nil || len(mine) != 1 || mine[0].ID != child.ID {
+				t.Errorf("assignee:me = %+v, %v", mine, err)
+			}
+			if is, err := store.SetAssignee(ctx, child.ID, ""); err != nil || is.AssigneeID != "" {
+				t.Errorf("unassigning = %+v, %v", is, err)
+			}
+			if _, err := store.SetAssignee(ctx, 999, LocalUserID); err == nil {
+				t.Error("assigning a missing issue succeeded")
+			}
+		})
+	}
+}
+
+func TestStoreSearchIssues(t *testing.T) {
+	for name, store := range localStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			titled, _ := store.CreateIssue(ctx, CreateIssueRequest{Title: "Login crash", Description: "on submit"}, LocalUserID)
+			described, _ := store.CreateIssue(ctx, CreateIssueRequest{Title: "Form", Description: "Crash on login"}, LocalUserID)
+			commented, _ := store.CreateIssue(ctx, CreateIssueRequest{Title: "Quiet"}, LocalUserID)
+			if _, err := store.CreateComment(ctx, commented.ID, LocalUserID, "same LOGIN crash here"); err != nil {
+				t.Fatal(err)
+			}
+			store.CreateIssue(ctx, CreateIssueRequest{Title: "Login page"}, LocalUserID)
+
+			results, err := store.SearchIssues(ctx, "login crash")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []int
+			for _, r := range results {
+				got = append(got, r.Issue.ID)
+			}
+			want := []int{titled.ID, described.ID, commented.ID}
+			if !slices.Equal(got, want) {
+				t.Fatalf("results = %v, want %v", got, want)
+			}
+			if results[0].Snippet != "" || results[2].Snippet != "same LOGIN crash here" {
+				t.Errorf("snippets = %q, %q", results[0].Snippet, results[2].Snippet)
+			}
+			if results, err := store.SearchIssues(ctx, "  "); err != nil || len(results) != 0 {
+				t.Errorf("blank search = %v, %v", results, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/supabase-community/postgrest-go"
+
+	"zel/lo/supabase"
+)
+
+// IssueTemplate pre-fills new issues on a board. Body is Markdown and may
+// contain {{field}} placeholders, see Render.
+type IssueTemplate struct {
+	ID          int      `json:"id,omitempty"`
+	BoardID     int      `json:"board_id"`
+	Name        string   `json:"name"`
+	TitlePrefix string   `json:"title_prefix"`
+	Labels      []string `json:"labels"`
+	Body        string   `json:"body"`
+}
+
+// DefaultTemplates are offered on boards that have not defined their own.
+var DefaultTemplates = []IssueTemplate{
+	{
+		Name:        "Bug report",
+		TitlePrefix: "[Bug] ",
+		Labels:      []string{"bug"},
+		Body: `## Summary
+{{summary}}
+
+## Steps to reproduce
+1. 
+
+## Expected behaviour
+
+## Actual behaviour
+
+## Environment
+- Version: {{version}}
+- Reported by {{author}} on {{date}}
+`,
+	},
+	{
+		Name:        "Feature request",
+		TitlePrefix: "[Feature] ",
+		Labels:      []string{"enhancement"},
+		Body: `## Problem
+{{problem}}
+
+## Proposed solution
+
+## Alternatives considered
+`,
+	},
+}
+
+var templateField = regexp.MustCompile(`\{\{\s*([a-zA-Z0-9_]+)\s*\}\}`)
+
+// ListIssueTemplates returns a board's templates, or DefaultTemplates when it
+// has none.
+func ListIssueTemplates(client *supabase.Client, boardID int) ([]IssueTemplate, error) {
+	var templates []IssueTemplate
+
+	_, err := client.From("issue_templates").
+		Select("*", "", false).
+		Eq("board_id", strconv.Itoa(boardID)).
+		Order("name", &postgrest.OrderOpts{Ascending: true}).
+		ExecuteTo(&templates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch issue templates: %w", err)
+	}
+
+	if len(templates) == 0 {
+		return DefaultTemplates, nil
+	}
+	return templates, nil
+}
+
+// Fields lists the placeholders used in the template body, in order of first
+// appearance.
+func (t IssueTemplate) Fields() []string {
+	var fields []string
+	seen := map[string]bool{}
+	for _, m := range templateField.FindAllStringSubmatch(t.Body, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			fields = append(fields, m[1])
+		}
+	}
+	return fields
+}
+
+// Render fills the body's placeholders from values. Placeholders without a
+// value become a [fill in: field] blank so they stand out in the editor.
+func (t IssueTemplate) Render(values map[string]string) string {
+	return templateField.ReplaceAllStringFunc(t.Body, func(s string) string {
+		name := strings.TrimSpace(strings.Trim(s, "{}"))
+		if v, ok := values[name]; ok && v != "" {
+			return v
+		}
+		return "[fill in: " + name + "]"
+	})
+}
+
+// SaveIssueTemplate stores a template on its board, replacing the board's
+// template of the same name.
+func SaveIssueTemplate(client *supabase.Client, t IssueTemplate) (*IssueTemplate, error) {
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Name == "" {
+		return nil, fmt.Errorf("template name required")
+	}
+	if t.Labels == nil {
+		t.Labels = []string{}
+	}
+
+	var templates []IssueTemplate
+
+	templateData := map[string]interface{}{
+		"board_id":     t.BoardID,
+		"name":         t.Name,
+		"title_prefix": t.TitlePrefix,
+		"labels":       t.Labels,
+		"body":         t.Body,
+	}
+
+	_, err := client.From("issue_templates").
+		Upsert([]map[string]interface{}{templateData}, "board_id,name", "representation", "").
+		ExecuteTo(&templates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save issue template: %w", err)
+	}
+
+	if len(templates) == 0 {
+		return nil, fmt.Errorf("save succeeded but no template returned")
+	}
+
+	return &templates[0], nil
+}
+
+// DeleteIssueTemplate removes a board's template by name. Once a board has
+// no templates left, DefaultTemplates are offered again.
+func DeleteIssueTemplate(client *supabase.Client, boardID int, name string) error {
+	var deleted []IssueTemplate
+	_, err := client.From("issue_templates").
+		Delete("representation", "").
+		Eq("board_id", strconv.Itoa(boardID)).
+		Eq("name", name).
+		ExecuteTo(&deleted)
+	if err != nil {
+		return fmt.Errorf("failed to delete issue template: %w", err)
+	}
+	if len(deleted) == 0 {
+		return fmt.Errorf("no template named %q on this board", name)
+	}
+	return nil
+}
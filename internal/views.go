@@ -0,0 +1,112 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+
+	"zel/lo/supabase"
+
+	"github.com/supabase-community/postgrest-go"
+)
+
+// SavedView is a named filter, sort and column layout for the issue list.
+// Views belong to the user who saved them; shared views are visible to
+// everyone on the board they were saved on.
+type SavedView struct {
+	ID      int      `json:"id,omitempty"`
+	UserID  string   `json:"user_id"`
+	Name    string   `json:"name"`
+	Query   string   `json:"query"`
+	Sort    string   `json:"sort"`
+	Columns []string `json:"columns"`
+	Shared  bool     `json:"shared"`
+	BoardID *int     `json:"board_id,omitempty"`
+}
+
+// ListSavedViews returns the user's own views followed by views other users
+// have shared on the board, each group ordered by name. Without a board
+// (boardID 0) only the user's own views are listed.
+func ListSavedViews(client *supabase.Client, userID string, boardID int) ([]SavedView, error) {
+	var views []SavedView
+
+	query := client.From("saved_views").Select("*", "", false)
+	if boardID != 0 {
+		query = query.Or(fmt.Sprintf("user_id.eq.%s,and(shared.is.true,board_id.eq.%d)", userID, boardID), "")
+	} else {
+		query = query.Eq("user_id", userID)
+	}
+	_, err := query.
+		Order("name", &postgrest.OrderOpts{Ascending: true}).
+		ExecuteTo(&views)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch saved views: %w", err)
+	}
+
+	own := make([]SavedView, 0, len(views))
+	var shared []SavedView
+	for _, v := range views {
+		if v.UserID == userID {
+			own = append(own, v)
+		} else {
+			shared = append(shared, v)
+		}
+	}
+	return append(own, shared...), nil
+}
+
+// SaveView stores a view for its user, replacing any view of theirs with the
+// same name. Shared views need a board to be shared with.
+func SaveView(client *supabase.Client, view SavedView) (*SavedView, error) {
+	if view.Name == "" {
+		return nil, fmt.Errorf("view name required")
+	}
+	if view.Shared && view.BoardID == nil {
+		return nil, fmt.Errorf("a shared view needs a board")
+	}
+	if _, err := ParseQuery(view.Query); err != nil {
+		return nil, err
+	}
+	if _, _, err := ParseSort(view.Sort); err != nil {
+		return nil, err
+	}
+
+	var views []SavedView
+
+	viewData := map[string]interface{}{
+		"user_id": view.UserID,
+		"name":    view.Name,
+		"query":   view.Query,
+		"sort":    view.Sort,
+		"columns": view.Columns,
+		"shared":  view.Shared,
+	}
+	if view.BoardID != nil {
+		viewData["board_id"] = *view.BoardID
+	}
+
+	_, err := client.From("saved_views").
+		Upsert([]map[string]interface{}{viewData}, "user_id,name", "representation", "").
+		ExecuteTo(&views)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save view: %w", err)
+	}
+
+	if len(views) == 0 {
+		return nil, fmt.Errorf("save succeeded but no view returned")
+	}
+
+	return &views[0], nil
+}
+
+// DeleteSavedView removes one of the user's views.
+func DeleteSavedView(client *supabase.Client, id int, userID string) error {
+	_, _, err := client.From("saved_views").
+		Delete("minimal", "").
+		Eq("id", strconv.Itoa(id)).
+		Eq("user_id", userID).
+		Execute()
+	if err != nil {
+		return fmt.Errorf("failed to delete view: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"zel/lo/supabase/supabasetest"
+)
+
+func TestSaveView(t *testing.T) {
+	board := 1
+	tests := []struct {
+		name    string
+		view    SavedView
+		wantErr string
+	}{
+		{"private", SavedView{UserID: "ada", Name: "Mine", Query: "assignee:me", Sort: "updated_at.desc"}, ""},
+		{"shared", SavedView{UserID: "ada", Name: "Bugs", Query: "label:bug", Shared: true, BoardID: &board}, ""},
+		{"no name", SavedView{UserID: "ada", Query: "label:bug"}, "view name required"},
+		{"shared without board", SavedView{UserID: "ada", Name: "Bugs", Shared: true}, "a shared view needs a board"},
+		{"bad query", SavedView{UserID: "ada", Name: "Broken", Query: "(status:open"}, "missing closing parenthesis"},
+		{"bad sort", SavedView{UserID: "ada", Name: "Broken", Sort: "priority"}, "priority"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := supabasetest.NewServer()
+			defer srv.Close()
+			srv.Unique("saved_views", "user_id", "name")
+
+			v, err := SaveView(srv.Client(), tt.view)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("err = %v, want %q", err, tt.wantErr)
+				}
+				if n := len(srv.Rows("saved_views")); n != 0 {
+					t.Errorf("stored %d views", n)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v.ID == 0 || v.Name != tt.view.Name || v.Query != tt.view.Query || v.Shared != tt.view.Shared {
+				t.Errorf("saved %+v", v)
+			}
+		})
+	}
+}
+
+func TestSaveViewReplacesByName(t *testing.T) {
+	srv := supabasetest.NewServer()
+	defer srv.Close()
+	srv.Unique("saved_views", "user_id", "name")
+	client := srv.Client()
+
+	for _, q := range []string{"status:open", "status:closed"} {
+		if _, err := SaveView(client, SavedView{UserID: "ada", Name: "Triage", Query: q}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := SaveView(client, SavedView{UserID: "bob", Name: "Triage", Query: "label:bug"}); err != nil {
+		t.Fatal(err)
+	}
+	rows := srv.Rows("saved_views")
+	if len(rows) != 2 || rows[0]["query"] != "status:closed" {
+		t.Errorf("views = %v, want Ada's replaced and Bob's added", rows)
+	}
+}
+
+func TestListSavedViews(t *testing.T) {
+	srv := supabasetest.NewServer()
+	defer srv.Close()
+	srv.Seed("saved_views",
+		map[string]interface{}{"user_id": "ada", "name": "Zeta", "shared": false},
+		map[string]interface{}{"user_id": "ada", "name": "Alpha", "shared": true, "board_id": 1},
+		map[string]interface{}{"user_id": "bob", "name": "Bob's bugs", "shared": true, "board_id": 1},
+		map[string]interface{}{"user_id": "bob", "name": "Another board", "shared": true, "board_id": 2},
+		map[string]interface{}{"user_id": "bob", "name": "Bob private", "shared": false, "board_id": 1},
+		map[string]interface{}{"user_id": "cy", "name": "Aardvark", "shared": true, "board_id": 1},
+	)
+
+	tests := []struct {
+		user  string
+		board int
+		want  string
+	}{
+		{"ada", 1, "Alpha,Zeta,Aardvark,Bob's bugs"},
+		{"ada", 2, "Alpha,Zeta,Another board"},
+		{"ada", 0, "Alpha,Zeta"},
+		{"bob", 1, "Another board,Bob private,Bob's bugs,Aardvark,Alpha"},
+		{"dee", 3, ""},
+	}
+	for _, tt := range tests {
+		views, err := ListSavedViews(srv.Client(), tt.user, tt.board)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, v := range views {
+			names = append(names, v.Name)
+		}
+		if got := strings.Join(names, ","); got != tt.want {
+			t.Errorf("ListSavedViews(%s, %d) = %s, want %s", tt.user, tt.board, got, tt.want)
+		}
+	}
+}
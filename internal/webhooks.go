@@ -0,0 +1,354 @@
+package internal
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/supabase-community/postgrest-go"
+
+	"zel/lo/supabase"
+	"zel/lo/webhook"
+)
+
+// Webhook event types. Each maps to an issue_events kind written by the
+// triggers on issues and comments.
+const (
+	WebhookIssueCreated       = "issue.created"
+	WebhookIssueUpdated       = "issue.updated"
+	WebhookIssueStatusChanged = "issue.status_changed"
+	WebhookCommentCreated     = "comment.created"
+	WebhookPing               = "ping"
+)
+
+var webhookEventKinds = map[string]string{
+	"created": WebhookIssueCreated,
+	"updated": WebhookIssueUpdated,
+	"status":  WebhookIssueStatusChanged,
+	"comment": WebhookCommentCreated,
+}
+
+// WebhookEvents lists the event types a webhook can subscribe to.
+var WebhookEvents = []string{WebhookIssueCreated, WebhookIssueUpdated, WebhookIssueStatusChanged, WebhookCommentCreated}
+
+// Webhook posts a board's issue events to URL. LastEventID is the dispatch
+// cursor: events up to it have been handled, delivered or not. Secret is
+// kept in webhook_secrets, which only the service role can read; it is set
+// on webhooks returned by CreateWebhook and loaded for deliveries.
+type Webhook struct {
+	ID          int      `json:"id"`
+	BoardID     int      `json:"board_id"`
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	Secret      string   `json:"-"`
+	Active      bool     `json:"active"`
+	LastEventID int      `json:"last_event_id"`
+	CreatedAt   string   `json:"created_at,omitempty"`
+}
+
+// Subscribed reports whether the webhook wants events of the given type.
+// Pings always go through.
+func (h Webhook) Subscribed(event string) bool {
+	return event == WebhookPing || contains(h.Events, event)
+}
+
+// WebhookDelivery is one entry in the delivery log, covering all attempts
+// made for one event.
+type WebhookDelivery struct {
+	ID         int    `json:"id,omitempty"`
+	WebhookID  int    `json:"webhook_id"`
+	EventID    *int   `json:"event_id"`
+	Event      string `json:"event"`
+	StatusCode int    `json:"status_code"`
+	Error      string `json:"error"`
+	Attempts   int    `json:"attempts"`
+	CreatedAt  string `json:"created_at,omitempty"`
+}
+
+// WebhookPayload is the JSON body posted for an event.
+type WebhookPayload struct {
+	Event     string `json:"event"`
+	EventID   int    `json:"event_id,omitempty"`
+	Board     *Board `json:"board,omitempty"`
+	Issue     *Issue `json:"issue,omitempty"`
+	UserID    string `json:"user_id,omitempty"`
+	From      string `json:"from,omitempty"`
+	To        string `json:"to,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+func ListWebhooks(client *supabase.Client, boardID int) ([]Webhook, error) {
+	var hooks []Webhook
+
+	_, err := client.From("webhooks").
+		Select("*", "", false).
+		Eq("board_id", strconv.Itoa(boardID)).
+		Order("id", &postgrest.OrderOpts{Ascending: true}).
+		ExecuteTo(&hooks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch webhooks: %w", err)
+	}
+
+	return hooks, nil
+}
+
+// CreateWebhook subscribes url to events on a board. A random secret is
+// generated when none is given. The webhook starts from the current end of
+// the event log so it is not flooded with history.
+func CreateWebhook(client *supabase.Client, boardID int, url string, events []string, secret string) (*Webhook, error) {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return nil, fmt.Errorf("webhook url must start with http:// or https://")
+	}
+	if len(events) == 0 {
+		events = WebhookEvents
+	}
+	for _, e := range events {
+		if !contains(WebhookEvents, e) {
+			return nil, fmt.Errorf("unknown event %q, expected one of %s", e, strings.Join(WebhookEvents, ", "))
+		}
+	}
+	if secret == "" {
+		buf := make([]byte, 24)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, fmt.Errorf("failed to generate secret: %w", err)
+		}
+		secret = "whsec_" + hex.EncodeToString(buf)
+	}
+
+	var latest []IssueEvent
+	_, err := client.From("issue_events").
+		Select("id", "", false).
+		Order("id", &postgrest.OrderOpts{Ascending: false}).
+		Limit(1, "").
+		ExecuteTo(&latest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch issue activity: %w", err)
+	}
+	lastEventID := 0
+	if len(latest) > 0 {
+		lastEventID = latest[0].ID
+	}
+
+	var hooks []Webhook
+
+	hookData := map[string]interface{}{
+		"board_id":      boardID,
+		"url":           url,
+		"events":        events,
+		"active":        true,
+		"last_event_id": lastEventID,
+	}
+
+	_, err = client.From("webhooks").
+		Insert([]map[string]interface{}{hookData}, false, "", "representation", "").
+		ExecuteTo(&hooks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	if len(hooks) == 0 {
+		return nil, fmt.Errorf("insert succeeded but no webhook returned")
+	}
+
+	// The secret cannot be read back, so it is written without asking for
+	// the row.
+	secretData := map[string]interface{}{"webhook_id": hooks[0].ID, "secret": secret}
+	_, _, err = client.From("webhook_secrets").
+		Insert([]map[string]interface{}{secretData}, false, "", "minimal", "").
+		Execute()
+	if err != nil {
+		DeleteWebhook(client, hooks[0].ID)
+		return nil, fmt.Errorf("failed to save webhook secret: %w", err)
+	}
+
+	hooks[0].Secret = secret
+	return &hooks[0], nil
+}
+
+// webhookSecret reads a webhook's signing secret. Only the service role may,
+// so it reports a missing secret rather than an empty one.
+func webhookSecret(client *supabase.Client, webhookID int) (string, error) {
+	var secrets []struct {
+		Secret string `json:"secret"`
+	}
+	_, err := client.From("webhook_secrets").
+		Select("secret", "", false).
+		Eq("webhook_id", strconv.Itoa(webhookID)).
+		ExecuteTo(&secrets)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch webhook secret: %w", err)
+	}
+	if len(secrets) == 0 {
+		return "", fmt.Errorf("no secret found for webhook %d; deliveries need the service role key", webhookID)
+	}
+	return secrets[0].Secret, nil
+}
+
+func DeleteWebhook(client *supabase.Client, id int) error {
+	_, _, err := client.From("webhooks").
+		Delete("minimal", "").
+		Eq("id", strconv.Itoa(id)).
+		Execute()
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	return nil
+}
+
+// ListWebhookDeliveries returns the most recent deliveries, newest first,
+// for one webhook or for all of them when webhookID is 0.
+func ListWebhookDeliveries(client *supabase.Client, webhookID, limit int) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+
+	q := client.From("webhook_deliveries").Select("*", "", false)
+	if webhookID != 0 {
+		q = q.Eq("webhook_id", strconv.Itoa(webhookID))
+	}
+	_, err := q.Order("id", &postgrest.OrderOpts{Ascending: false}).
+		Limit(limit, "").
+		ExecuteTo(&deliveries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// webhookEvent is an issue_events row with the issue it belongs to.
+type webhookEvent struct {
+	IssueEvent
+	Issue Issue `json:"issue"`
+}
+
+// DispatchWebhooks delivers the events recorded since each of the board's
+// active webhooks last ran, logging every delivery. Failed deliveries are
+// logged and skipped rather than retried forever, so one broken receiver
+// cannot hold up the rest. It returns the number of deliveries made.
+//
+// The client must use the service role key: only that role can read the
+// webhooks' secrets and write the delivery log.
+func DispatchWebhooks(ctx context.Context, client *supabase.Client, sender webhook.Sender, board Board) (int, error) {
+	hooks, err := ListWebhooks(client, board.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, h := range hooks {
+		if !h.Active {
+			continue
+		}
+		if h.Secret, err = webhookSecret(client, h.ID); err != nil {
+			return sent, err
+		}
+		var events []webhookEvent
+		_, err := client.From("issue_events").
+			Select("*,issue:issues!inner(*)", "", false).
+			Gt("id", strconv.Itoa(h.LastEventID)).
+			Eq("issue.board_id", strconv.Itoa(board.ID)).
+			Order("id", &postgrest.OrderOpts{Ascending: true}).
+			Limit(100, "").
+			ExecuteTo(&events)
+		if err != nil {
+			return sent, fmt.Errorf("failed to fetch issue activity: %w", err)
+		}
+
+		for _, e := range events {
+			if err := ctx.Err(); err != nil {
+				return sent, err
+			}
+			event, ok := webhookEventKinds[e.Kind]
+			if ok && h.Subscribed(event) {
+				issue := e.Issue
+				payload := WebhookPayload{
+					Event:     event,
+					EventID:   e.ID,
+					Board:     &board,
+					Issue:     &issue,
+					UserID:    e.UserID,
+					From:      e.FromValue,
+					To:        e.ToValue,
+					CreatedAt: e.CreatedAt,
+				}
+				if _, err := deliverWebhook(ctx, client, sender, h, payload); err != nil {
+					return sent, err
+				}
+				sent++
+			}
+			if err := advanceWebhook(client, h.ID, e.ID); err != nil {
+				return sent, err
+			}
+		}
+	}
+	return sent, nil
+}
+
+// PingWebhook sends a ping event so a new subscription can be checked end to
+// end. The delivery is logged like any other and returned.
+func PingWebhook(ctx context.Context, client *supabase.Client, sender webhook.Sender, h Webhook) (*WebhookDelivery, error) {
+	if h.Secret == "" {
+		secret, err := webhookSecret(client, h.ID)
+		if err != nil {
+			return nil, err
+		}
+		h.Secret = secret
+	}
+	payload := WebhookPayload{Event: WebhookPing, CreatedAt: time.Now().UTC().Format(time.RFC3339)}
+	return deliverWebhook(ctx, client, sender, h, payload)
+}
+
+func deliverWebhook(ctx context.Context, client *supabase.Client, sender webhook.Sender, h Webhook, payload WebhookPayload) (*WebhookDelivery, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+	id := fmt.Sprintf("%d-%d", h.ID, payload.EventID)
+	if payload.EventID == 0 {
+		id = fmt.Sprintf("%d-ping-%d", h.ID, time.Now().Unix())
+	}
+
+	attempts := sender.Send(ctx, h.URL, h.Secret, webhook.Delivery{ID: id, Event: payload.Event, Body: body})
+	last := attempts[len(attempts)-1]
+	deliveryData := map[string]interface{}{
+		"webhook_id":  h.ID,
+		"event":       payload.Event,
+		"status_code": last.StatusCode,
+		"attempts":    len(attempts),
+	}
+	if payload.EventID != 0 {
+		deliveryData["event_id"] = payload.EventID
+	}
+	if last.Err != nil {
+		deliveryData["error"] = last.Err.Error()
+	}
+
+	var deliveries []WebhookDelivery
+	_, err = client.From("webhook_deliveries").
+		Insert([]map[string]interface{}{deliveryData}, false, "", "representation", "").
+		ExecuteTo(&deliveries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to log webhook delivery: %w", err)
+	}
+
+	if len(deliveries) == 0 {
+		return nil, fmt.Errorf("insert succeeded but no delivery returned")
+	}
+
+	return &deliveries[0], nil
+}
+
+func advanceWebhook(client *supabase.Client, webhookID, eventID int) error {
+	_, _, err := client.From("webhooks").
+		Update(map[string]interface{}{"last_event_id": eventID}, "minimal", "").
+		Eq("id", strconv.Itoa(webhookID)).
+		Execute()
+	if err != nil {
+		return fmt.Errorf("failed to update webhook: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"zel/lo/supabase/supabasetest"
+	"zel/lo/webhook"
+)
+
+func TestDispatchWebhooksLogsDeliveries(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		events     []string
+		delivered  int
+		wantStatus float64
+		attempts   float64
+		wantError  string
+	}{
+		{"accepted", http.StatusNoContent, nil, 2, 204, 1, ""},
+		{"receiver failing", http.StatusInternalServerError, nil, 2, 500, 3, "receiver responded 500"},
+		{"rejected", http.StatusGone, nil, 2, 410, 1, "receiver responded 410"},
+		{"not subscribed", http.StatusNoContent, []string{WebhookCommentCreated}, 0, 0, 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var received []WebhookPayload
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.status != http.StatusNoContent {
+					w.WriteHeader(tt.status)
+					return
+				}
+				webhook.Receiver{Secret: "s3cret", Handle: func(event, _ string, body []byte) {
+					var p WebhookPayload
+					if err := json.Unmarshal(body, &p); err != nil {
+						t.Error(err)
+					}
+					mu.Lock()
+					received = append(received, p)
+					mu.Unlock()
+				}}.ServeHTTP(w, r)
+			}))
+			defer receiver.Close()
+
+			srv := supabasetest.NewServer()
+			defer srv.Close()
+			events := tt.events
+			if events == nil {
+				events = WebhookEvents
+			}
+			srv.Seed("webhooks", map[string]interface{}{
+				"id": 1, "board_id": 1, "url": receiver.URL, "events": events,
+				"active": true, "last_event_id": 4,
+			})
+			srv.Seed("webhook_secrets", map[string]interface{}{"webhook_id": 1, "secret": "s3cret"})
+			srv.Seed("issues", map[string]interface{}{"id": 7, "title": "Crash", "status": "open", "board_id": 1})
+			srv.Seed("issue_events",
+				map[string]interface{}{"id": 4, "issue_id": 7, "kind": "created"},
+				map[string]interface{}{"id": 5, "issue_id": 7, "kind": "status", "from_value": "open", "to_value": "in_progress"},
+				map[string]interface{}{"id": 6, "issue_id": 7, "kind": "updated"},
+			)
+
+			sender := webhook.Sender{Backoff: time.Millisecond}
+			sent, err := DispatchWebhooks(context.Background(), srv.Client(), sender, Board{ID: 1, Key: "ZEL"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sent != tt.delivered {
+				t.Errorf("sent = %d, want %d", sent, tt.delivered)
+			}
+
+			log := srv.Rows("webhook_deliveries")
+			if len(log) != tt.delivered {
+				t.Fatalf("logged %d deliveries, want %d", len(log), tt.delivered)
+			}
+			for i, d := range log {
+				if d["webhook_id"] != float64(1) || d["event_id"] != float64(5+i) {
+					t.Errorf("delivery %d = %v", i, d)
+				}
+				if d["status_code"] != tt.wantStatus || d["attempts"] != tt.attempts {
+					t.Errorf("delivery %d: status %v after %v attempts, want %v after %v", i, d["status_code"], d["attempts"], tt.wantStatus, tt.attempts)
+				}
+				if got, _ := d["error"].(string); !strings.HasPrefix(got, tt.wantError) || (tt.wantError == "") != (got == "") {
+					t.Errorf("delivery %d error = %q, want %q", i, got, tt.wantError)
+				}
+			}
+			if len(log) > 0 && log[0]["event"] != WebhookIssueStatusChanged {
+				t.Errorf("first delivery is %v, want %s", log[0]["event"], WebhookIssueStatusChanged)
+			}
+			if tt.status == http.StatusNoContent && len(received) > 0 {
+				if received[0].From != "open" || received[0].To != "in_progress" || received[0].EventID != 5 {
+					t.Errorf("payload = %+v", received[0])
+				}
+			}
+
+			// Failed or not, handled events are not sent again.
+			if cursor := srv.Rows("webhooks")[0]["last_event_id"]; cursor != float64(6) {
+				t.Errorf("last_event_id = %v, want 6", cursor)
+			}
+		})
+	}
+}
+
+func TestPingWebhook(t *testing.T) {
+	var got string
+	receiver := httptest.NewServer(webhook.Receiver{Secret: "s3cret", Handle: func(event, deliveryID string, _ []byte) {
+		got = event + " " + deliveryID
+	}})
+	defer receiver.Close()
+	srv := supabasetest.NewServer()
+	defer srv.Close()
+
+	srv.Seed("webhook_secrets", map[string]interface{}{"webhook_id": 3, "secret": "s3cret"})
+
+	h := Webhook{ID: 3, BoardID: 1, URL: receiver.URL, Active: true}
+	d, err := PingWebhook(context.Background(), srv.Client(), webhook.Sender{}, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.StatusCode != http.StatusNoContent || d.Attempts != 1 || d.Error != "" || d.EventID != nil {
+		t.Errorf("delivery = %+v", d)
+	}
+	if !strings.HasPrefix(got, "ping 3-ping-") {
+		t.Errorf("receiver got %q", got)
+	}
+}
+
+func TestCreateWebhookKeepsSecretApart(t *testing.T) {
+	srv := supabasetest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	h, err := CreateWebhook(client, 1, "https://example.com/hook", nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(h.Secret, "whsec_") {
+		t.Errorf("secret = %q, want a generated one", h.Secret)
+	}
+	if _, ok := srv.Rows("webhooks")[0]["secret"]; ok {
+		t.Error("secret was stored on the webhook row")
+	}
+	secrets := srv.Rows("webhook_secrets")
+	if len(secrets) != 1 || secrets[0]["webhook_id"] != float64(h.ID) || secrets[0]["secret"] != h.Secret {
+		t.Errorf("webhook_secrets = %v", secrets)
+	}
+
+	hooks, err := ListWebhooks(client, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hooks) != 1 || hooks[0].Secret != "" {
+		t.Errorf("listed webhooks = %+v", hooks)
+	}
+}
+
+func TestDispatchWebhooksNeedsSecret(t *testing.T) {
+	srv := supabasetest.NewServer()
+	defer srv.Close()
+	srv.Seed("webhooks", map[string]interface{}{
+		"id": 1, "board_id": 1, "url": "https://example.com/hook", "events": WebhookEvents, "active": true,
+	})
+
+	_, err := DispatchWebhooks(context.Background(), srv.Client(), webhook.Sender{}, Board{ID: 1, Key: "ZEL"})
+	if err == nil || !strings.Contains(err.Error(), "service role") {
+		t.Fatalf("err = %v, want a missing secret error", err)
+	}
+	if n := len(srv.Rows("webhook_deliveries")); n != 0 {
+		t.Errorf("logged %d deliveries without a secret", n)
+	}
+}
@@ -0,0 +1,160 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"zel/lo/supabase"
+)
+
+// Worklog is time a user spent on an issue. Durations are stored in whole
+// minutes.
+type Worklog struct {
+	ID        int    `json:"id,omitempty"`
+	IssueID   int    `json:"issue_id"`
+	UserID    string `json:"user_id"`
+	Minutes   int    `json:"minutes"`
+	Note      string `json:"note"`
+	LoggedOn  string `json:"logged_on"`
+	CreatedAt string `json:"created_at,omitempty"`
+}
+
+func (w Worklog) Duration() time.Duration {
+	return time.Duration(w.Minutes) * time.Minute
+}
+
+// LogWork records d against an issue on the given day. Durations are rounded
+// to the nearest minute, with a minimum of one.
+func LogWork(client *supabase.Client, issueID int, userID string, d time.Duration, note string, on time.Time) (*Worklog, error) {
+	if d <= 0 {
+		return nil, fmt.Errorf("duration must be positive")
+	}
+	minutes := int(d.Round(time.Minute) / time.Minute)
+	if minutes == 0 {
+		minutes = 1
+	}
+
+	var logs []Worklog
+
+	logData := map[string]interface{}{
+		"issue_id":  issueID,
+		"user_id":   userID,
+		"minutes":   minutes,
+		"note":      note,
+		"logged_on": on.Format(dateLayout),
+	}
+
+	_, err := client.From("worklogs").
+		Insert([]map[string]interface{}{logData}, false, "", "representation", "").
+		ExecuteTo(&logs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to log work: %w", err)
+	}
+
+	if len(logs) == 0 {
+		return nil, fmt.Errorf("insert succeeded but no worklog returned")
+	}
+
+	return &logs[0], nil
+}
+
+// ListWorklogs returns worklogs, newest first, for one issue or for every
+// issue when issueID is 0.
+func ListWorklogs(client *supabase.Client, issueID int) ([]Worklog, error) {
+	var logs []Worklog
+
+	builder := client.From("worklogs").Select("*", "", false)
+	if issueID != 0 {
+		builder = builder.Eq("issue_id", strconv.Itoa(issueID))
+	}
+	_, err := builder.Order("logged_on", nil).ExecuteTo(&logs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch worklogs: %w", err)
+	}
+
+	return logs, nil
+}
+
+type TimeTotals struct {
+	Total   time.Duration
+	ByIssue map[int]time.Duration
+	ByUser  map[string]time.Duration
+}
+
+// SumWorklogs totals worklogs per issue and per user.
+func SumWorklogs(logs []Worklog) TimeTotals {
+	totals := TimeTotals{ByIssue: map[int]time.Duration{}, ByUser: map[string]time.Duration{}}
+	for _, w := range logs {
+		totals.Total += w.Duration()
+		totals.ByIssue[w.IssueID] += w.Duration()
+		totals.ByUser[w.UserID] += w.Duration()
+	}
+	return totals
+}
+
+// SortedUsers returns the users in the totals, most time first.
+func (t TimeTotals) SortedUsers() []string {
+	users := make([]string, 0, len(t.ByUser))
+	for u := range t.ByUser {
+		users = append(users, u)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if t.ByUser[users[i]] != t.ByUser[users[j]] {
+			return t.ByUser[users[i]] > t.ByUser[users[j]]
+		}
+		return users[i] < users[j]
+	})
+	return users
+}
+
+// SetIssueEstimate sets an issue's estimate in story points; nil clears it.
+func SetIssueEstimate(client *supabase.Client, issueID int, points *float64) (*Issue, error) {
+	if err := checkEstimate(points); err != nil {
+		return nil, err
+	}
+
+	var issues []Issue
+	_, err := client.From("issues").
+		Update(map[string]interface{}{"estimate": points}, "representation", "").
+		Eq("id", strconv.Itoa(issueID)).
+		ExecuteTo(&issues)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set estimate: %w", err)
+	}
+	if len(issues) == 0 {
+		return nil, fmt.Errorf("issue #%d not found", issueID)
+	}
+	return &issues[0], nil
+}
+
+func checkEstimate(points *float64) error {
+	if points != nil && *points < 0 {
+		return fmt.Errorf("estimate cannot be negative")
+	}
+	return nil
+}
+
+// FormatDuration renders durations the way they are typed, e.g. 1h30m.
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	h, m := int(d.Hours()), int(d.Minutes())%60
+	switch {
+	case h == 0:
+		return fmt.Sprintf("%dm", m)
+	case m == 0:
+		return fmt.Sprintf("%dh", h)
+	}
+	return fmt.Sprintf("%dh%dm", h, m)
+}
+
+// ParseWorkDuration parses durations like 1h30m, 45m or 2h.
+func ParseWorkDuration(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid duration %q, expected something like 1h30m", s)
+	}
+	return d, nil
+}
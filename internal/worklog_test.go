@@ -0,0 +1,119 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"zel/lo/supabase/supabasetest"
+)
+
+func TestParseWorkDuration(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"1h30m", 90 * time.Minute, false},
+		{" 45m ", 45 * time.Minute, false},
+		{"2h", 2 * time.Hour, false},
+		{"90s", 90 * time.Second, false},
+		{"0m", 0, true},
+		{"-1h", 0, true},
+		{"1.5", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseWorkDuration(tt.in)
+		if tt.wantErr {
+			if err == nil || !strings.Contains(err.Error(), "invalid duration") {
+				t.Errorf("ParseWorkDuration(%q) error = %v", tt.in, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseWorkDuration(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want string
+	}{
+		{0, "0m"},
+		{45 * time.Minute, "45m"},
+		{2 * time.Hour, "2h"},
+		{90 * time.Minute, "1h30m"},
+		{59*time.Minute + 40*time.Second, "1h"},
+		{26*time.Hour + 5*time.Minute, "26h5m"},
+	}
+	for _, tt := range tests {
+		if got := FormatDuration(tt.in); got != tt.want {
+			t.Errorf("FormatDuration(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSumWorklogs(t *testing.T) {
+	totals := SumWorklogs([]Worklog{
+		{IssueID: 1, UserID: "ada", Minutes: 30},
+		{IssueID: 1, UserID: "bob", Minutes: 90},
+		{IssueID: 2, UserID: "ada", Minutes: 60},
+		{IssueID: 3, UserID: "cy", Minutes: 90},
+	})
+	if totals.Total != 270*time.Minute {
+		t.Errorf("Total = %v", totals.Total)
+	}
+	if totals.ByIssue[1] != 2*time.Hour || totals.ByIssue[2] != time.Hour || totals.ByIssue[3] != 90*time.Minute {
+		t.Errorf("ByIssue = %v", totals.ByIssue)
+	}
+	// Ties are broken by name.
+	if got := fmt.Sprint(totals.SortedUsers()); got != "[ada bob cy]" {
+		t.Errorf("SortedUsers() = %s", got)
+	}
+	if empty := SumWorklogs(nil); empty.Total != 0 || len(empty.SortedUsers()) != 0 {
+		t.Errorf("SumWorklogs(nil) = %+v", empty)
+	}
+}
+
+func TestLogWork(t *testing.T) {
+	on := time.Date(2026, 3, 2, 18, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in      time.Duration
+		want    int
+		wantErr string
+	}{
+		{90 * time.Minute, 90, ""},
+		{29 * time.Second, 1, ""},
+		{90 * time.Second, 2, ""},
+		{time.Hour + 29*time.Second, 60, ""},
+		{0, 0, "duration must be positive"},
+		{-time.Minute, 0, "duration must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in.String(), func(t *testing.T) {
+			srv := supabasetest.NewServer()
+			defer srv.Close()
+
+			w, err := LogWork(srv.Client(), 7, "ada", tt.in, "review", on)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("err = %v, want %q", err, tt.wantErr)
+				}
+				if n := len(srv.Rows("worklogs")); n != 0 {
+					t.Errorf("stored %d worklogs", n)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if w.Minutes != tt.want || w.IssueID != 7 || w.LoggedOn != "2026-03-02" || w.Note != "review" {
+				t.Errorf("worklog = %+v, want %d minutes", w, tt.want)
+			}
+		})
+	}
+}
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"zel/lo/internal"
 	"zel/lo/ui"
@@ -17,11 +19,54 @@ import (
 
 
 
-type CreateUserRequest struct {
-	Name string `json:"name"`
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "--local" {
+		if err := runLocal(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// .env is optional here; newClient insists on it for Supabase.
+	godotenv.Load()
+	switch backend := os.Getenv("ZELLO_BACKEND"); backend {
+	case "", backendSupabase:
+	case backendPostgres:
+		if err := runPostgres(); err != nil {
+			log.Fatal(err)
+		}
+		return
+	default:
+		log.Fatalf("unknown ZELLO_BACKEND %q, expected %s or %s", backend, backendSupabase, backendPostgres)
+	}
+
+	client := newClient()
+	userID := promptAuth(client)
+
+	// Launch the TUI (press Ctrl+C to quit)
+	if err := ui.Start(client, internal.NewSupabaseStore(client), userID, os.Getenv("ZELLO_BOARD")); err != nil {
+		log.Fatal(err)
+	}
+
+	
+	
+		
+	
+	
+
 }
 
-func main() {
+
+
+
+// newClient loads .env and creates the Supabase client, exiting on failure.
+func newClient() *supabase.Client {
 	err := godotenv.Load()
 	if err != nil {
 		log.Fatal("Error loading .env file")
@@ -29,15 +74,20 @@ func main() {
 
 	supabaseUrl := os.Getenv("SUPABASE_URL")
 	supabaseAnonKey := os.Getenv("SUPABASE_ANON_KEY")
-	
 
 	client, err := supabase.NewClient(supabaseUrl, supabaseAnonKey, &supabase.ClientOptions{})
 	if err != nil {
 		log.Fatal("Error creating Supabase client:", err)
 	}
+	return client
+}
+
+// promptAuth asks on stdin whether to sign in or create an account and
+// returns the authenticated user's ID.
+func promptAuth(client *supabase.Client) string {
 	var option string
 	var userID string
-	fmt.Println("press S to sign in and C to create account")
+	fmt.Println("press S to sign in, P to sign in with your phone, O to get a sign-in code by email, L to sign in with GitHub, Google or another provider, R to reset your password and C to create account")
 	fmt.Scanf("%s", &option)
 
 	if option == "s"{
@@ -51,8 +101,81 @@ func main() {
 		if err != nil {
 			log.Fatal("Error signing in:", err)
 		}
+		verifyMFA(client, session)
+		userID = session.User.ID.String()
+		fmt.Println("Successfully authenticated!")
+	} else if option == "p" {
+		var phone string
+		var password string
+		fmt.Println("enter your phone number (+14155550100):")
+		fmt.Scanf("%s", &phone)
+		if err := internal.ValidatePhone(phone); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("enter your password:")
+		fmt.Scanf("%s", &password)
+		session, err := client.SignInWithPhonePassword(phone, password)
+		if err != nil {
+			log.Fatal("Error signing in:", err)
+		}
+		verifyMFA(client, session)
+		userID = session.User.ID.String()
+		fmt.Println("Successfully authenticated!")
+	} else if option == "o" {
+		var email string
+		var code string
+		fmt.Println("enter your email:")
+		fmt.Scanf("%s", &email)
+		if err := internal.SendSignInCode(client, email); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("enter the code we emailed you:")
+		fmt.Scanf("%s", &code)
+		session, err := internal.SignInWithCode(client, email, code)
+		if err != nil {
+			log.Fatal("Error signing in:", err)
+		}
+		verifyMFA(client, session)
+		userID = session.User.ID.String()
+		fmt.Println("Successfully authenticated!")
+	} else if option == "l" {
+		var provider string
+		fmt.Println("enter the provider (github, google, ...):")
+		fmt.Scanf("%s", &provider)
+		ctx, cancel := context.WithTimeout(context.Background(), supabase.OAuthTimeout)
+		defer cancel()
+		session, err := client.SignInWithOAuth(ctx, supabase.OAuthOptions{Provider: authtypes.Provider(strings.ToLower(provider))}, func(url string) error {
+			fmt.Println("continue in your browser; if it does not open, visit:")
+			fmt.Println(url)
+			internal.OpenBrowser(url)
+			return nil
+		})
+		if err != nil {
+			log.Fatal("Error signing in:", err)
+		}
+		verifyMFA(client, session)
 		userID = session.User.ID.String()
 		fmt.Println("Successfully authenticated!")
+	} else if option == "r" {
+		var email string
+		var code string
+		var password string
+		fmt.Println("enter your email:")
+		fmt.Scanf("%s", &email)
+		if err := internal.SendPasswordReset(client, email); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("enter the code from the password reset email:")
+		fmt.Scanf("%s", &code)
+		fmt.Println("enter your new password:")
+		fmt.Scanf("%s", &password)
+		session, err := internal.ResetPassword(client, email, code, password)
+		if err != nil {
+			log.Fatal("Error resetting password:", err)
+		}
+		verifyMFA(client, session)
+		userID = session.User.ID.String()
+		fmt.Println("Password changed, you are signed in!")
 	} else if option == "c"{
 		var email string
 		var password string
@@ -68,8 +191,9 @@ func main() {
 		signupRequest := authtypes.SignupRequest{
 			Email:    email,
 			Password: password,
+			Data:     map[string]interface{}{"name": name},
 		}
-		_, err = client.Auth.Signup(signupRequest)
+		_, err := client.Auth.Signup(signupRequest)
 		if err != nil {
 			log.Fatal("Error creating account:", err)
 		}
@@ -83,49 +207,29 @@ func main() {
 		userID = session.User.ID.String()
 		
 		
-		newUserRequest := CreateUserRequest{Name: name}
-		user, err := CreateUser(client, newUserRequest)
+		profile, err := internal.CreateProfile(client, userID, name)
 		if err != nil {
-			log.Fatal("Error creating user record:", err)
+			log.Fatal("Error creating profile:", err)
 		}
-		fmt.Printf("User record created: ID=%d, Name=%s\n", user.ID, user.Name)
-	}
-
-
-	// Launch the TUI (press Ctrl+C to quit)
-	if err := ui.Start(client, userID); err != nil {
-		log.Fatal(err)
+		fmt.Printf("Profile created: %s\n", profile.DisplayName)
 	}
-
-	
-	
-		
-	
-	
-
+	return userID
 }
 
-
-
-
-func CreateUser(client *supabase.Client, userRequest CreateUserRequest) (*internal.User, error) {
-	var users []internal.User
-
-	
-	userData := map[string]interface{}{
-		"name": userRequest.Name,
+// verifyMFA asks for a code from the authenticator app when the account has
+// one and session has not been through it yet. ZELLO_TOTP_CODE answers
+// without prompting.
+func verifyMFA(client *supabase.Client, session authtypes.Session) {
+	factor := internal.PendingFactor(session)
+	if factor == nil {
+		return
 	}
-
-	_, err := client.From("users").
-		Insert([]map[string]interface{}{userData}, false, "", "*", "").
-		ExecuteTo(&users)
-	if err != nil {
-		return nil, fmt.Errorf("failed to insert user: %w", err)
+	code := os.Getenv("ZELLO_TOTP_CODE")
+	if code == "" {
+		fmt.Println("enter the code from your authenticator app:")
+		fmt.Scanf("%s", &code)
 	}
-
-	if len(users) == 0 {
-		return nil, fmt.Errorf("insert succeeded but no user returned")
+	if _, err := internal.VerifyMFA(client, factor.ID, code); err != nil {
+		log.Fatal("Error verifying code:", err)
 	}
-
-	return &users[0], nil
 }
@@ -0,0 +1,149 @@
+-- Tables and indexes. User ids are stored as text: on Supabase they are
+-- auth.users ids, on a self-hosted database ids from the users table.
+
+-- Supabase provides the auth schema, auth.uid() and the API roles. A plain
+-- Postgres database gets stand-ins so the policies and functions in later
+-- migrations apply there too.
+do $$
+begin
+  create schema if not exists auth;
+  if to_regprocedure('auth.uid()') is null then
+    create function auth.uid() returns uuid
+    language sql stable
+    as $f$ select nullif(current_setting('request.jwt.claim.sub', true), '')::uuid $f$;
+  end if;
+  if not exists (select 1 from pg_roles where rolname = 'anon') then
+    create role anon nologin;
+  end if;
+  if not exists (select 1 from pg_roles where rolname = 'authenticated') then
+    create role authenticated nologin;
+  end if;
+end
+$$;
+
+-- Display names on Supabase; local accounts with bcrypt password hashes on a
+-- self-hosted database (ZELLO_BACKEND=postgres).
+create table users (
+  id bigint generated by default as identity primary key,
+  name text not null default '',
+  email text unique,
+  password_hash text,
+  created_at timestamptz not null default now()
+);
+
+create table boards (
+  id bigint generated by default as identity primary key,
+  key text not null unique,
+  name text not null,
+  next_number integer not null default 1,
+  created_at timestamptz not null default now()
+);
+
+create table sprints (
+  id bigint generated by default as identity primary key,
+  board_id bigint not null references boards (id) on delete cascade,
+  name text not null,
+  start_date date not null,
+  end_date date not null,
+  goal text not null default '',
+  created_at timestamptz not null default now(),
+  check (end_date >= start_date)
+);
+create index sprints_board_id_idx on sprints (board_id, start_date);
+
+create table issues (
+  id bigint generated by default as identity primary key,
+  title text not null,
+  description text not null default '',
+  status text not null default 'open',
+  user_id text,
+  assignee_id text,
+  labels text[] not null default '{}',
+  parent_id bigint references issues (id) on delete set null,
+  board_id bigint references boards (id) on delete set null,
+  sprint_id bigint references sprints (id) on delete set null,
+  number integer,
+  estimate numeric,
+  external_id text unique,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  fts tsvector generated always as (
+    to_tsvector('english', title || ' ' || description)
+  ) stored
+);
+create unique index issues_board_number_key on issues (board_id, number);
+create index issues_status_idx on issues (status);
+create index issues_assignee_id_idx on issues (assignee_id);
+create index issues_parent_id_idx on issues (parent_id);
+create index issues_sprint_id_idx on issues (sprint_id);
+create index issues_labels_idx on issues using gin (labels);
+create index issues_fts_idx on issues using gin (fts);
+
+create table comments (
+  id bigint generated by default as identity primary key,
+  issue_id bigint not null references issues (id) on delete cascade,
+  user_id text,
+  body text not null,
+  external_id text unique,
+  created_at timestamptz not null default now(),
+  fts tsvector generated always as (to_tsvector('english', body)) stored
+);
+create index comments_issue_id_idx on comments (issue_id, created_at);
+create index comments_fts_idx on comments using gin (fts);
+
+create table issue_links (
+  id bigint generated by default as identity primary key,
+  source_id bigint not null references issues (id) on delete cascade,
+  target_id bigint not null references issues (id) on delete cascade,
+  kind text not null check (kind in ('blocks', 'duplicates', 'relates_to')),
+  created_at timestamptz not null default now(),
+  unique (source_id, target_id, kind),
+  check (source_id <> target_id)
+);
+create index issue_links_target_id_idx on issue_links (target_id);
+
+-- Activity history, written by the triggers in 0002_activity.sql.
+create table issue_events (
+  id bigint generated by default as identity primary key,
+  issue_id bigint not null references issues (id) on delete cascade,
+  user_id text,
+  kind text not null,
+  from_value text,
+  to_value text,
+  created_at timestamptz not null default now()
+);
+create index issue_events_issue_id_idx on issue_events (issue_id, kind);
+
+create table saved_views (
+  id bigint generated by default as identity primary key,
+  user_id text not null,
+  name text not null,
+  query text not null default '',
+  sort text not null default '',
+  columns text[] not null default '{}',
+  shared boolean not null default false,
+  created_at timestamptz not null default now(),
+  unique (user_id, name)
+);
+
+create table issue_templates (
+  id bigint generated by default as identity primary key,
+  board_id bigint not null references boards (id) on delete cascade,
+  name text not null,
+  title_prefix text not null default '',
+  labels text[] not null default '{}',
+  body text not null default '',
+  created_at timestamptz not null default now(),
+  unique (board_id, name)
+);
+
+create table worklogs (
+  id bigint generated by default as identity primary key,
+  issue_id bigint not null references issues (id) on delete cascade,
+  user_id text not null,
+  minutes integer not null check (minutes > 0),
+  note text not null default '',
+  logged_on date not null default current_date,
+  created_at timestamptz not null default now()
+);
+create index worklogs_issue_id_idx on worklogs (issue_id, logged_on);
@@ -0,0 +1,69 @@
+-- Keep issues.updated_at current and record issue activity in issue_events.
+-- Event kinds: created, updated (any change other than status), status
+-- (from_value and to_value hold the old and new status) and comment
+-- (to_value holds the comment id). Webhooks are dispatched from these rows.
+-- The trigger functions are security definer so they can write events that
+-- users cannot insert themselves.
+
+create function set_updated_at() returns trigger
+language plpgsql
+as $$
+begin
+  new.updated_at := now();
+  return new;
+end;
+$$;
+
+create trigger issues_set_updated_at
+before update on issues
+for each row execute function set_updated_at();
+
+create function record_issue_event() returns trigger
+language plpgsql
+security definer
+set search_path = public
+as $$
+declare
+  actor text := coalesce(auth.uid()::text, new.user_id);
+begin
+  if tg_op = 'INSERT' then
+    insert into issue_events (issue_id, user_id, kind, to_value)
+    values (new.id, actor, 'created', new.status);
+    return new;
+  end if;
+
+  if new.status is distinct from old.status then
+    insert into issue_events (issue_id, user_id, kind, from_value, to_value)
+    values (new.id, actor, 'status', old.status, new.status);
+  end if;
+  if (new.title, new.description, new.labels, new.assignee_id, new.parent_id,
+      new.board_id, new.sprint_id, new.estimate)
+     is distinct from
+     (old.title, old.description, old.labels, old.assignee_id, old.parent_id,
+      old.board_id, old.sprint_id, old.estimate) then
+    insert into issue_events (issue_id, user_id, kind)
+    values (new.id, actor, 'updated');
+  end if;
+  return new;
+end;
+$$;
+
+create trigger issues_record_event
+after insert or update on issues
+for each row execute function record_issue_event();
+
+create function record_comment_event() returns trigger
+language plpgsql
+security definer
+set search_path = public
+as $$
+begin
+  insert into issue_events (issue_id, user_id, kind, to_value)
+  values (new.issue_id, coalesce(auth.uid()::text, new.user_id), 'comment', new.id::text);
+  return new;
+end;
+$$;
+
+create trigger comments_record_event
+after insert on comments
+for each row execute function record_comment_event();
@@ -0,0 +1,111 @@
+-- Functions called through supabase.Rpc and by the create-issue and
+-- transition-issue edge functions. They run as the caller (security
+-- invoker), so row level security applies and counts only include rows the
+-- user can see.
+
+create or replace function create_board_issue(
+  p_board_id bigint,
+  p_title text,
+  p_description text,
+  p_status text,
+  p_parent_id bigint,
+  p_labels text[]
+) returns issues
+language plpgsql
+security invoker
+as $$
+declare
+  n integer;
+  result issues;
+begin
+  -- The row lock on the board serialises concurrent creators.
+  update boards set next_number = next_number + 1
+   where id = p_board_id
+  returning next_number - 1 into n;
+  if n is null then
+    raise exception 'board % not found', p_board_id using hint = 'not_found';
+  end if;
+
+  insert into issues (title, description, status, user_id, board_id, parent_id, labels, number)
+  values (p_title, coalesce(p_description, ''), coalesce(p_status, 'open'), auth.uid()::text, p_board_id, p_parent_id, coalesce(p_labels, '{}'), n)
+  returning * into result;
+  return result;
+end;
+$$;
+
+create or replace function transition_issue(
+  p_issue_id bigint,
+  p_status text,
+  p_force boolean,
+  p_comment text
+) returns jsonb
+language plpgsql
+security invoker
+as $$
+declare
+  closing boolean := p_status in ('closed', 'done');
+  blockers bigint[];
+  issue issues;
+  note comments;
+  open_siblings integer;
+begin
+  select * into issue from issues where id = p_issue_id for update;
+  if not found then
+    raise exception 'issue #% not found', p_issue_id using hint = 'not_found';
+  end if;
+
+  if closing and not p_force then
+    select array_agg(b.id order by b.id) into blockers
+      from issue_links l join issues b on b.id = l.source_id
+     where l.target_id = p_issue_id and l.kind = 'blocks'
+       and b.status not in ('closed', 'done');
+    if blockers is not null then
+      raise exception 'issue #% is blocked by open issues', p_issue_id
+        using hint = 'blocked', detail = to_jsonb(blockers)::text;
+    end if;
+  end if;
+
+  update issues set status = p_status where id = p_issue_id returning * into issue;
+
+  if p_comment is not null then
+    insert into comments (issue_id, user_id, body)
+    values (p_issue_id, auth.uid()::text, p_comment)
+    returning * into note;
+  end if;
+
+  if closing and issue.parent_id is not null then
+    select count(*) into open_siblings from issues
+     where parent_id = issue.parent_id and status not in ('closed', 'done');
+  end if;
+
+  return jsonb_strip_nulls(jsonb_build_object(
+    'issue', to_jsonb(issue),
+    'comment', case when p_comment is not null then to_jsonb(note) end,
+    'completed_parent', case when open_siblings = 0 then issue.parent_id end
+  ));
+end;
+$$;
+
+create or replace function issue_status_counts(p_board_id bigint default null)
+returns table (status text, count bigint)
+language sql
+stable
+security invoker
+as $$
+  select status, count(*)
+    from issues
+   where p_board_id is null or board_id = p_board_id
+   group by status;
+$$;
+
+create or replace function worklog_totals(p_issue_id bigint default null)
+returns table (issue_id bigint, user_id text, minutes bigint)
+language sql
+stable
+security invoker
+as $$
+  select issue_id, user_id, sum(minutes)
+    from worklogs
+   where p_issue_id is null or issue_id = p_issue_id
+   group by issue_id, user_id;
+$$;
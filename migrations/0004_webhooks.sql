@@ -0,0 +1,25 @@
+-- Outgoing webhooks. last_event_id is the dispatch cursor into issue_events.
+
+create table webhooks (
+  id bigint generated by default as identity primary key,
+  board_id bigint not null references boards (id) on delete cascade,
+  url text not null,
+  events text[] not null default '{}',
+  secret text not null,
+  active boolean not null default true,
+  last_event_id bigint not null default 0,
+  created_at timestamptz not null default now()
+);
+create index webhooks_board_id_idx on webhooks (board_id);
+
+create table webhook_deliveries (
+  id bigint generated by default as identity primary key,
+  webhook_id bigint not null references webhooks (id) on delete cascade,
+  event_id bigint references issue_events (id) on delete set null,
+  event text not null,
+  status_code integer not null default 0,
+  error text,
+  attempts integer not null default 1,
+  created_at timestamptz not null default now()
+);
+create index webhook_deliveries_webhook_id_idx on webhook_deliveries (webhook_id, created_at desc);
@@ -0,0 +1,69 @@
+-- Row level security for the Supabase API. zello is a team tool: every
+-- signed-in user can work on every board, while saved views are private
+-- unless shared and activity is written only by triggers. Connections as
+-- the table owner, as the self-hosted backend uses, are not affected.
+
+alter table users enable row level security;
+alter table boards enable row level security;
+alter table sprints enable row level security;
+alter table issues enable row level security;
+alter table comments enable row level security;
+alter table issue_links enable row level security;
+alter table issue_events enable row level security;
+alter table saved_views enable row level security;
+alter table issue_templates enable row level security;
+alter table worklogs enable row level security;
+alter table webhooks enable row level security;
+alter table webhook_deliveries enable row level security;
+
+-- Password hashes belong to self-hosted local accounts, which never go
+-- through the API, so users added there cannot carry one.
+create policy "Signed-in users can read users" on users
+  for select to authenticated using (true);
+create policy "Signed-in users can add users" on users
+  for insert to authenticated with check (password_hash is null);
+
+create policy "Team access" on boards
+  for all to authenticated using (true) with check (true);
+create policy "Team access" on sprints
+  for all to authenticated using (true) with check (true);
+create policy "Team access" on issues
+  for all to authenticated using (true) with check (true);
+create policy "Team access" on issue_links
+  for all to authenticated using (true) with check (true);
+create policy "Team access" on issue_templates
+  for all to authenticated using (true) with check (true);
+create policy "Team access" on webhooks
+  for all to authenticated using (true) with check (true);
+create policy "Team access" on webhook_deliveries
+  for all to authenticated using (true) with check (true);
+
+create policy "Signed-in users can read comments" on comments
+  for select to authenticated using (true);
+create policy "Users comment as themselves" on comments
+  for insert to authenticated with check (user_id = auth.uid()::text);
+create policy "Users edit their own comments" on comments
+  for update to authenticated using (user_id = auth.uid()::text);
+create policy "Users delete their own comments" on comments
+  for delete to authenticated using (user_id = auth.uid()::text);
+
+create policy "Signed-in users can read activity" on issue_events
+  for select to authenticated using (true);
+
+create policy "Users see their own and shared views" on saved_views
+  for select to authenticated using (user_id = auth.uid()::text or shared);
+create policy "Users manage their own views" on saved_views
+  for insert to authenticated with check (user_id = auth.uid()::text);
+create policy "Users update their own views" on saved_views
+  for update to authenticated using (user_id = auth.uid()::text);
+create policy "Users delete their own views" on saved_views
+  for delete to authenticated using (user_id = auth.uid()::text);
+
+create policy "Signed-in users can read worklogs" on worklogs
+  for select to authenticated using (true);
+create policy "Users log their own time" on worklogs
+  for insert to authenticated with check (user_id = auth.uid()::text);
+create policy "Users edit their own worklogs" on worklogs
+  for update to authenticated using (user_id = auth.uid()::text);
+create policy "Users delete their own worklogs" on worklogs
+  for delete to authenticated using (user_id = auth.uid()::text);
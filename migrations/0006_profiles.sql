@@ -0,0 +1,88 @@
+-- Profiles keyed by the account's UUID: auth.users.id on Supabase, users.uid
+-- for self-hosted local accounts. They replace users.name, which was never
+-- linked to an account. Avatars live in the public avatars bucket under
+-- <user id>/.
+
+create table profiles (
+  id uuid primary key,
+  display_name text not null default '',
+  avatar_url text not null default '',
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now()
+);
+
+create trigger profiles_set_updated_at
+before update on profiles
+for each row execute function set_updated_at();
+
+alter table profiles enable row level security;
+create policy "Signed-in users can read profiles" on profiles
+  for select to authenticated using (true);
+create policy "Users create their own profile" on profiles
+  for insert to authenticated with check (id = auth.uid());
+create policy "Users update their own profile" on profiles
+  for update to authenticated using (id = auth.uid());
+
+-- Local accounts get a UUID like Supabase accounts, and rows they own are
+-- moved over from the old integer ids. On Supabase user ids are already
+-- UUIDs and nothing matches.
+alter table users add column uid uuid not null default gen_random_uuid() unique;
+
+update issues set user_id = u.uid::text from users u where issues.user_id = u.id::text;
+update issues set assignee_id = u.uid::text from users u where issues.assignee_id = u.id::text;
+update comments set user_id = u.uid::text from users u where comments.user_id = u.id::text;
+update issue_events set user_id = u.uid::text from users u where issue_events.user_id = u.id::text;
+update saved_views set user_id = u.uid::text from users u where saved_views.user_id = u.id::text;
+update worklogs set user_id = u.uid::text from users u where worklogs.user_id = u.id::text;
+
+insert into profiles (id, display_name)
+select uid, name from users where password_hash is not null;
+
+-- On Supabase every new account gets a profile, named from the name in its
+-- signup metadata, however it was created.
+do $$
+begin
+  if to_regclass('auth.users') is null then
+    return;
+  end if;
+
+  alter table profiles
+    add constraint profiles_id_fkey foreign key (id) references auth.users (id) on delete cascade;
+
+  create function create_profile_for_user() returns trigger
+  language plpgsql
+  security definer
+  set search_path = public
+  as $f$
+  begin
+    insert into profiles (id, display_name)
+    values (new.id, coalesce(new.raw_user_meta_data ->> 'name', ''))
+    on conflict (id) do nothing;
+    return new;
+  end;
+  $f$;
+
+  create trigger on_auth_user_created
+  after insert on auth.users
+  for each row execute function create_profile_for_user();
+
+  insert into profiles (id, display_name)
+  select id, coalesce(raw_user_meta_data ->> 'name', '') from auth.users
+  on conflict (id) do nothing;
+
+  if to_regclass('storage.buckets') is null then
+    return;
+  end if;
+
+  insert into storage.buckets (id, name, public)
+  values ('avatars', 'avatars', true)
+  on conflict (id) do nothing;
+
+  create policy "Users upload their own avatar" on storage.objects
+    for insert to authenticated
+    with check (bucket_id = 'avatars' and (storage.foldername(name))[1] = auth.uid()::text);
+  create policy "Users replace their own avatar" on storage.objects
+    for update to authenticated
+    using (bucket_id = 'avatars' and (storage.foldername(name))[1] = auth.uid()::text);
+end
+$$;
@@ -0,0 +1,35 @@
+-- Lets a signed-in user delete their own Supabase account. GoTrue only
+-- deletes users through its admin API, which needs the service role key, so
+-- delete_account runs as its owner instead. The profile goes with the
+-- account through its foreign key and private saved views are dropped;
+-- issues, comments and worklogs stay under the old id.
+do $$
+begin
+  if to_regclass('auth.users') is null then
+    return;
+  end if;
+
+  create function delete_account() returns void
+  language plpgsql
+  security definer
+  set search_path = public
+  as $f$
+  begin
+    if auth.uid() is null then
+      raise exception 'not signed in';
+    end if;
+    delete from saved_views where user_id = auth.uid()::text and not shared;
+    delete from auth.users where id = auth.uid();
+  end;
+  $f$;
+
+  revoke execute on function delete_account() from public, anon;
+  grant execute on function delete_account() to authenticated;
+
+  if to_regclass('storage.objects') is not null then
+    create policy "Users remove their own avatar" on storage.objects
+      for delete to authenticated
+      using (bucket_id = 'avatars' and (storage.foldername(name))[1] = auth.uid()::text);
+  end if;
+end
+$$;
@@ -0,0 +1,42 @@
+-- Accounts from OAuth providers carry the provider's profile in their
+-- metadata. Depending on the provider the name is in name, full_name or
+-- user_name, and the picture in avatar_url. New profiles take both, and
+-- blank profiles of existing accounts are filled in.
+do $$
+begin
+  if to_regclass('auth.users') is null then
+    return;
+  end if;
+
+  create or replace function create_profile_for_user() returns trigger
+  language plpgsql
+  security definer
+  set search_path = public
+  as $f$
+  begin
+    insert into profiles (id, display_name, avatar_url)
+    values (
+      new.id,
+      coalesce(
+        nullif(new.raw_user_meta_data ->> 'name', ''),
+        nullif(new.raw_user_meta_data ->> 'full_name', ''),
+        nullif(new.raw_user_meta_data ->> 'user_name', ''),
+        ''),
+      coalesce(new.raw_user_meta_data ->> 'avatar_url', ''))
+    on conflict (id) do nothing;
+    return new;
+  end;
+  $f$;
+
+  update profiles p set display_name = coalesce(
+      nullif(u.raw_user_meta_data ->> 'full_name', ''),
+      nullif(u.raw_user_meta_data ->> 'user_name', ''),
+      '')
+  from auth.users u
+  where p.id = u.id and p.display_name = '';
+
+  update profiles p set avatar_url = u.raw_user_meta_data ->> 'avatar_url'
+  from auth.users u
+  where p.id = u.id and p.avatar_url = '' and u.raw_user_meta_data ? 'avatar_url';
+end
+$$;
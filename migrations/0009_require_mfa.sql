@@ -0,0 +1,39 @@
+-- Once a user has a verified authenticator app, a password alone no longer
+-- opens the board: their requests must carry an aal2 session, which they
+-- get by entering a code from the app after signing in. Users without an
+-- app are unaffected. mfa_satisfied reads auth.mfa_factors, which only
+-- its owner may, hence security definer.
+do $$
+declare
+  t text;
+begin
+  if to_regclass('auth.mfa_factors') is null then
+    return;
+  end if;
+
+  create function mfa_satisfied() returns boolean
+  language sql
+  stable
+  security definer
+  set search_path = public
+  as $f$
+    select coalesce(auth.jwt() ->> 'aal', 'aal1') = 'aal2'
+      or not exists (
+        select 1 from auth.mfa_factors
+        where user_id = auth.uid() and status = 'verified');
+  $f$;
+
+  revoke execute on function mfa_satisfied() from public, anon;
+  grant execute on function mfa_satisfied() to authenticated;
+
+  foreach t in array array[
+    'users', 'boards', 'sprints', 'issues', 'comments', 'issue_links',
+    'issue_events', 'saved_views', 'issue_templates', 'worklogs',
+    'webhooks', 'webhook_deliveries', 'profiles'
+  ] loop
+    execute format(
+      'create policy "Enrolled users need two-step sign-in" on %I as restrictive to authenticated using (mfa_satisfied()) with check (mfa_satisfied())',
+      t);
+  end loop;
+end
+$$;
@@ -0,0 +1,45 @@
+-- Ranks full-text search results in Postgres. Title matches weigh most,
+-- then the description, then comments; an issue's comment ranks are added
+-- to its own. Stemmed matches rank like exact ones, and at most p_limit
+-- issues come back. snippet is the best matching comment of issues that
+-- matched only through their comments.
+create or replace function search_issues(p_query text, p_limit integer default 50)
+returns table (issue jsonb, rank real, snippet text)
+language sql
+stable
+security invoker
+as $$
+  with q as (
+    select websearch_to_tsquery('english', p_query) as tsq
+  ),
+  issue_hits as (
+    select i.id,
+           ts_rank(setweight(to_tsvector('english', i.title), 'A') ||
+                   setweight(to_tsvector('english', i.description), 'B'), q.tsq) as rank
+      from issues i, q
+     where i.fts @@ q.tsq
+  ),
+  comment_hits as (
+    select c.issue_id as id, c.body,
+           ts_rank(setweight(c.fts, 'C'), q.tsq) as rank,
+           row_number() over (partition by c.issue_id
+                              order by ts_rank(c.fts, q.tsq) desc, c.id) as n
+      from comments c, q
+     where c.fts @@ q.tsq
+  ),
+  ranked as (
+    select id, sum(rank) as rank
+      from (select id, rank from issue_hits
+            union all
+            select id, rank from comment_hits) h
+     group by id
+     order by sum(rank) desc, id desc
+     limit greatest(p_limit, 1)
+  )
+  select to_jsonb(i), r.rank::real,
+         case when not exists (select 1 from issue_hits ih where ih.id = r.id) then ch.body end
+    from ranked r
+    join issues i on i.id = r.id
+    left join comment_hits ch on ch.id = r.id and ch.n = 1
+   order by r.rank desc, i.id desc;
+$$;
@@ -0,0 +1,5 @@
+-- Shared views belong to a board and are only listed on that board. Views
+-- shared before this have no board, so only their owners see them until
+-- they are saved again from a board.
+alter table saved_views add column board_id bigint references boards (id) on delete cascade;
+create index saved_views_board_id_idx on saved_views (board_id) where shared;
@@ -0,0 +1,101 @@
+-- Webhooks are managed by board admins, and their signing secrets never
+-- leave the database through the API. Secrets move to webhook_secrets,
+-- which admins may write but nobody signed in may read; zello webhook
+-- dispatch reads it, and writes the delivery log, as the service role.
+--
+-- Whoever creates a board becomes its first admin. Boards created before
+-- this have no admins until one is added with the service role, e.g.
+--
+--   insert into board_admins (board_id, user_id) values (1, '<user id>');
+
+create table board_admins (
+  board_id bigint not null references boards (id) on delete cascade,
+  user_id text not null,
+  created_at timestamptz not null default now(),
+  primary key (board_id, user_id)
+);
+
+-- Security definer so the policies on board_admins can call it without
+-- recursing into themselves.
+create function is_board_admin(board bigint) returns boolean
+language sql
+stable
+security definer
+set search_path = public
+as $f$
+  select exists (
+    select 1 from board_admins
+    where board_id = board and user_id = auth.uid()::text);
+$f$;
+
+create function add_board_creator() returns trigger
+language plpgsql
+security definer
+set search_path = public
+as $f$
+begin
+  if auth.uid() is not null then
+    insert into board_admins (board_id, user_id) values (new.id, auth.uid()::text);
+  end if;
+  return new;
+end;
+$f$;
+
+create trigger boards_add_creator after insert on boards
+  for each row execute function add_board_creator();
+
+create table webhook_secrets (
+  webhook_id bigint primary key references webhooks (id) on delete cascade,
+  secret text not null
+);
+insert into webhook_secrets (webhook_id, secret) select id, secret from webhooks;
+alter table webhooks drop column secret;
+
+alter table board_admins enable row level security;
+alter table webhook_secrets enable row level security;
+
+create policy "Signed-in users can read board admins" on board_admins
+  for select to authenticated using (true);
+create policy "Admins add admins" on board_admins
+  for insert to authenticated with check (is_board_admin(board_id));
+create policy "Admins remove admins" on board_admins
+  for delete to authenticated using (is_board_admin(board_id));
+
+drop policy "Team access" on webhooks;
+create policy "Signed-in users can read webhooks" on webhooks
+  for select to authenticated using (true);
+create policy "Admins add webhooks" on webhooks
+  for insert to authenticated with check (is_board_admin(board_id));
+create policy "Admins edit webhooks" on webhooks
+  for update to authenticated
+  using (is_board_admin(board_id)) with check (is_board_admin(board_id));
+create policy "Admins delete webhooks" on webhooks
+  for delete to authenticated using (is_board_admin(board_id));
+
+-- There is no select policy, so a secret cannot be read back even by the
+-- admin who wrote it.
+create policy "Admins set webhook secrets" on webhook_secrets
+  for insert to authenticated
+  with check (exists (
+    select 1 from webhooks w where w.id = webhook_id and is_board_admin(w.board_id)));
+revoke select, update, delete on webhook_secrets from anon, authenticated;
+
+drop policy "Team access" on webhook_deliveries;
+create policy "Signed-in users can read webhook deliveries" on webhook_deliveries
+  for select to authenticated using (true);
+revoke insert, update, delete on webhook_deliveries from anon, authenticated;
+
+do $$
+declare
+  t text;
+begin
+  if to_regprocedure('mfa_satisfied()') is null then
+    return;
+  end if;
+  foreach t in array array['board_admins', 'webhook_secrets'] loop
+    execute format(
+      'create policy "Enrolled users need two-step sign-in" on %I as restrictive to authenticated using (mfa_satisfied()) with check (mfa_satisfied())',
+      t);
+  end loop;
+end
+$$;
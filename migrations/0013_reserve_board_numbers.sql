@@ -0,0 +1,27 @@
+-- zello import numbers the issues it creates on a board with a block of
+-- numbers taken here in one statement, so issues created through
+-- create_board_issue meanwhile never share a number with them. Returns the
+-- first number of the block.
+
+create or replace function reserve_board_numbers(p_board_id bigint, p_count integer)
+returns integer
+language plpgsql
+security invoker
+as $$
+declare
+  first integer;
+begin
+  if p_count < 1 then
+    raise exception 'cannot reserve % numbers', p_count using hint = 'invalid';
+  end if;
+
+  -- The row lock on the board serialises this with concurrent creators.
+  update boards set next_number = next_number + p_count
+   where id = p_board_id
+  returning next_number - p_count into first;
+  if first is null then
+    raise exception 'board % not found', p_board_id using hint = 'not_found';
+  end if;
+  return first;
+end;
+$$;
@@ -0,0 +1,10 @@
+// Package migrations embeds the versioned SQL that creates and evolves the
+// zello schema. Files are named NNNN_description.sql and applied in version
+// order by zello db migrate; an applied file must never be edited, add a new
+// one instead.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -32,6 +32,7 @@ type Client struct {
 type clientOptions struct {
 	url     string
 	headers map[string]string
+	schema  string
 }
 
 type ClientOptions struct {
@@ -72,6 +73,7 @@ func NewClient(url, key string, options *ClientOptions) (*Client, error) {
 		schema = "public"
 	}
 
+	client.options.schema = schema
 	client.rest = postgrest.NewClient(url+REST_URL, schema, headers)
 	client.Storage = storage_go.NewClient(url+STORAGE_URL, key, headers)
 	client.Auth = auth.New(url, key).WithCustomAuthURL(url + AUTH_URL)
@@ -86,12 +88,6 @@ func (c *Client) From(table string) *postgrest.QueryBuilder {
 	return c.rest.From(table)
 }
 
-// Wrap postgrest Rpc method
-// Rpc returns a string for the specified function.
-func (c *Client) Rpc(name, count string, rpcBody interface{}) string {
-	return c.rest.Rpc(name, count, rpcBody)
-}
-
 func (c *Client) SignInWithEmailPassword(email, password string) (types.Session, error) {
 	resp, err := c.Auth.SignInWithEmailPassword(email, password)
 	if err != nil {
@@ -102,6 +98,14 @@ func (c *Client) SignInWithEmailPassword(email, password string) (types.Session,
 	return resp.Session, err
 }
 
+// VerifyPassword checks an email and password on a separate auth client,
+// leaving this client's session alone. Signing in again would swap an aal2
+// session for an aal1 one.
+func (c *Client) VerifyPassword(email, password string) error {
+	_, err := c.Auth.WithToken("").SignInWithEmailPassword(email, password)
+	return err
+}
+
 func (c *Client) SignInWithPhonePassword(phone, password string) (types.Session, error) {
 	resp, err := c.Auth.SignInWithPhonePassword(phone, password)
 	if err != nil {
@@ -111,6 +115,21 @@ func (c *Client) SignInWithPhonePassword(phone, password string) (types.Session,
 	return resp.Session, err
 }
 
+// VerifyOTP exchanges a one-time code from an email or SMS for a session.
+func (c *Client) VerifyOTP(req types.VerifyForUserRequest) (types.Session, error) {
+	// auth-go insists on a redirect, but GoTrue answers a POSTed code with
+	// the session itself and never redirects.
+	if req.RedirectTo == "" {
+		req.RedirectTo = c.options.url
+	}
+	resp, err := c.Auth.VerifyForUser(req)
+	if err != nil {
+		return types.Session{}, err
+	}
+	c.UpdateAuthSession(resp.Session)
+	return resp.Session, err
+}
+
 func (c *Client) EnableTokenAutoRefresh(session types.Session) {
 	go func() {
 		attempt := 0
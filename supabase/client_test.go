@@ -0,0 +1,174 @@
+package supabase_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"zel/lo/supabase"
+	"zel/lo/supabase/supabasetest"
+)
+
+func TestSignInAndRefresh(t *testing.T) {
+	srv := supabasetest.NewServer()
+	defer srv.Close()
+	userID := srv.AddUser("ada@example.com", "correct horse")
+
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		ok       bool
+	}{
+		{"right password", "ada@example.com", "correct horse", true},
+		{"wrong password", "ada@example.com", "battery staple", false},
+		{"unknown user", "bob@example.com", "correct horse", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := srv.Client()
+			session, err := client.SignInWithEmailPassword(tt.email, tt.password)
+			if !tt.ok {
+				if err == nil {
+					t.Error("signed in")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if session.User.ID.String() != userID {
+				t.Errorf("signed in as %s, want %s", session.User.ID, userID)
+			}
+			if got, ok := srv.UserForToken(session.AccessToken); !ok || got != userID {
+				t.Errorf("access token belongs to %q", got)
+			}
+			if current, _ := supabase.AssuranceLevel(session); current != supabase.AAL1 {
+				t.Errorf("assurance level = %s, want %s", current, supabase.AAL1)
+			}
+
+			refreshed, err := client.RefreshToken(session.RefreshToken)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if refreshed.AccessToken == session.AccessToken || refreshed.User.ID != session.User.ID {
+				t.Errorf("refresh returned %+v", refreshed)
+			}
+			// The client now sends the new token.
+			user, err := client.Auth.GetUser()
+			if err != nil || user.ID != session.User.ID {
+				t.Errorf("GetUser after refresh = %v, %v", user, err)
+			}
+			if _, err := client.RefreshToken(session.RefreshToken); err == nil {
+				t.Error("a refresh token was accepted twice")
+			}
+		})
+	}
+}
+
+func TestRpc(t *testing.T) {
+	srv := supabasetest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	srv.HandleRPC("add", func(p map[string]interface{}) (interface{}, error) {
+		return p["a"].(float64) + p["b"].(float64), nil
+	})
+	srv.HandleRPC("open_issues", func(p map[string]interface{}) (interface{}, error) {
+		return []map[string]interface{}{{"id": 1}, {"id": 2}, {"id": 3}}, nil
+	})
+	srv.HandleRPC("close_issue", func(p map[string]interface{}) (interface{}, error) {
+		return nil, &supabasetest.RPCError{Code: "P0001", Message: "issue #4 is blocked", Details: "blocked by #2", Hint: "blocked"}
+	})
+
+	t.Run("scalar", func(t *testing.T) {
+		sum, count, err := supabase.Rpc[float64](context.Background(), client, "add", map[string]int{"a": 2, "b": 3}, nil)
+		if err != nil || sum != 5 || count != -1 {
+			t.Errorf("add = %v, %d, %v", sum, count, err)
+		}
+	})
+
+	t.Run("set with count", func(t *testing.T) {
+		rows, count, err := supabase.Rpc[[]struct{ ID int }](context.Background(), client, "open_issues", nil, &supabase.RpcOptions{Count: "exact"})
+		if err != nil || len(rows) != 3 || rows[2].ID != 3 || count != 3 {
+			t.Errorf("open_issues = %v, %d, %v", rows, count, err)
+		}
+	})
+
+	tests := []struct {
+		name   string
+		fn     string
+		status int
+		code   string
+		hint   string
+	}{
+		{"raised error", "close_issue", http.StatusBadRequest, "P0001", "blocked"},
+		{"missing function", "nope", http.StatusNotFound, "PGRST202", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := supabase.Rpc[json.RawMessage](context.Background(), client, tt.fn, nil, nil)
+			var rpcErr *supabase.RpcError
+			if !errors.As(err, &rpcErr) {
+				t.Fatalf("err = %v, want an RpcError", err)
+			}
+			if rpcErr.Function != tt.fn || rpcErr.Status != tt.status || rpcErr.Code != tt.code || rpcErr.Hint != tt.hint {
+				t.Errorf("err = %+v", rpcErr)
+			}
+		})
+	}
+}
+
+func TestInvoke(t *testing.T) {
+	srv := supabasetest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	srv.HandleFunction("echo", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"echo": body["msg"]})
+	}))
+	srv.HandleFunction("fail", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"error":"issue is closed","code":"closed","issue_id":4}`))
+	}))
+	srv.HandleFunction("crash", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+
+	var out struct{ Echo string }
+	if err := client.Invoke(context.Background(), "echo", map[string]string{"msg": "hi"}, &out); err != nil || out.Echo != "hi" {
+		t.Errorf("echo = %+v, %v", out, err)
+	}
+	if err := client.Invoke(context.Background(), "echo", map[string]string{"msg": "hi"}, nil); err != nil {
+		t.Errorf("echo without output: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		fn      string
+		status  int
+		code    string
+		message string
+	}{
+		{"reported error", "fail", http.StatusConflict, "closed", "issue is closed"},
+		{"bare status", "crash", http.StatusBadGateway, "", "Bad Gateway"},
+		{"missing function", "nope", http.StatusNotFound, "not_found", "function not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := client.Invoke(context.Background(), tt.fn, struct{}{}, nil)
+			var fnErr *supabase.FunctionError
+			if !errors.As(err, &fnErr) {
+				t.Fatalf("err = %v, want a FunctionError", err)
+			}
+			if fnErr.Status != tt.status || fnErr.Code != tt.code || fnErr.Message != tt.message {
+				t.Errorf("err = %+v", fnErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package supabase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// httpClient sends the requests made by Invoke and Rpc. functions-go keeps
+// its invoke method private and postgrest-go's Rpc drops errors, so both are
+// called directly with the client's URL and session headers.
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// FunctionError is returned when an edge function responds with a non-2xx
+// status. Functions report failures as {"error": "...", "code": "..."}; any
+// other fields are kept in Details for callers that need them.
+type FunctionError struct {
+	Function string
+	Status   int
+	Code     string
+	Message  string
+	Details  json.RawMessage
+}
+
+func (e *FunctionError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("%s: %s (%s, status %d)", e.Function, e.Message, e.Code, e.Status)
+	}
+	return fmt.Sprintf("%s: %s (status %d)", e.Function, e.Message, e.Status)
+}
+
+// Invoke calls the edge function name with body encoded as JSON and decodes
+// the JSON response into out, which may be nil.
+func (c *Client) Invoke(ctx context.Context, name string, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s request: %w", name, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.options.url+FUNCTIONS_URL+"/"+name, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	for k, v := range c.options.headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to invoke %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s response: %w", name, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		fnErr := &FunctionError{Function: name, Status: resp.StatusCode, Details: data}
+		var decoded struct {
+			Error   string `json:"error"`
+			Message string `json:"message"`
+			Code    string `json:"code"`
+		}
+		if json.Unmarshal(data, &decoded) == nil {
+			fnErr.Code = decoded.Code
+			fnErr.Message = decoded.Error
+			if fnErr.Message == "" {
+				fnErr.Message = decoded.Message
+			}
+		}
+		if fnErr.Message == "" {
+			fnErr.Message = http.StatusText(resp.StatusCode)
+			if resp.Header.Get("x-relay-error") == "true" {
+				fnErr.Message = "function relay error"
+			}
+		}
+		return fnErr
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", name, err)
+	}
+	return nil
+}
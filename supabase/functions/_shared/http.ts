@@ -0,0 +1,49 @@
+// Helpers shared by the zello edge functions.
+import { createClient, SupabaseClient } from "https://esm.sh/@supabase/supabase-js@2";
+
+export function json(body: unknown, status = 200): Response {
+  return new Response(JSON.stringify(body), {
+    status,
+    headers: { "Content-Type": "application/json" },
+  });
+}
+
+// error responds in the shape supabase.FunctionError decodes:
+// {"error": message, "code": code, ...extra}.
+export function error(status: number, code: string, message: string, extra: Record<string, unknown> = {}): Response {
+  return json({ error: message, code, ...extra }, status);
+}
+
+// userClient acts as the caller, so row level security still applies to
+// everything the function reads or writes.
+export function userClient(req: Request): SupabaseClient {
+  return createClient(Deno.env.get("SUPABASE_URL")!, Deno.env.get("SUPABASE_ANON_KEY")!, {
+    global: { headers: { Authorization: req.headers.get("Authorization") ?? "" } },
+  });
+}
+
+// readJSON parses the request body, returning null when it is not a JSON
+// object.
+export async function readJSON(req: Request): Promise<Record<string, unknown> | null> {
+  if (req.method !== "POST") return null;
+  try {
+    const body = await req.json();
+    return body && typeof body === "object" && !Array.isArray(body) ? body : null;
+  } catch {
+    return null;
+  }
+}
+
+// fromPostgres maps errors raised by the SQL functions to responses. The
+// functions raise with a SQLSTATE and put the zello error code in HINT.
+export function fromPostgres(err: { code?: string; message: string; hint?: string; details?: string }): Response {
+  switch (err.hint) {
+    case "blocked":
+      return error(409, "blocked", err.message, { blockers: JSON.parse(err.details || "[]") });
+    case "not_found":
+      return error(404, "not_found", err.message);
+    case "invalid":
+      return error(400, "invalid", err.message);
+  }
+  return error(500, "internal", err.message);
+}
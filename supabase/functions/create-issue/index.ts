@@ -0,0 +1,32 @@
+// create-issue inserts an issue with the next number on its board. The
+// number is allocated by create_board_issue() in the same transaction as the
+// insert, so concurrent creators never share a number.
+//
+// Request:  {"title", "description"?, "status"?, "board_id", "parent_id"?, "labels"?}
+// Response: {"issue": {...}}
+import { error, fromPostgres, json, readJSON, userClient } from "../_shared/http.ts";
+
+Deno.serve(async (req) => {
+  const body = await readJSON(req);
+  if (!body) return error(400, "invalid", "expected a JSON object");
+
+  const title = typeof body.title === "string" ? body.title.trim() : "";
+  if (!title) return error(400, "invalid", "title is required");
+  if (typeof body.board_id !== "number") return error(400, "invalid", "board_id is required");
+
+  const supabase = userClient(req);
+  const { data: auth, error: authErr } = await supabase.auth.getUser();
+  if (authErr || !auth.user) return error(401, "unauthorized", "sign in required");
+
+  const { data, error: err } = await supabase.rpc("create_board_issue", {
+    p_board_id: body.board_id,
+    p_title: title,
+    p_description: typeof body.description === "string" ? body.description : "",
+    p_status: typeof body.status === "string" && body.status ? body.status : "open",
+    p_parent_id: typeof body.parent_id === "number" ? body.parent_id : null,
+    p_labels: Array.isArray(body.labels) ? body.labels : [],
+  });
+  if (err) return fromPostgres(err);
+
+  return json({ issue: data });
+});
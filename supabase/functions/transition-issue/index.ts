@@ -0,0 +1,32 @@
+// transition-issue moves an issue to a new status. transition_issue() checks
+// blockers, updates the status, adds the optional comment and reports
+// whether the parent's last open child was just closed, all in one
+// transaction.
+//
+// Request:  {"issue_id", "status", "force"?, "comment"?}
+// Response: {"issue": {...}, "comment"?: {...}, "completed_parent"?: id}
+// Errors:   409 {"code": "blocked", "blockers": [ids]} while blockers are open
+import { error, fromPostgres, json, readJSON, userClient } from "../_shared/http.ts";
+
+Deno.serve(async (req) => {
+  const body = await readJSON(req);
+  if (!body) return error(400, "invalid", "expected a JSON object");
+
+  if (typeof body.issue_id !== "number") return error(400, "invalid", "issue_id is required");
+  const status = typeof body.status === "string" ? body.status.trim() : "";
+  if (!status) return error(400, "invalid", "status is required");
+
+  const supabase = userClient(req);
+  const { data: auth, error: authErr } = await supabase.auth.getUser();
+  if (authErr || !auth.user) return error(401, "unauthorized", "sign in required");
+
+  const { data, error: err } = await supabase.rpc("transition_issue", {
+    p_issue_id: body.issue_id,
+    p_status: status,
+    p_force: body.force === true,
+    p_comment: typeof body.comment === "string" && body.comment.trim() ? body.comment.trim() : null,
+  });
+  if (err) return fromPostgres(err);
+
+  return json(data);
+});
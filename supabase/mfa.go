@@ -0,0 +1,63 @@
+package supabase
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/supabase-community/auth-go/types"
+)
+
+// Authenticator assurance levels: AAL1 after a password, code or provider
+// sign-in, AAL2 once a second factor has been verified as well.
+const (
+	AAL1 = "aal1"
+	AAL2 = "aal2"
+)
+
+// AssuranceLevel reports the level a session was issued at and the level
+// its user can reach. When next is above current, the user has a verified
+// factor that still has to be challenged with VerifyFactor.
+func AssuranceLevel(session types.Session) (current, next string) {
+	current = AAL1
+	if parts := strings.Split(session.AccessToken, "."); len(parts) == 3 {
+		var claims struct {
+			AAL string `json:"aal"`
+		}
+		if payload, err := base64.RawURLEncoding.DecodeString(parts[1]); err == nil {
+			if json.Unmarshal(payload, &claims) == nil && claims.AAL != "" {
+				current = claims.AAL
+			}
+		}
+	}
+	next = AAL1
+	for _, f := range session.User.Factors {
+		if f.Status == "verified" {
+			next = AAL2
+			break
+		}
+	}
+	return current, next
+}
+
+// VerifyFactor challenges an enrolled factor and answers the challenge with
+// code, upgrading the session to AAL2. It also completes an enrolment, as a
+// new factor counts once its first code checks out.
+func (c *Client) VerifyFactor(factorID uuid.UUID, code string) (types.Session, error) {
+	challenge, err := c.Auth.ChallengeFactor(types.ChallengeFactorRequest{FactorID: factorID})
+	if err != nil {
+		return types.Session{}, fmt.Errorf("failed to challenge factor: %w", err)
+	}
+	resp, err := c.Auth.VerifyFactor(types.VerifyFactorRequest{
+		FactorID:    factorID,
+		ChallengeID: challenge.ID,
+		Code:        code,
+	})
+	if err != nil {
+		return types.Session{}, err
+	}
+	c.UpdateAuthSession(resp.Session)
+	return resp.Session, nil
+}
@@ -0,0 +1,170 @@
+package supabase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/supabase-community/auth-go/types"
+)
+
+// OAuthCallbackPath is where the loopback listener receives the redirect
+// back from GoTrue. Add http://127.0.0.1:*/auth/callback to the project's
+// allowed redirect URLs.
+const OAuthCallbackPath = "/auth/callback"
+
+// OAuthTimeout is how long an OAuth sign-in should wait for the browser.
+const OAuthTimeout = 5 * time.Minute
+
+const oauthDonePage = `<!doctype html>
+<html><body style="font-family: sans-serif; text-align: center; margin-top: 4em">
+<h2>%s</h2><p>You can close this tab and return to zello.</p>
+</body></html>`
+
+// OAuthOptions choose the identity provider for SignInWithOAuth.
+type OAuthOptions struct {
+	Provider types.Provider
+	// Scopes are extra provider scopes, space separated, added to the
+	// ones GoTrue asks the provider for by default.
+	Scopes string
+}
+
+// OAuthFlow is a PKCE sign-in waiting for the browser to come back to the
+// loopback listener. Open URL in a browser, then call Wait.
+type OAuthFlow struct {
+	URL string
+
+	client   *Client
+	verifier string
+	server   *http.Server
+	result   chan oauthResult
+	once     sync.Once
+}
+
+type oauthResult struct {
+	code string
+	err  error
+}
+
+// StartOAuth begins a PKCE sign-in with an external provider: it listens
+// on a free localhost port and asks GoTrue for the provider's authorize
+// URL with that port as the redirect. Close the flow if Wait is never
+// called.
+func (c *Client) StartOAuth(opts OAuthOptions) (*OAuthFlow, error) {
+	if opts.Provider == "" {
+		return nil, errors.New("provider required")
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for the OAuth callback: %w", err)
+	}
+	redirect := fmt.Sprintf("http://%s%s", ln.Addr(), OAuthCallbackPath)
+
+	resp, err := c.Auth.Authorize(types.AuthorizeRequest{
+		Provider:   opts.Provider,
+		RedirectTo: redirect,
+		FlowType:   types.FlowPKCE,
+		Scopes:     opts.Scopes,
+	})
+	if err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("failed to start %s sign-in: %w", opts.Provider, err)
+	}
+
+	f := &OAuthFlow{
+		URL:      resp.AuthorizationURL,
+		client:   c,
+		verifier: resp.Verifier,
+		result:   make(chan oauthResult, 1),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(OAuthCallbackPath, f.callback)
+	f.server = &http.Server{Handler: mux}
+	go f.server.Serve(ln)
+	return f, nil
+}
+
+// callback receives the browser after GoTrue redirects it back, with
+// either a code or an error.
+func (f *OAuthFlow) callback(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var res oauthResult
+	switch {
+	case q.Get("error") != "":
+		msg := q.Get("error_description")
+		if msg == "" {
+			msg = q.Get("error")
+		}
+		res.err = fmt.Errorf("sign-in was not completed: %s", msg)
+	case q.Get("code") == "":
+		res.err = errors.New("sign-in was not completed: no code in the callback")
+	default:
+		res.code = q.Get("code")
+	}
+
+	title := "Signed in"
+	if res.err != nil {
+		title = "Sign-in failed"
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, oauthDonePage, title)
+
+	// Only the first callback counts; a reload must not block.
+	select {
+	case f.result <- res:
+	default:
+	}
+}
+
+// Wait blocks until the browser comes back or ctx ends, then exchanges the
+// code for a session and signs the client in with it. The listener is
+// closed either way.
+func (f *OAuthFlow) Wait(ctx context.Context) (types.Session, error) {
+	defer f.Close()
+
+	var res oauthResult
+	select {
+	case res = <-f.result:
+	case <-ctx.Done():
+		return types.Session{}, fmt.Errorf("no answer from the browser: %w", ctx.Err())
+	}
+	if res.err != nil {
+		return types.Session{}, res.err
+	}
+
+	resp, err := f.client.Auth.Token(types.TokenRequest{
+		GrantType:    "pkce",
+		Code:         res.code,
+		CodeVerifier: f.verifier,
+	})
+	if err != nil {
+		return types.Session{}, fmt.Errorf("failed to exchange the sign-in code: %w", err)
+	}
+	f.client.UpdateAuthSession(resp.Session)
+	return resp.Session, nil
+}
+
+// Close stops the loopback listener.
+func (f *OAuthFlow) Close() error {
+	var err error
+	f.once.Do(func() { err = f.server.Close() })
+	return err
+}
+
+// SignInWithOAuth runs a whole PKCE sign-in, handing the authorize URL to
+// open, which typically launches a browser or prints it.
+func (c *Client) SignInWithOAuth(ctx context.Context, opts OAuthOptions, open func(url string) error) (types.Session, error) {
+	f, err := c.StartOAuth(opts)
+	if err != nil {
+		return types.Session{}, err
+	}
+	if err := open(f.URL); err != nil {
+		f.Close()
+		return types.Session{}, err
+	}
+	return f.Wait(ctx)
+}
@@ -0,0 +1,88 @@
+package supabase_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/supabase-community/auth-go/types"
+
+	"zel/lo/supabase"
+	"zel/lo/supabase/supabasetest"
+)
+
+// visit plays the browser: it follows the authorize URL through the
+// provider and back to the loopback callback.
+func visit(url string) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func TestSignInWithOAuth(t *testing.T) {
+	srv := supabasetest.NewServer()
+	defer srv.Close()
+	userID := srv.AddOAuthUser("github", "ada@example.com")
+	client := srv.Client()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	session, err := client.SignInWithOAuth(ctx, supabase.OAuthOptions{Provider: types.ProviderGitHub}, visit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.User.ID.String() != userID || session.User.Email != "ada@example.com" {
+		t.Errorf("signed in as %s <%s>, want %s", session.User.ID, session.User.Email, userID)
+	}
+	if id, ok := srv.UserForToken(session.AccessToken); !ok || id != userID {
+		t.Errorf("access token belongs to %q (%v)", id, ok)
+	}
+	// The client uses the new session.
+	resp, err := client.Auth.GetUser()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.User.ID.String() != userID {
+		t.Errorf("client is signed in as %s, want %s", resp.User.ID, userID)
+	}
+}
+
+func TestSignInWithOAuthDenied(t *testing.T) {
+	srv := supabasetest.NewServer()
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// No user for the provider, so it sends the browser back with an error.
+	_, err := srv.Client().SignInWithOAuth(ctx, supabase.OAuthOptions{Provider: types.ProviderGitHub}, visit)
+	if err == nil || !strings.Contains(err.Error(), "denied access") {
+		t.Fatalf("err = %v, want the provider's error", err)
+	}
+}
+
+func TestSignInWithOAuthTimeout(t *testing.T) {
+	srv := supabasetest.NewServer()
+	defer srv.Close()
+	srv.AddOAuthUser("github", "ada@example.com")
+
+	var authorizeURL string
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	// The browser never comes back.
+	_, err := srv.Client().SignInWithOAuth(ctx, supabase.OAuthOptions{Provider: types.ProviderGitHub}, func(url string) error {
+		authorizeURL = url
+		return nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want the context deadline", err)
+	}
+	// The loopback listener is gone, so a late browser gets nowhere.
+	if visit(authorizeURL) == nil {
+		t.Error("the callback listener still answers after the timeout")
+	}
+}
@@ -0,0 +1,122 @@
+package supabase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// RpcOptions tune a Postgres function call.
+type RpcOptions struct {
+	// Count asks PostgREST to count the rows a set-returning function
+	// produces: "exact", "planned" or "estimated". Empty skips counting.
+	Count string
+}
+
+// RpcError is a PostgREST error response for a function call.
+type RpcError struct {
+	Function string
+	Status   int
+	Code     string
+	Message  string
+	Details  string
+	Hint     string
+}
+
+func (e *RpcError) Error() string {
+	msg := fmt.Sprintf("%s: %s", e.Function, e.Message)
+	if e.Code != "" {
+		msg += " (" + e.Code + ")"
+	}
+	if e.Details != "" {
+		msg += ": " + e.Details
+	}
+	return msg
+}
+
+// Rpc calls the Postgres function name with params encoded as its named
+// arguments and decodes the result into T. Scalar functions decode into a
+// scalar T, set-returning functions into a slice. The count is the total
+// reported by PostgREST when opts.Count is set, and -1 otherwise.
+func Rpc[T any](ctx context.Context, c *Client, name string, params interface{}, opts *RpcOptions) (T, int64, error) {
+	var result T
+
+	if params == nil {
+		params = struct{}{}
+	}
+	body, err := json.Marshal(params)
+	if err != nil {
+		return result, -1, fmt.Errorf("failed to encode %s params: %w", name, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.options.url+REST_URL+"/rpc/"+name, bytes.NewReader(body))
+	if err != nil {
+		return result, -1, err
+	}
+	for k, v := range c.options.headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if c.options.schema != "" && c.options.schema != "public" {
+		req.Header.Set("Content-Profile", c.options.schema)
+		req.Header.Set("Accept-Profile", c.options.schema)
+	}
+	if opts != nil && opts.Count != "" {
+		req.Header.Set("Prefer", "count="+opts.Count)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return result, -1, fmt.Errorf("failed to call %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return result, -1, fmt.Errorf("failed to read %s response: %w", name, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		rpcErr := &RpcError{Function: name, Status: resp.StatusCode}
+		var decoded struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+			Details string `json:"details"`
+			Hint    string `json:"hint"`
+		}
+		if json.Unmarshal(data, &decoded) == nil {
+			rpcErr.Code, rpcErr.Message, rpcErr.Details, rpcErr.Hint = decoded.Code, decoded.Message, decoded.Details, decoded.Hint
+		}
+		if rpcErr.Message == "" {
+			rpcErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return result, -1, rpcErr
+	}
+
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &result); err != nil {
+			return result, -1, fmt.Errorf("failed to decode %s result: %w", name, err)
+		}
+	}
+	return result, contentRangeTotal(resp.Header.Get("Content-Range")), nil
+}
+
+// contentRangeTotal parses the total from a Content-Range header such as
+// "0-24/310" or "*/0", returning -1 when it is missing or unknown.
+func contentRangeTotal(header string) int64 {
+	_, total, ok := strings.Cut(header, "/")
+	if !ok || total == "*" {
+		return -1
+	}
+	n, err := strconv.ParseInt(total, 10, 64)
+	if err != nil {
+		return -1
+	}
+	return n
+}
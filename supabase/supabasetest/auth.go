@@ -0,0 +1,701 @@
+package supabasetest
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"zel/lo/supabase"
+)
+
+// tokenLifetime is the expires_in of issued sessions.
+const tokenLifetime = time.Hour
+
+// challengeLifetime is how long an MFA challenge can be answered.
+const challengeLifetime = 5 * time.Minute
+
+// oauthProviderPath is the stand-in provider /authorize redirects to. It
+// sits outside the API key check as only a browser visits it.
+const oauthProviderPath = "/oauth-provider/authorize"
+
+type fakeUser struct {
+	ID        string                 `json:"id"`
+	Aud       string                 `json:"aud"`
+	Role      string                 `json:"role"`
+	Email     string                 `json:"email"`
+	Phone     string                 `json:"phone"`
+	Metadata  map[string]interface{} `json:"user_metadata"`
+	CreatedAt time.Time              `json:"created_at"`
+	UpdatedAt time.Time              `json:"updated_at"`
+	// An email change waits here until ConfirmEmailChange.
+	NewEmail          string        `json:"new_email,omitempty"`
+	EmailChangeSentAt *time.Time    `json:"email_change_sent_at,omitempty"`
+	Factors           []*fakeFactor `json:"factors,omitempty"`
+	password          string
+}
+
+// fakeFactor is an enrolled TOTP authenticator app.
+type fakeFactor struct {
+	ID           string    `json:"id"`
+	FriendlyName string    `json:"friendly_name,omitempty"`
+	FactorType   string    `json:"factor_type"`
+	Status       string    `json:"status"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	secret       string
+}
+
+// mfaChallenge is an open challenge on a factor.
+type mfaChallenge struct {
+	factorID  string
+	expiresAt time.Time
+}
+
+type authState struct {
+	users      map[string]*fakeUser    // by id
+	access     map[string]grant        // access token -> session
+	refresh    map[string]grant        // refresh token -> session
+	codes      map[string]sentCode     // email -> last one-time code mailed
+	oauth      map[string]string       // provider -> user id signing in with it
+	challenges map[string]mfaChallenge // by id
+	flows      []*oauthFlow
+}
+
+// grant is who a token was issued to and at which assurance level.
+type grant struct {
+	userID, aal string
+}
+
+// oauthFlow is a PKCE authorization from /authorize until its code is
+// exchanged at /token.
+type oauthFlow struct {
+	id, provider, challenge, redirect string
+	userID, code                      string
+}
+
+// sentCode is a one-time code and the verification type it is good for.
+type sentCode struct {
+	code, kind string
+}
+
+func newAuthState() authState {
+	return authState{
+		users:      map[string]*fakeUser{},
+		access:     map[string]grant{},
+		refresh:    map[string]grant{},
+		codes:      map[string]sentCode{},
+		oauth:      map[string]string{},
+		challenges: map[string]mfaChallenge{},
+	}
+}
+
+// authError is GoTrue's error body.
+type authError struct {
+	Code      int    `json:"code"`
+	ErrorCode string `json:"error_code"`
+	Msg       string `json:"msg"`
+}
+
+// AddUser registers a confirmed user and returns their id.
+func (s *Server) AddUser(email, password string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addUser(email, "", password, nil).ID
+}
+
+// UserForToken returns the id of the user an access token was issued to.
+func (s *Server) UserForToken(token string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	g, ok := s.auth.access[token]
+	return g.userID, ok
+}
+
+// AddTOTPFactor gives a user a verified authenticator app, so signing in
+// takes a code from TOTPCode as well, and returns the app's secret.
+func (s *Server) AddTOTPFactor(userID, name string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := s.auth.users[userID]
+	f := s.addFactor(u, name)
+	f.Status = "verified"
+	return f.secret
+}
+
+// TOTPCode returns the code an authenticator app shows for secret at the
+// server's current time.
+func (s *Server) TOTPCode(secret string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return totp(secret, s.now())
+}
+
+// SentCode returns the one-time code last mailed to email by a password
+// reset or a passwordless sign-in, standing in for the user's inbox.
+func (s *Server) SentCode(email string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.auth.codes[strings.ToLower(email)]
+	return c.code, ok
+}
+
+// ConfirmEmailChange completes a pending email change as if the user had
+// followed the confirmation link, reporting whether one was pending.
+func (s *Server) ConfirmEmailChange(userID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.auth.users[userID]
+	if !ok || u.NewEmail == "" {
+		return false
+	}
+	u.Email, u.NewEmail, u.EmailChangeSentAt = u.NewEmail, "", nil
+	return true
+}
+
+// AddOAuthUser makes provider sign in as the user with email, creating the
+// user if needed, and returns their id. Providers without a user deny
+// every sign-in.
+func (s *Server) AddOAuthUser(provider, email string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := s.findUser(email, "")
+	if u == nil {
+		u = s.addUser(email, "", "", map[string]interface{}{"name": email, "provider": provider})
+	}
+	s.auth.oauth[provider] = u.ID
+	return u.ID
+}
+
+func (s *Server) findFlow(match func(*oauthFlow) bool) (int, *oauthFlow) {
+	for i, f := range s.auth.flows {
+		if match(f) {
+			return i, f
+		}
+	}
+	return -1, nil
+}
+
+// ExpireTokens invalidates every access token so clients must refresh.
+func (s *Server) ExpireTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.auth.access = map[string]grant{}
+}
+
+func (s *Server) addUser(email, phone, password string, data map[string]interface{}) *fakeUser {
+	now := s.now().UTC()
+	u := &fakeUser{
+		ID:        newUUID(),
+		Aud:       "authenticated",
+		Role:      "authenticated",
+		Email:     email,
+		Phone:     phone,
+		Metadata:  data,
+		CreatedAt: now,
+		UpdatedAt: now,
+		password:  password,
+	}
+	if u.Metadata == nil {
+		u.Metadata = map[string]interface{}{}
+	}
+	s.auth.users[u.ID] = u
+	return u
+}
+
+func (s *Server) findUser(email, phone string) *fakeUser {
+	for _, u := range s.auth.users {
+		if (email != "" && strings.EqualFold(u.Email, email)) || (phone != "" && u.Phone == phone) {
+			return u
+		}
+	}
+	return nil
+}
+
+func (s *Server) serveAuth(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, supabase.AUTH_URL)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case path == "/signup" && r.Method == http.MethodPost:
+		var req struct {
+			Email    string                 `json:"email"`
+			Phone    string                 `json:"phone"`
+			Password string                 `json:"password"`
+			Data     map[string]interface{} `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, authError{400, "bad_json", err.Error()})
+			return
+		}
+		if (req.Email == "" && req.Phone == "") || len(req.Password) < 6 {
+			writeJSON(w, http.StatusUnprocessableEntity, authError{422, "weak_password", "Password should be at least 6 characters."})
+			return
+		}
+		if s.findUser(req.Email, req.Phone) != nil {
+			writeJSON(w, http.StatusUnprocessableEntity, authError{422, "user_already_exists", "User already registered"})
+			return
+		}
+		u := s.addUser(req.Email, req.Phone, req.Password, req.Data)
+		// With autoconfirm on, GoTrue answers with a session; the user
+		// fields are repeated at the top level as auth-go's SignupResponse
+		// embeds both.
+		body := s.sessionBody(u, "aal1")
+		userFields, _ := json.Marshal(u)
+		json.Unmarshal(userFields, &body)
+		writeJSON(w, http.StatusOK, body)
+
+	case path == "/token" && r.Method == http.MethodPost:
+		var req struct {
+			Email        string `json:"email"`
+			Phone        string `json:"phone"`
+			Password     string `json:"password"`
+			RefreshToken string `json:"refresh_token"`
+			AuthCode     string `json:"auth_code"`
+			CodeVerifier string `json:"code_verifier"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, authError{400, "bad_json", err.Error()})
+			return
+		}
+		switch r.URL.Query().Get("grant_type") {
+		case "password":
+			u := s.findUser(req.Email, req.Phone)
+			if u == nil || u.password == "" || u.password != req.Password {
+				writeJSON(w, http.StatusBadRequest, authError{400, "invalid_credentials", "Invalid login credentials"})
+				return
+			}
+			writeJSON(w, http.StatusOK, s.sessionBody(u, "aal1"))
+		case "pkce":
+			i, f := s.findFlow(func(f *oauthFlow) bool { return f.code != "" && f.code == req.AuthCode })
+			if f == nil {
+				writeJSON(w, http.StatusNotFound, authError{404, "flow_state_not_found", "invalid flow state, no valid flow state found"})
+				return
+			}
+			sum := sha256.Sum256([]byte(req.CodeVerifier))
+			if base64.RawURLEncoding.EncodeToString(sum[:]) != f.challenge {
+				writeJSON(w, http.StatusBadRequest, authError{400, "bad_code_verifier", "code challenge does not match previously saved code verifier"})
+				return
+			}
+			s.auth.flows = append(s.auth.flows[:i], s.auth.flows[i+1:]...)
+			writeJSON(w, http.StatusOK, s.sessionBody(s.auth.users[f.userID], "aal1"))
+		case "refresh_token":
+			g, ok := s.auth.refresh[req.RefreshToken]
+			if !ok {
+				writeJSON(w, http.StatusBadRequest, authError{400, "refresh_token_not_found", "Invalid Refresh Token: Refresh Token Not Found"})
+				return
+			}
+			// Refresh tokens are single use.
+			delete(s.auth.refresh, req.RefreshToken)
+			writeJSON(w, http.StatusOK, s.sessionBody(s.auth.users[g.userID], g.aal))
+		default:
+			writeJSON(w, http.StatusBadRequest, authError{400, "unsupported_grant_type", "unsupported_grant_type"})
+		}
+
+	case (path == "/recover" || path == "/otp") && r.Method == http.MethodPost:
+		var req struct {
+			Email      string `json:"email"`
+			CreateUser bool   `json:"create_user"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, authError{400, "bad_json", err.Error()})
+			return
+		}
+		kind := "recovery"
+		if path == "/otp" {
+			kind = "email"
+		}
+		u := s.findUser(req.Email, "")
+		if u == nil && kind == "email" {
+			if !req.CreateUser {
+				writeJSON(w, http.StatusUnprocessableEntity, authError{422, "otp_disabled", "Signups not allowed for otp"})
+				return
+			}
+			u = s.addUser(req.Email, "", "", nil)
+		}
+		// Recovery answers alike for unknown addresses so it cannot be used
+		// to probe for accounts.
+		if u != nil {
+			s.auth.codes[strings.ToLower(u.Email)] = sentCode{randomCode(), kind}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{})
+
+	case path == "/verify" && r.Method == http.MethodPost:
+		var req struct {
+			Type  string `json:"type"`
+			Token string `json:"token"`
+			Email string `json:"email"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, authError{400, "bad_json", err.Error()})
+			return
+		}
+		email := strings.ToLower(req.Email)
+		c, ok := s.auth.codes[email]
+		if req.Type == "magiclink" {
+			req.Type = "email"
+		}
+		u := s.findUser(email, "")
+		if !ok || u == nil || c.code != req.Token || c.kind != req.Type {
+			writeJSON(w, http.StatusForbidden, authError{403, "otp_expired", "Token has expired or is invalid"})
+			return
+		}
+		delete(s.auth.codes, email)
+		writeJSON(w, http.StatusOK, s.sessionBody(u, "aal1"))
+
+	case path == "/authorize" && r.Method == http.MethodGet:
+		q := r.URL.Query()
+		if q.Get("provider") == "" {
+			writeJSON(w, http.StatusBadRequest, authError{400, "validation_failed", "Unsupported provider: provider  could not be found"})
+			return
+		}
+		if q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256" {
+			writeJSON(w, http.StatusBadRequest, authError{400, "validation_failed", "only the PKCE flow with S256 is supported"})
+			return
+		}
+		f := &oauthFlow{
+			id:        randomHex(8),
+			provider:  q.Get("provider"),
+			challenge: q.Get("code_challenge"),
+			redirect:  q.Get("redirect_to"),
+		}
+		s.auth.flows = append(s.auth.flows, f)
+		http.Redirect(w, r, "http://"+r.Host+oauthProviderPath+"?flow="+f.id, http.StatusFound)
+
+	case path == "/user" && r.Method == http.MethodGet:
+		u := s.bearerUser(r)
+		if u == nil {
+			writeJSON(w, http.StatusUnauthorized, authError{401, "bad_jwt", "invalid JWT"})
+			return
+		}
+		writeJSON(w, http.StatusOK, u)
+
+	case path == "/user" && r.Method == http.MethodPut:
+		u := s.bearerUser(r)
+		if u == nil {
+			writeJSON(w, http.StatusUnauthorized, authError{401, "bad_jwt", "invalid JWT"})
+			return
+		}
+		var req struct {
+			Email    string                 `json:"email"`
+			Password *string                `json:"password"`
+			Data     map[string]interface{} `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, authError{400, "bad_json", err.Error()})
+			return
+		}
+		if req.Password != nil {
+			if len(*req.Password) < 6 {
+				writeJSON(w, http.StatusUnprocessableEntity, authError{422, "weak_password", "Password should be at least 6 characters."})
+				return
+			}
+			if *req.Password == u.password {
+				writeJSON(w, http.StatusUnprocessableEntity, authError{422, "same_password", "New password should be different from the old password."})
+				return
+			}
+		}
+		if req.Email != "" && !strings.EqualFold(req.Email, u.Email) {
+			if other := s.findUser(req.Email, ""); other != nil {
+				writeJSON(w, http.StatusUnprocessableEntity, authError{422, "email_exists", "A user with this email address has already been registered"})
+				return
+			}
+		}
+		now := s.now().UTC()
+		if req.Password != nil {
+			u.password = *req.Password
+		}
+		if req.Email != "" && !strings.EqualFold(req.Email, u.Email) {
+			u.NewEmail, u.EmailChangeSentAt = req.Email, &now
+		}
+		for k, v := range req.Data {
+			u.Metadata[k] = v
+		}
+		u.UpdatedAt = now
+		writeJSON(w, http.StatusOK, u)
+
+	case path == "/logout" && r.Method == http.MethodPost:
+		if u := s.bearerUser(r); u != nil {
+			for t, g := range s.auth.access {
+				if g.userID == u.ID {
+					delete(s.auth.access, t)
+				}
+			}
+			for t, g := range s.auth.refresh {
+				if g.userID == u.ID {
+					delete(s.auth.refresh, t)
+				}
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	case path == "/factors" || strings.HasPrefix(path, "/factors/"):
+		s.serveFactors(w, r, strings.TrimPrefix(path, "/factors"))
+
+	default:
+		writeJSON(w, http.StatusNotFound, authError{404, "not_found", "not found"})
+	}
+}
+
+// serveFactors handles TOTP enrolment, challenges and unenrolment for the
+// bearer's own factors; rest is the path after /factors.
+func (s *Server) serveFactors(w http.ResponseWriter, r *http.Request, rest string) {
+	token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	g, ok := s.auth.access[token]
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, authError{401, "bad_jwt", "invalid JWT"})
+		return
+	}
+	u := s.auth.users[g.userID]
+
+	if rest == "" && r.Method == http.MethodPost {
+		var req struct {
+			FriendlyName string `json:"friendly_name"`
+			FactorType   string `json:"factor_type"`
+			Issuer       string `json:"issuer"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, authError{400, "bad_json", err.Error()})
+			return
+		}
+		if req.FactorType != "totp" {
+			writeJSON(w, http.StatusBadRequest, authError{400, "validation_failed", "factor_type needs to be totp"})
+			return
+		}
+		for _, f := range u.Factors {
+			if req.FriendlyName != "" && f.FriendlyName == req.FriendlyName {
+				writeJSON(w, http.StatusUnprocessableEntity, authError{422, "mfa_factor_name_conflict", "A factor with the friendly name \"" + req.FriendlyName + "\" for this user already exists"})
+				return
+			}
+		}
+		f := s.addFactor(u, req.FriendlyName)
+		issuer := req.Issuer
+		if issuer == "" {
+			issuer = r.Host
+		}
+		uri := (&url.URL{
+			Scheme:   "otpauth",
+			Host:     "totp",
+			Path:     "/" + issuer + ":" + u.Email,
+			RawQuery: url.Values{"secret": {f.secret}, "issuer": {issuer}}.Encode(),
+		}).String()
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"id":   f.ID,
+			"type": "totp",
+			"totp": map[string]string{
+				// Real GoTrue sends an SVG here; nothing in zello draws it.
+				"qr_code": "data:image/svg+xml;utf-8,",
+				"secret":  f.secret,
+				"uri":     uri,
+			},
+		})
+		return
+	}
+
+	id, action, _ := strings.Cut(strings.TrimPrefix(rest, "/"), "/")
+	var f *fakeFactor
+	i := -1
+	for j, c := range u.Factors {
+		if c.ID == id {
+			f, i = c, j
+		}
+	}
+	if f == nil {
+		writeJSON(w, http.StatusNotFound, authError{404, "mfa_factor_not_found", "Factor not found"})
+		return
+	}
+
+	switch {
+	case action == "challenge" && r.Method == http.MethodPost:
+		c := mfaChallenge{factorID: f.ID, expiresAt: s.now().Add(challengeLifetime)}
+		cid := newUUID()
+		s.auth.challenges[cid] = c
+		writeJSON(w, http.StatusOK, map[string]interface{}{"id": cid, "expires_at": c.expiresAt.Unix()})
+
+	case action == "verify" && r.Method == http.MethodPost:
+		var req struct {
+			ChallengeID string `json:"challenge_id"`
+			Code        string `json:"code"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, authError{400, "bad_json", err.Error()})
+			return
+		}
+		c, ok := s.auth.challenges[req.ChallengeID]
+		if !ok || c.factorID != f.ID {
+			writeJSON(w, http.StatusNotFound, authError{404, "mfa_factor_not_found", "MFA factor with the provided challenge ID not found"})
+			return
+		}
+		if s.now().After(c.expiresAt) {
+			delete(s.auth.challenges, req.ChallengeID)
+			writeJSON(w, http.StatusUnprocessableEntity, authError{422, "mfa_challenge_expired", "MFA challenge has expired, verify against another challenge or create a new factor."})
+			return
+		}
+		if !s.validTOTP(f.secret, req.Code) {
+			writeJSON(w, http.StatusUnprocessableEntity, authError{422, "mfa_verification_failed", "Invalid TOTP code entered"})
+			return
+		}
+		delete(s.auth.challenges, req.ChallengeID)
+		f.Status, f.UpdatedAt = "verified", s.now().UTC()
+		writeJSON(w, http.StatusOK, s.sessionBody(u, "aal2"))
+
+	case action == "" && r.Method == http.MethodDelete:
+		if f.Status == "verified" && g.aal != "aal2" {
+			writeJSON(w, http.StatusForbidden, authError{403, "insufficient_aal", "AAL2 required to unenroll verified factor"})
+			return
+		}
+		u.Factors = append(u.Factors[:i], u.Factors[i+1:]...)
+		writeJSON(w, http.StatusOK, map[string]string{"id": f.ID})
+
+	default:
+		writeJSON(w, http.StatusNotFound, authError{404, "not_found", "not found"})
+	}
+}
+
+func (s *Server) addFactor(u *fakeUser, name string) *fakeFactor {
+	secret := make([]byte, 20)
+	rand.Read(secret)
+	now := s.now().UTC()
+	f := &fakeFactor{
+		ID:           newUUID(),
+		FriendlyName: name,
+		FactorType:   "totp",
+		Status:       "unverified",
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		secret:       base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret),
+	}
+	u.Factors = append(u.Factors, f)
+	return f
+}
+
+// validTOTP accepts the code for the current 30 second step or the one
+// either side of it, allowing for clock drift as GoTrue does.
+func (s *Server) validTOTP(secret, code string) bool {
+	now := s.now()
+	for _, skew := range []time.Duration{0, -30 * time.Second, 30 * time.Second} {
+		if hmac.Equal([]byte(totp(secret, now.Add(skew))), []byte(code)) {
+			return true
+		}
+	}
+	return false
+}
+
+// totp computes the RFC 6238 code for a base32 secret at t.
+func totp(secret string, t time.Time) string {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		return ""
+	}
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(t.Unix()/30))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	n := binary.BigEndian.Uint32(sum[offset:]) & 0x7fffffff
+	return fmt.Sprintf("%06d", n%1_000_000)
+}
+
+// serveOAuthProvider stands in for the provider's consent page: it sends
+// the browser straight back with a code for the provider's user, or with
+// access_denied when AddOAuthUser gave it none.
+func (s *Server) serveOAuthProvider(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, f := s.findFlow(func(f *oauthFlow) bool { return f.id == r.URL.Query().Get("flow") })
+	if f == nil {
+		http.Error(w, "unknown authorization", http.StatusNotFound)
+		return
+	}
+	back, err := url.Parse(f.redirect)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	q := back.Query()
+	if id, ok := s.auth.oauth[f.provider]; ok {
+		f.userID, f.code = id, randomHex(16)
+		q.Set("code", f.code)
+	} else {
+		q.Set("error", "access_denied")
+		q.Set("error_description", "The user denied access to "+f.provider)
+	}
+	back.RawQuery = q.Encode()
+	http.Redirect(w, r, back.String(), http.StatusFound)
+}
+
+func (s *Server) bearerUser(r *http.Request) *fakeUser {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return nil
+	}
+	g, ok := s.auth.access[token]
+	if !ok {
+		return nil
+	}
+	return s.auth.users[g.userID]
+}
+
+// sessionBody issues a session at assurance level aal. Access tokens are
+// shaped like GoTrue's JWTs so clients can read the claims, but are not
+// signed; the fake only accepts tokens it handed out.
+func (s *Server) sessionBody(u *fakeUser, aal string) map[string]interface{} {
+	expiresAt := s.now().Add(tokenLifetime).Unix()
+	header, _ := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"sub":  u.ID,
+		"aud":  u.Aud,
+		"role": u.Role,
+		"aal":  aal,
+		"exp":  expiresAt,
+	})
+	access := base64.RawURLEncoding.EncodeToString(header) + "." +
+		base64.RawURLEncoding.EncodeToString(claims) + "." + randomHex(16)
+	refresh := "refresh-" + randomHex(16)
+	s.auth.access[access] = grant{u.ID, aal}
+	s.auth.refresh[refresh] = grant{u.ID, aal}
+	return map[string]interface{}{
+		"access_token":  access,
+		"refresh_token": refresh,
+		"token_type":    "bearer",
+		"expires_in":    int(tokenLifetime.Seconds()),
+		"expires_at":    expiresAt,
+		"user":          u,
+	}
+}
+
+func randomHex(n int) string {
+	buf := make([]byte, n)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// randomCode returns a six digit one-time code.
+func randomCode() string {
+	n, _ := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	return fmt.Sprintf("%06d", n)
+}
+
+func newUUID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	h := hex.EncodeToString(b)
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
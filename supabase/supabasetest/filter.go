@@ -0,0 +1,355 @@
+package supabasetest
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type predicate func(row) bool
+
+// parseFilters compiles the horizontal filters of a request: column=op.value
+// parameters and the and=(...) / or=(...) logic trees, all of which must
+// hold.
+func parseFilters(query url.Values) (predicate, error) {
+	var preds []predicate
+	for key, values := range query {
+		if reservedParams[key] {
+			continue
+		}
+		for _, value := range values {
+			var p predicate
+			var err error
+			switch key {
+			case "and", "or", "not.and", "not.or":
+				negate := strings.HasPrefix(key, "not.")
+				p, err = parseLogic(strings.TrimPrefix(key, "not."), value)
+				if negate && p != nil {
+					inner := p
+					p = func(r row) bool { return !inner(r) }
+				}
+			default:
+				if strings.Contains(key, ".") {
+					// Filters on embedded resources are not supported.
+					continue
+				}
+				p, err = parseCondition(key, value)
+			}
+			if err != nil {
+				return nil, err
+			}
+			preds = append(preds, p)
+		}
+	}
+	return allOf(preds), nil
+}
+
+// parseLogic compiles "(cond,cond,...)" joined by op, where each cond is
+// "column.op.value" or a nested "and(...)", "or(...)", "not.and(...)".
+func parseLogic(op, value string) (predicate, error) {
+	if !strings.HasPrefix(value, "(") || !strings.HasSuffix(value, ")") {
+		return nil, fmt.Errorf("\"failed to parse logic tree (%s)\"", value)
+	}
+	var preds []predicate
+	for _, part := range splitTopLevel(value[1 : len(value)-1]) {
+		negate := false
+		if rest, ok := strings.CutPrefix(part, "not."); ok && (strings.HasPrefix(rest, "and(") || strings.HasPrefix(rest, "or(")) {
+			negate, part = true, rest
+		}
+		var p predicate
+		var err error
+		switch {
+		case strings.HasPrefix(part, "and("):
+			p, err = parseLogic("and", part[3:])
+		case strings.HasPrefix(part, "or("):
+			p, err = parseLogic("or", part[2:])
+		default:
+			column, cond, ok := strings.Cut(part, ".")
+			if !ok {
+				return nil, fmt.Errorf("\"failed to parse logic tree (%s)\"", value)
+			}
+			p, err = parseCondition(column, cond)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if negate {
+			inner := p
+			p = func(r row) bool { return !inner(r) }
+		}
+		preds = append(preds, p)
+	}
+	if op == "or" {
+		return anyOf(preds), nil
+	}
+	return allOf(preds), nil
+}
+
+// parseCondition compiles "op.value" or "not.op.value" applied to column.
+func parseCondition(column, cond string) (predicate, error) {
+	negate := false
+	if rest, ok := strings.CutPrefix(cond, "not."); ok {
+		negate, cond = true, rest
+	}
+	op, value, ok := strings.Cut(cond, ".")
+	if !ok {
+		return nil, fmt.Errorf("\"failed to parse filter (%s)\"", cond)
+	}
+	test, err := operator(op, value)
+	if err != nil {
+		return nil, err
+	}
+	return func(r row) bool {
+		v, present := r[column]
+		if !present && strings.Contains(op, "fts") {
+			// Full-text columns are generated in Postgres; search every
+			// text field instead.
+			v = rowText(r)
+		}
+		return test(v) != negate
+	}, nil
+}
+
+func operator(op, value string) (func(interface{}) bool, error) {
+	switch op {
+	case "eq":
+		return func(v interface{}) bool { return v != nil && compareValues(v, value) == 0 }, nil
+	case "neq":
+		return func(v interface{}) bool { return v != nil && compareValues(v, value) != 0 }, nil
+	case "gt":
+		return func(v interface{}) bool { return v != nil && compareValues(v, value) > 0 }, nil
+	case "gte":
+		return func(v interface{}) bool { return v != nil && compareValues(v, value) >= 0 }, nil
+	case "lt":
+		return func(v interface{}) bool { return v != nil && compareValues(v, value) < 0 }, nil
+	case "lte":
+		return func(v interface{}) bool { return v != nil && compareValues(v, value) <= 0 }, nil
+	case "like", "ilike":
+		pattern := regexp.QuoteMeta(value)
+		pattern = strings.NewReplacer(`\*`, ".*", "%", ".*", "_", ".").Replace(pattern)
+		if op == "ilike" {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile("^" + pattern + "$")
+		if err != nil {
+			return nil, err
+		}
+		return func(v interface{}) bool { s, ok := v.(string); return ok && re.MatchString(s) }, nil
+	case "is":
+		switch strings.ToLower(value) {
+		case "null":
+			return func(v interface{}) bool { return v == nil }, nil
+		case "true", "false":
+			want := strings.ToLower(value) == "true"
+			return func(v interface{}) bool { b, ok := v.(bool); return ok && b == want }, nil
+		}
+		return nil, fmt.Errorf("\"failed to parse filter (is.%s)\"", value)
+	case "in":
+		if !strings.HasPrefix(value, "(") || !strings.HasSuffix(value, ")") {
+			return nil, fmt.Errorf("\"failed to parse filter (in.%s)\"", value)
+		}
+		items := splitList(value[1 : len(value)-1])
+		return func(v interface{}) bool {
+			for _, item := range items {
+				if v != nil && compareValues(v, item) == 0 {
+					return true
+				}
+			}
+			return false
+		}, nil
+	case "cs", "cd", "ov":
+		if !strings.HasPrefix(value, "{") || !strings.HasSuffix(value, "}") {
+			return nil, fmt.Errorf("\"failed to parse filter (%s.%s)\"", op, value)
+		}
+		items := splitList(value[1 : len(value)-1])
+		return func(v interface{}) bool {
+			arr, ok := v.([]interface{})
+			if !ok {
+				return false
+			}
+			return arrayOp(op, arr, items)
+		}, nil
+	case "fts", "plfts", "phfts", "wfts":
+		terms := searchTerms(value)
+		return func(v interface{}) bool {
+			text := strings.ToLower(fmt.Sprint(v))
+			for _, t := range terms {
+				if strings.Contains(text, t.word) == t.exclude {
+					return false
+				}
+			}
+			return len(terms) > 0
+		}, nil
+	}
+	if base, _, ok := strings.Cut(op, "("); ok && strings.HasSuffix(base, "fts") {
+		// fts(english).query: the config makes no difference here.
+		return operator(base, value)
+	}
+	return nil, fmt.Errorf("\"failed to parse filter (%s.%s)\"", op, value)
+}
+
+func arrayOp(op string, arr []interface{}, items []string) bool {
+	has := func(item string) bool {
+		for _, a := range arr {
+			if compareValues(a, item) == 0 {
+				return true
+			}
+		}
+		return false
+	}
+	switch op {
+	case "cs":
+		for _, item := range items {
+			if !has(item) {
+				return false
+			}
+		}
+		return true
+	case "cd":
+		for _, a := range arr {
+			found := false
+			for _, item := range items {
+				if compareValues(a, item) == 0 {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	}
+	for _, item := range items {
+		if has(item) {
+			return true
+		}
+	}
+	return false
+}
+
+type searchTerm struct {
+	word    string
+	exclude bool
+}
+
+// searchTerms approximates websearch_to_tsquery: every word must appear
+// and words prefixed with - must not. "or" is ignored.
+func searchTerms(query string) []searchTerm {
+	var terms []searchTerm
+	for _, f := range strings.Fields(strings.ToLower(query)) {
+		f = strings.Trim(f, `"'`)
+		if f == "" || f == "or" || f == "and" {
+			continue
+		}
+		if w, ok := strings.CutPrefix(f, "-"); ok {
+			terms = append(terms, searchTerm{w, true})
+			continue
+		}
+		terms = append(terms, searchTerm{f, false})
+	}
+	return terms
+}
+
+func rowText(r row) string {
+	var b strings.Builder
+	for _, v := range r {
+		if s, ok := v.(string); ok {
+			b.WriteString(s)
+			b.WriteByte(' ')
+		}
+	}
+	return b.String()
+}
+
+// compareValues orders a stored JSON value against another stored value or
+// a filter string, comparing numerically when the stored value is a number.
+func compareValues(a, b interface{}) int {
+	if bs, ok := b.(string); ok {
+		switch av := a.(type) {
+		case float64:
+			f, err := strconv.ParseFloat(bs, 64)
+			if err != nil {
+				return strings.Compare(strconv.FormatFloat(av, 'f', -1, 64), bs)
+			}
+			return compareFloat(av, f)
+		case bool:
+			return strings.Compare(strconv.FormatBool(av), strings.ToLower(bs))
+		}
+	}
+	if af, ok := a.(float64); ok {
+		if bf, ok := b.(float64); ok {
+			return compareFloat(af, bf)
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// splitTopLevel splits on commas outside parentheses, braces and quotes.
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, quoted, start := 0, false, 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '(' || c == '{':
+			depth++
+		case c == ')' || c == '}':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	if rest := strings.TrimSpace(s[start:]); rest != "" {
+		parts = append(parts, rest)
+	}
+	return parts
+}
+
+// splitList splits an in.() or {} list, unquoting quoted items.
+func splitList(s string) []string {
+	parts := splitTopLevel(s)
+	for i, p := range parts {
+		if len(p) >= 2 && p[0] == '"' && p[len(p)-1] == '"' {
+			parts[i] = strings.ReplaceAll(p[1:len(p)-1], `\"`, `"`)
+		}
+	}
+	return parts
+}
+
+func allOf(preds []predicate) predicate {
+	return func(r row) bool {
+		for _, p := range preds {
+			if !p(r) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func anyOf(preds []predicate) predicate {
+	return func(r row) bool {
+		for _, p := range preds {
+			if p(r) {
+				return true
+			}
+		}
+		return false
+	}
+}
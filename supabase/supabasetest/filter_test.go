@@ -0,0 +1,88 @@
+package supabasetest
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var filterRows = []row{
+	{"id": float64(1), "title": "Login fails", "status": "open", "labels": []interface{}{"bug", "auth"}, "assignee_id": "u1", "shared": true},
+	{"id": float64(2), "title": "Dark mode", "status": "in_progress", "labels": []interface{}{"feature"}, "assignee_id": nil, "shared": false},
+	{"id": float64(10), "title": "Crash on start", "status": "closed", "labels": []interface{}{"bug"}, "assignee_id": "u2", "shared": false},
+}
+
+func TestParseFilters(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", "1,2,10"},
+		{"status=eq.open", "1"},
+		{"status=neq.open", "2,10"},
+		{"id=gt.2", "10"},
+		{"id=lte.2", "1,2"},
+		{"title=ilike.*crash*", "10"},
+		{"title=like.Dark%25", "2"},
+		{"assignee_id=is.null", "2"},
+		{"shared=is.true", "1"},
+		{"id=in.(1,10)", "1,10"},
+		{"status=in.(\"open\",\"closed\")", "1,10"},
+		{"labels=cs.{bug}", "1,10"},
+		{"labels=cs.{bug,auth}", "1"},
+		{"labels=cd.{bug,feature}", "2,10"},
+		{"labels=ov.{auth,feature}", "1,2"},
+		{"status=not.eq.open", "2,10"},
+		{"status=eq.open&id=eq.2", ""},
+		{"or=(status.eq.open,id.eq.2)", "1,2"},
+		{"or=(assignee_id.eq.u2,and(shared.is.true,status.eq.open))", "1,10"},
+		{"and=(labels.cs.{bug},not.or(status.eq.closed,id.eq.2))", "1"},
+		{"not.or=(status.eq.open,status.eq.closed)", "2"},
+		{"title=fts.crash -login", "10"},
+		{"fts=wfts(english).dark", "2"},
+		{"issue.board_id=eq.1", "1,2,10"},
+		{"select=id&order=id.desc&limit=1", "1,2,10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			match, err := parseFilters(values)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, r := range filterRows {
+				if match(r) {
+					ids = append(ids, strconv.FormatFloat(r["id"].(float64), 'f', -1, 64))
+				}
+			}
+			if got := strings.Join(ids, ","); got != tt.want {
+				t.Errorf("matched %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFiltersErrors(t *testing.T) {
+	for _, query := range []string{
+		"status=open",
+		"status=regex.open",
+		"assignee_id=is.maybe",
+		"id=in.1,2",
+		"labels=cs.bug",
+		"or=status.eq.open",
+		"or=(status)",
+	} {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := parseFilters(values); err == nil {
+			t.Errorf("%s: no error", query)
+		}
+	}
+}
@@ -0,0 +1,407 @@
+package supabasetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"zel/lo/supabase"
+)
+
+// pgError is the PostgREST error body.
+type pgError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details string `json:"details"`
+	Hint    string `json:"hint"`
+}
+
+var reservedParams = map[string]bool{"select": true, "order": true, "limit": true, "offset": true, "on_conflict": true, "columns": true}
+
+func (s *Server) serveREST(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, supabase.REST_URL+"/")
+	if name, ok := strings.CutPrefix(path, "rpc/"); ok {
+		s.serveRPC(w, r, name)
+		return
+	}
+	table := path
+	if table == "" || strings.Contains(table, "/") {
+		writeJSON(w, http.StatusNotFound, pgError{Code: "PGRST125", Message: "Invalid path specified in request URL"})
+		return
+	}
+
+	query := r.URL.Query()
+	filter, err := parseFilters(query)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, pgError{Code: "PGRST100", Message: err.Error()})
+		return
+	}
+	prefer := parsePrefer(r.Header.Get("Prefer"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result []row
+	status := http.StatusOK
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		result, err = s.selectRows(table, filter, query, r.Header.Get("Range"), w, prefer["count"] != "")
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, pgError{Code: "PGRST100", Message: err.Error()})
+			return
+		}
+	case http.MethodPost:
+		rows, err := readRows(r.Body)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, pgError{Code: "PGRST102", Message: err.Error()})
+			return
+		}
+		var onConflict []string
+		if prefer["resolution"] == "merge-duplicates" {
+			onConflict = []string{"id"}
+			if c := query.Get("on_conflict"); c != "" {
+				onConflict = strings.Split(c, ",")
+			}
+		}
+		inserted, pgErr := s.insertRows(table, rows, onConflict)
+		if pgErr != nil {
+			writeJSON(w, http.StatusConflict, pgErr)
+			return
+		}
+		result = inserted
+		status = http.StatusCreated
+	case http.MethodPatch:
+		var patch row
+		if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+			writeJSON(w, http.StatusBadRequest, pgError{Code: "PGRST102", Message: err.Error()})
+			return
+		}
+		for _, existing := range s.tables[table] {
+			if filter(existing) {
+				for k, v := range patch {
+					existing[k] = v
+				}
+				result = append(result, existing)
+			}
+		}
+	case http.MethodDelete:
+		kept := s.tables[table][:0]
+		for _, existing := range s.tables[table] {
+			if filter(existing) {
+				result = append(result, existing)
+			} else {
+				kept = append(kept, existing)
+			}
+		}
+		s.tables[table] = kept
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, pgError{Message: "method not allowed"})
+		return
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		if c := prefer["count"]; c != "" {
+			w.Header().Set("Content-Range", fmt.Sprintf("*/%d", len(result)))
+		}
+		if prefer["return"] != "representation" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+
+	out := make([]row, len(result))
+	for i, r := range result {
+		out[i] = project(r, query.Get("select"))
+	}
+	if strings.Contains(r.Header.Get("Accept"), "vnd.pgrst.object") {
+		if len(out) != 1 {
+			writeJSON(w, http.StatusNotAcceptable, pgError{Code: "PGRST116", Message: "JSON object requested, multiple (or no) rows returned", Details: fmt.Sprintf("The result contains %d rows", len(out))})
+			return
+		}
+		writeJSON(w, status, out[0])
+		return
+	}
+	if r.Method == http.MethodHead {
+		w.WriteHeader(status)
+		return
+	}
+	writeJSON(w, status, out)
+}
+
+func (s *Server) selectRows(table string, filter func(row) bool, query url.Values, rangeHeader string, w http.ResponseWriter, count bool) ([]row, error) {
+	var matched []row
+	for _, r := range s.tables[table] {
+		if filter(r) {
+			matched = append(matched, r)
+		}
+	}
+	if err := orderRows(matched, query.Get("order")); err != nil {
+		return nil, err
+	}
+
+	total := len(matched)
+	offset, limit := 0, -1
+	if v := query.Get("offset"); v != "" {
+		offset, _ = strconv.Atoi(v)
+	}
+	if v := query.Get("limit"); v != "" {
+		limit, _ = strconv.Atoi(v)
+	}
+	if from, to, ok := strings.Cut(rangeHeader, "-"); ok {
+		f, err1 := strconv.Atoi(from)
+		t, err2 := strconv.Atoi(to)
+		if err1 == nil && err2 == nil && t >= f {
+			offset, limit = f, t-f+1
+		}
+	}
+	if offset > len(matched) {
+		offset = len(matched)
+	}
+	matched = matched[offset:]
+	if limit >= 0 && limit < len(matched) {
+		matched = matched[:limit]
+	}
+
+	totalPart := "*"
+	if count {
+		totalPart = strconv.Itoa(total)
+	}
+	if len(matched) == 0 {
+		w.Header().Set("Content-Range", "*/"+totalPart)
+	} else {
+		w.Header().Set("Content-Range", fmt.Sprintf("%d-%d/%s", offset, offset+len(matched)-1, totalPart))
+	}
+	return matched, nil
+}
+
+func orderRows(rows []row, order string) error {
+	if order == "" {
+		return nil
+	}
+	type key struct {
+		column     string
+		desc       bool
+		nullsFirst bool
+	}
+	var keys []key
+	for _, part := range strings.Split(order, ",") {
+		fields := strings.Split(part, ".")
+		k := key{column: fields[0]}
+		for _, f := range fields[1:] {
+			switch f {
+			case "asc":
+			case "desc":
+				k.desc = true
+			case "nullsfirst":
+				k.nullsFirst = true
+			case "nullslast":
+			default:
+				return fmt.Errorf("invalid order %q", part)
+			}
+		}
+		keys = append(keys, k)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		for _, k := range keys {
+			a, b := rows[i][k.column], rows[j][k.column]
+			if a == nil || b == nil {
+				if (a == nil) == (b == nil) {
+					continue
+				}
+				return (a == nil) == k.nullsFirst
+			}
+			c := compareValues(a, b)
+			if c == 0 {
+				continue
+			}
+			return (c < 0) != k.desc
+		}
+		return false
+	})
+	return nil
+}
+
+func readRows(body io.Reader) ([]row, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	data = []byte(strings.TrimSpace(string(data)))
+	if len(data) > 0 && data[0] == '{' {
+		var r row
+		if err := json.Unmarshal(data, &r); err != nil {
+			return nil, err
+		}
+		return []row{r}, nil
+	}
+	var rows []row
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// insertRows inserts rows, merging into existing rows that match on the
+// onConflict columns when it is set. Nothing is written if any row violates
+// a unique constraint.
+func (s *Server) insertRows(table string, rows []row, onConflict []string) ([]row, *pgError) {
+	type op struct {
+		existing row
+		fresh    row
+	}
+	ops := make([]op, len(rows))
+	for i, r := range rows {
+		if onConflict != nil {
+			if existing := s.findMatch(table, r, onConflict); existing != nil {
+				ops[i].existing = existing
+				ops[i].fresh = r
+				continue
+			}
+		}
+		for _, cols := range s.unique[table] {
+			if s.findMatch(table, r, cols) != nil {
+				return nil, &pgError{
+					Code:    "23505",
+					Message: fmt.Sprintf("duplicate key value violates unique constraint \"%s_%s_key\"", table, strings.Join(cols, "_")),
+					Details: fmt.Sprintf("Key (%s) already exists.", strings.Join(cols, ", ")),
+				}
+			}
+		}
+		ops[i].fresh = r
+	}
+
+	result := make([]row, 0, len(rows))
+	for _, o := range ops {
+		if o.existing != nil {
+			for k, v := range o.fresh {
+				o.existing[k] = v
+			}
+			result = append(result, o.existing)
+			continue
+		}
+		result = append(result, s.insertRow(table, o.fresh))
+	}
+	return result, nil
+}
+
+func (s *Server) insertRow(table string, r row) row {
+	if _, ok := r["id"]; !ok {
+		s.nextID[table]++
+		r["id"] = float64(s.nextID[table])
+	} else if id, ok := r["id"].(float64); ok && int(id) > s.nextID[table] {
+		s.nextID[table] = int(id)
+	}
+	if _, ok := r["created_at"]; !ok {
+		r["created_at"] = s.now().UTC().Format(time.RFC3339Nano)
+	}
+	s.tables[table] = append(s.tables[table], r)
+	return r
+}
+
+func (s *Server) findMatch(table string, r row, columns []string) row {
+	for _, existing := range s.tables[table] {
+		match := true
+		for _, c := range columns {
+			v, ok := r[c]
+			if !ok || v == nil || compareValues(existing[c], v) != 0 {
+				match = false
+				break
+			}
+		}
+		if match {
+			return existing
+		}
+	}
+	return nil
+}
+
+// project applies a select list. Embedded resources such as
+// "issue:issues(*)" are not supported and are left out.
+func project(r row, selectList string) row {
+	if selectList == "" || selectList == "*" {
+		return copyRow(r)
+	}
+	out := row{}
+	for _, col := range splitTopLevel(selectList) {
+		if col == "*" {
+			for k, v := range r {
+				out[k] = v
+			}
+			continue
+		}
+		if strings.Contains(col, "(") {
+			continue
+		}
+		name := col
+		if alias, real, ok := strings.Cut(col, ":"); ok {
+			name, col = alias, real
+		}
+		col, _, _ = strings.Cut(col, "::")
+		out[name] = r[col]
+	}
+	return out
+}
+
+func parsePrefer(header string) map[string]string {
+	prefs := map[string]string{}
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if k != "" {
+			prefs[k] = v
+		}
+	}
+	return prefs
+}
+
+func (s *Server) serveRPC(w http.ResponseWriter, r *http.Request, name string) {
+	s.mu.Lock()
+	fn, ok := s.rpcs[name]
+	s.mu.Unlock()
+	if !ok {
+		writeJSON(w, http.StatusNotFound, pgError{Code: "PGRST202", Message: fmt.Sprintf("Could not find the function public.%s in the schema cache", name)})
+		return
+	}
+
+	params := map[string]interface{}{}
+	if r.Method == http.MethodPost {
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil && err != io.EOF {
+			writeJSON(w, http.StatusBadRequest, pgError{Code: "PGRST102", Message: err.Error()})
+			return
+		}
+	} else {
+		for k, v := range r.URL.Query() {
+			params[k] = v[0]
+		}
+	}
+
+	result, err := fn(params)
+	if err != nil {
+		if pe, ok := err.(*RPCError); ok {
+			writeJSON(w, http.StatusBadRequest, pgError{Code: pe.Code, Message: pe.Message, Details: pe.Details, Hint: pe.Hint})
+			return
+		}
+		writeJSON(w, http.StatusBadRequest, pgError{Code: "P0001", Message: err.Error()})
+		return
+	}
+	if rows, ok := result.([]map[string]interface{}); ok && parsePrefer(r.Header.Get("Prefer"))["count"] != "" {
+		w.Header().Set("Content-Range", fmt.Sprintf("0-%d/%d", len(rows)-1, len(rows)))
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// RPCError lets a HandleRPC stub control the Postgres error it reports,
+// e.g. to mimic a RAISE with a HINT.
+type RPCError struct {
+	Code    string
+	Message string
+	Details string
+	Hint    string
+}
+
+func (e *RPCError) Error() string { return e.Message }
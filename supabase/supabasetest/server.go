@@ -0,0 +1,198 @@
+// Package supabasetest provides an in-process fake of the Supabase services
+// zello talks to, for exercising code that takes a *supabase.Client without
+// a live project:
+//
+//	srv := supabasetest.NewServer()
+//	defer srv.Close()
+//	client := srv.Client()
+//	srv.Seed("issues", map[string]interface{}{"title": "First", "status": "open"})
+//	issues, err := internal.ListIssues(client)
+//
+// It implements the parts of PostgREST (table reads and writes with
+// filters, logic trees, ordering, ranges, counts and the Prefer header),
+// GoTrue (signup, password, refresh token and PKCE grants, one-time codes
+// for password reset and passwordless sign-in, OAuth with a stand-in
+// provider, TOTP factors, user lookup and update, logout) and Storage (buckets, upload,
+// download, list, remove) that the supabase client libraries use. Postgres functions and edge functions are stubbed
+// with HandleRPC and HandleFunction.
+//
+// Tables are schemaless lists of JSON objects. Every inserted row gets an
+// integer id and a created_at timestamp unless it has them already. There
+// is no row level security, no joins and no triggers: embedded resources in
+// select are ignored, and filters on embedded columns match every row.
+package supabasetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"zel/lo/supabase"
+)
+
+// AnonKey is the API key the fake accepts; Client uses it.
+const AnonKey = "supabasetest-anon-key"
+
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	tables    map[string][]row
+	nextID    map[string]int
+	unique    map[string][][]string
+	rpcs      map[string]func(params map[string]interface{}) (interface{}, error)
+	functions map[string]http.Handler
+	auth      authState
+	storage   map[string]*bucket
+	now       func() time.Time
+}
+
+type row = map[string]interface{}
+
+// NewServer starts a fake Supabase server. Close it when done.
+func NewServer() *Server {
+	s := &Server{
+		tables:    map[string][]row{},
+		nextID:    map[string]int{},
+		unique:    map[string][][]string{},
+		rpcs:      map[string]func(map[string]interface{}) (interface{}, error){},
+		functions: map[string]http.Handler{},
+		auth:      newAuthState(),
+		storage:   map[string]*bucket{},
+		now:       time.Now,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(supabase.REST_URL+"/", s.serveREST)
+	mux.HandleFunc(supabase.AUTH_URL+"/", s.serveAuth)
+	mux.HandleFunc(supabase.STORAGE_URL+"/", s.serveStorage)
+	mux.HandleFunc(supabase.FUNCTIONS_URL+"/", s.serveFunctions)
+	root := http.NewServeMux()
+	root.HandleFunc(oauthProviderPath, s.serveOAuthProvider)
+	root.Handle("/", s.requireKey(mux))
+	s.Server = httptest.NewServer(root)
+	return s
+}
+
+// Client returns a supabase client pointed at the fake.
+func (s *Server) Client() *supabase.Client {
+	client, err := supabase.NewClient(s.URL, AnonKey, nil)
+	if err != nil {
+		panic(err)
+	}
+	return client
+}
+
+// SetClock replaces the clock used for created_at and token expiry.
+func (s *Server) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+}
+
+// Seed inserts rows as if they had been posted to the table and returns
+// them with their generated ids.
+func (s *Server) Seed(table string, rows ...map[string]interface{}) []map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]map[string]interface{}, 0, len(rows))
+	for _, r := range rows {
+		// Round-trip through JSON so seeded values compare like posted ones.
+		out = append(out, copyRow(s.insertRow(table, normalize(r))))
+	}
+	return out
+}
+
+// Rows returns a copy of a table's rows in insertion order.
+func (s *Server) Rows(table string) []map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]map[string]interface{}, len(s.tables[table]))
+	for i, r := range s.tables[table] {
+		out[i] = copyRow(r)
+	}
+	return out
+}
+
+// Unique declares a unique constraint. Inserts that violate it fail with
+// Postgres error 23505, and upserts with a matching on_conflict merge into
+// the existing row.
+func (s *Server) Unique(table string, columns ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unique[table] = append(s.unique[table], columns)
+}
+
+// HandleRPC stubs the Postgres function name. The handler gets the decoded
+// named arguments; an error is reported as a PostgREST error response.
+func (s *Server) HandleRPC(name string, fn func(params map[string]interface{}) (interface{}, error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rpcs[name] = fn
+}
+
+// HandleFunction stubs the edge function name.
+func (s *Server) HandleFunction(name string, h http.Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.functions[name] = h
+}
+
+func (s *Server) requireKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("apikey") != AnonKey && r.Header.Get("Authorization") == "" {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "No API key found in request"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) serveFunctions(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, supabase.FUNCTIONS_URL+"/")
+	s.mu.Lock()
+	h, ok := s.functions[name]
+	s.mu.Unlock()
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "function not found", "code": "not_found"})
+		return
+	}
+	h.ServeHTTP(w, r)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func normalize(r map[string]interface{}) row {
+	data, err := json.Marshal(r)
+	if err != nil {
+		panic(fmt.Sprintf("supabasetest: row is not JSON: %v", err))
+	}
+	var out row
+	json.Unmarshal(data, &out)
+	return out
+}
+
+func copyRow(r row) row {
+	out := make(row, len(r))
+	for k, v := range r {
+		out[k] = v
+	}
+	return out
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,197 @@
+package supabasetest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"zel/lo/supabase"
+)
+
+type object struct {
+	data        []byte
+	contentType string
+	createdAt   time.Time
+	updatedAt   time.Time
+}
+
+type bucket struct {
+	id      string
+	public  bool
+	objects map[string]*object
+}
+
+type storageError struct {
+	StatusCode string `json:"statusCode"`
+	Error      string `json:"error"`
+	Message    string `json:"message"`
+}
+
+// Object returns the stored contents of bucket/path.
+func (s *Server) Object(bucketID, path string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.storage[bucketID]
+	if !ok {
+		return nil, false
+	}
+	o, ok := b.objects[path]
+	if !ok {
+		return nil, false
+	}
+	return append([]byte(nil), o.data...), true
+}
+
+func (s *Server) serveStorage(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, supabase.STORAGE_URL+"/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	notFound := func(msg string) {
+		writeJSON(w, http.StatusNotFound, storageError{"404", "not_found", msg})
+	}
+
+	switch {
+	case path == "bucket" && r.Method == http.MethodPost:
+		var req struct {
+			ID     string `json:"id"`
+			Name   string `json:"name"`
+			Public bool   `json:"public"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, storageError{"400", "invalid_request", err.Error()})
+			return
+		}
+		id := req.ID
+		if id == "" {
+			id = req.Name
+		}
+		if _, ok := s.storage[id]; ok {
+			writeJSON(w, http.StatusConflict, storageError{"409", "Duplicate", "The resource already exists"})
+			return
+		}
+		s.storage[id] = &bucket{id: id, public: req.Public, objects: map[string]*object{}}
+		writeJSON(w, http.StatusOK, map[string]string{"name": id})
+
+	case path == "bucket" && r.Method == http.MethodGet:
+		ids := make([]string, 0, len(s.storage))
+		for id := range s.storage {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		out := make([]map[string]interface{}, len(ids))
+		for i, id := range ids {
+			out[i] = map[string]interface{}{"id": id, "name": id, "public": s.storage[id].public}
+		}
+		writeJSON(w, http.StatusOK, out)
+
+	case strings.HasPrefix(path, "object/list/") && r.Method == http.MethodPost:
+		b, ok := s.storage[strings.TrimPrefix(path, "object/list/")]
+		if !ok {
+			notFound("Bucket not found")
+			return
+		}
+		var req struct {
+			Prefix string `json:"prefix"`
+			Limit  int    `json:"limit"`
+			Offset int    `json:"offset"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		prefix := strings.TrimSuffix(req.Prefix, "/")
+		if prefix != "" {
+			prefix += "/"
+		}
+		var names []string
+		for name := range b.objects {
+			if rest, ok := strings.CutPrefix(name, prefix); ok && !strings.Contains(rest, "/") {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		if req.Offset < len(names) {
+			names = names[req.Offset:]
+		} else {
+			names = nil
+		}
+		if req.Limit > 0 && req.Limit < len(names) {
+			names = names[:req.Limit]
+		}
+		out := make([]map[string]interface{}, len(names))
+		for i, name := range names {
+			o := b.objects[name]
+			out[i] = map[string]interface{}{
+				"name":       strings.TrimPrefix(name, prefix),
+				"id":         name,
+				"created_at": o.createdAt.Format(time.RFC3339Nano),
+				"updated_at": o.updatedAt.Format(time.RFC3339Nano),
+				"metadata":   map[string]interface{}{"size": len(o.data), "mimetype": o.contentType},
+			}
+		}
+		writeJSON(w, http.StatusOK, out)
+
+	case strings.HasPrefix(path, "object/"):
+		rest := strings.TrimPrefix(path, "object/")
+		rest = strings.TrimPrefix(rest, "public/")
+		rest = strings.TrimPrefix(rest, "authenticated/")
+		bucketID, name, _ := strings.Cut(rest, "/")
+		b, ok := s.storage[bucketID]
+		if !ok {
+			notFound("Bucket not found")
+			return
+		}
+		switch r.Method {
+		case http.MethodPost, http.MethodPut:
+			if _, exists := b.objects[name]; exists && r.Method == http.MethodPost && r.Header.Get("x-upsert") != "true" {
+				writeJSON(w, http.StatusConflict, storageError{"409", "Duplicate", "The resource already exists"})
+				return
+			}
+			data, err := io.ReadAll(r.Body)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, storageError{"400", "invalid_request", err.Error()})
+				return
+			}
+			now := s.now().UTC()
+			o := &object{data: data, contentType: r.Header.Get("Content-Type"), createdAt: now, updatedAt: now}
+			if old, exists := b.objects[name]; exists {
+				o.createdAt = old.createdAt
+			}
+			b.objects[name] = o
+			writeJSON(w, http.StatusOK, map[string]string{"Key": bucketID + "/" + name})
+		case http.MethodGet:
+			o, ok := b.objects[name]
+			if !ok {
+				notFound("Object not found")
+				return
+			}
+			if o.contentType != "" {
+				w.Header().Set("Content-Type", o.contentType)
+			}
+			w.Write(o.data)
+		case http.MethodDelete:
+			var req struct {
+				Prefixes []string `json:"prefixes"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			if name != "" {
+				req.Prefixes = append(req.Prefixes, name)
+			}
+			removed := []map[string]interface{}{}
+			for _, p := range req.Prefixes {
+				if _, ok := b.objects[p]; ok {
+					delete(b.objects, p)
+					removed = append(removed, map[string]interface{}{"name": p, "bucket_id": bucketID})
+				}
+			}
+			writeJSON(w, http.StatusOK, removed)
+		default:
+			writeJSON(w, http.StatusMethodNotAllowed, storageError{"405", "method_not_allowed", "method not allowed"})
+		}
+
+	default:
+		notFound("not found")
+	}
+}
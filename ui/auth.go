@@ -0,0 +1,446 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	authtypes "github.com/supabase-community/auth-go/types"
+
+	"zel/lo/internal"
+	"zel/lo/supabase"
+)
+
+// Auth modes. Sign-up is toggled with Ctrl+S; the others have their own
+// keys and return to sign-in with Esc.
+const (
+	authSignIn = iota
+	authSignUp
+	authPhone
+	// authCode signs in with a one-time code mailed to the user.
+	authCode
+	// authReset sets a new password with the code from a reset email.
+	authReset
+	// authSSO signs in through an OAuth provider in the browser.
+	authSSO
+	// authMFA asks for an authenticator app code after a sign-in that
+	// needs one; Esc signs out again.
+	authMFA
+)
+
+type (
+	// codeSentMsg moves the code and reset modes on to asking for the code.
+	codeSentMsg struct{ email string }
+	// authErrMsg reports a failed sign-in inline in the auth view.
+	authErrMsg struct{ error }
+	// signedInMsg lands on the menu once a sign-in succeeds.
+	signedInMsg struct{ userID string }
+	// oauthStartedMsg carries an OAuth flow waiting for the browser.
+	oauthStartedMsg struct{ flow *supabase.OAuthFlow }
+	// mfaRequiredMsg stops a sign-in short of the menu until factor is
+	// verified.
+	mfaRequiredMsg struct {
+		userID string
+		factor authtypes.Factor
+	}
+)
+
+// afterSignIn lands on the menu, or first asks for a code from the
+// authenticator app when the account has one.
+func afterSignIn(session authtypes.Session) tea.Msg {
+	if f := internal.PendingFactor(session); f != nil {
+		return mfaRequiredMsg{session.User.ID.String(), *f}
+	}
+	return signedInMsg{session.User.ID.String()}
+}
+
+// authFields returns the inputs of the current auth mode in tab order.
+func (m *Model) authFields() []*textinput.Model {
+	switch m.authMode {
+	case authSignUp:
+		return []*textinput.Model{&m.emailInput, &m.nameInput, &m.passwordInput}
+	case authPhone:
+		return []*textinput.Model{&m.phoneInput, &m.passwordInput}
+	case authCode:
+		if m.codeSent {
+			return []*textinput.Model{&m.codeInput}
+		}
+		return []*textinput.Model{&m.emailInput}
+	case authReset:
+		if m.codeSent {
+			return []*textinput.Model{&m.codeInput, &m.passwordInput}
+		}
+		return []*textinput.Model{&m.emailInput}
+	case authSSO:
+		return []*textinput.Model{&m.providerInput}
+	case authMFA:
+		return []*textinput.Model{&m.codeInput}
+	}
+	return []*textinput.Model{&m.emailInput, &m.passwordInput}
+}
+
+// focusAuthField focuses the i-th field of the current mode, wrapping
+// around, and blurs every other auth input.
+func (m *Model) focusAuthField(i int) {
+	for _, in := range []*textinput.Model{&m.emailInput, &m.passwordInput, &m.nameInput, &m.phoneInput, &m.codeInput, &m.providerInput} {
+		in.Blur()
+	}
+	fields := m.authFields()
+	fields[(i+len(fields))%len(fields)].Focus()
+}
+
+func (m *Model) focusedAuthField() int {
+	for i, in := range m.authFields() {
+		if in.Focused() {
+			return i
+		}
+	}
+	return 0
+}
+
+func (m *Model) setAuthMode(mode int) {
+	if m.oauthCancel != nil {
+		m.oauthCancel()
+		m.oauthCancel = nil
+	}
+	m.authMode = mode
+	m.codeSent = false
+	m.mfaFactor = nil
+	m.err = nil
+	m.message = ""
+	m.codeInput.SetValue("")
+	m.passwordInput.Placeholder = "password"
+	m.focusAuthField(0)
+}
+
+func (m *Model) updateAuthKeys(k tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.authMode == authMFA {
+		return m.updateMFAKeys(k)
+	}
+	switch k.String() {
+	case "tab", "down":
+		m.focusAuthField(m.focusedAuthField() + 1)
+		return m, nil
+	case "shift+tab", "up":
+		m.focusAuthField(m.focusedAuthField() - 1)
+		return m, nil
+	case "ctrl+s":
+		if m.authMode == authSignUp {
+			m.setAuthMode(authSignIn)
+		} else {
+			m.setAuthMode(authSignUp)
+		}
+		return m, nil
+	case "ctrl+p":
+		m.setAuthMode(authPhone)
+		return m, nil
+	case "ctrl+o":
+		m.setAuthMode(authCode)
+		return m, nil
+	case "ctrl+r":
+		m.setAuthMode(authReset)
+		return m, nil
+	case "ctrl+g":
+		m.setAuthMode(authSSO)
+		return m, nil
+	case "enter":
+		return m.submitAuth()
+	case "esc":
+		if m.authMode != authSignIn {
+			m.setAuthMode(authSignIn)
+			return m, nil
+		}
+		return m, tea.Quit
+	case "q":
+		if m.authMode == authSignIn || m.authMode == authSignUp {
+			return m, tea.Quit
+		}
+	}
+	var cmds []tea.Cmd
+	for _, in := range m.authFields() {
+		var cmd tea.Cmd
+		*in, cmd = in.Update(k)
+		cmds = append(cmds, cmd)
+	}
+	return m, tea.Batch(cmds...)
+}
+
+func (m *Model) submitAuth() (tea.Model, tea.Cmd) {
+	email := strings.TrimSpace(m.emailInput.Value())
+	pass := m.passwordInput.Value()
+	code := strings.TrimSpace(m.codeInput.Value())
+	client := m.client
+	m.err = nil
+
+	switch m.authMode {
+	case authSSO:
+		provider := strings.ToLower(strings.TrimSpace(m.providerInput.Value()))
+		if provider == "" {
+			m.err = fmt.Errorf("enter a provider such as github or google")
+			return m, nil
+		}
+		if m.oauthCancel != nil {
+			return m, nil
+		}
+		return m, func() tea.Msg {
+			flow, err := client.StartOAuth(supabase.OAuthOptions{Provider: authtypes.Provider(provider)})
+			if err != nil {
+				return authErrMsg{err}
+			}
+			return oauthStartedMsg{flow}
+		}
+
+	case authPhone:
+		phone := strings.TrimSpace(m.phoneInput.Value())
+		if m.err = internal.ValidatePhone(phone); m.err != nil {
+			return m, nil
+		}
+		if pass == "" {
+			m.err = fmt.Errorf("fill required fields")
+			return m, nil
+		}
+		return m, func() tea.Msg {
+			session, err := client.SignInWithPhonePassword(phone, pass)
+			if err != nil {
+				return authErrMsg{err}
+			}
+			return afterSignIn(session)
+		}
+
+	case authCode, authReset:
+		if !m.codeSent {
+			if m.err = internal.ValidateEmail(email); m.err != nil {
+				return m, nil
+			}
+			send := internal.SendSignInCode
+			if m.authMode == authReset {
+				send = internal.SendPasswordReset
+			}
+			return m, func() tea.Msg {
+				if err := send(client, email); err != nil {
+					return authErrMsg{err}
+				}
+				return codeSentMsg{email}
+			}
+		}
+		if code == "" {
+			m.err = fmt.Errorf("enter the code from the email")
+			return m, nil
+		}
+		if m.authMode == authCode {
+			return m, func() tea.Msg {
+				session, err := internal.SignInWithCode(client, email, code)
+				if err != nil {
+					return authErrMsg{err}
+				}
+				return afterSignIn(session)
+			}
+		}
+		if m.err = internal.ValidatePassword(pass); m.err != nil {
+			return m, nil
+		}
+		return m, func() tea.Msg {
+			session, err := internal.ResetPassword(client, email, code, pass)
+			if err != nil {
+				return authErrMsg{err}
+			}
+			return afterSignIn(session)
+		}
+	}
+
+	name := strings.TrimSpace(m.nameInput.Value())
+	if email == "" || pass == "" || (m.authMode == authSignUp && name == "") {
+		m.err = fmt.Errorf("fill required fields")
+		return m, nil
+	}
+	if m.authMode == authSignUp {
+		return m.submitSignup(email, pass, name)
+	}
+	return m.submitSignin(email, pass)
+}
+
+func (m *Model) authCodeSent(msg codeSentMsg) (tea.Model, tea.Cmd) {
+	m.codeSent = true
+	m.err = nil
+	m.message = "We emailed a code to " + msg.email + "."
+	m.codeInput.SetValue("")
+	if m.authMode == authReset {
+		m.passwordInput.SetValue("")
+		m.passwordInput.Placeholder = "new password"
+	}
+	m.focusAuthField(0)
+	return m, nil
+}
+
+// oauthStarted opens the browser on the provider and waits for it to come
+// back; Esc or another auth mode abandons the wait.
+func (m *Model) oauthStarted(msg oauthStartedMsg) (tea.Model, tea.Cmd) {
+	if m.authMode != authSSO {
+		msg.flow.Close()
+		return m, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), supabase.OAuthTimeout)
+	m.oauthCancel = cancel
+	m.err = nil
+	m.message = "Continue in your browser. If it did not open, visit:\n" + msg.flow.URL
+	internal.OpenBrowser(msg.flow.URL)
+	return m, func() tea.Msg {
+		defer cancel()
+		session, err := msg.flow.Wait(ctx)
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+		if err != nil {
+			return authErrMsg{err}
+		}
+		return afterSignIn(session)
+	}
+}
+
+// mfaRequired switches to asking for the code of msg.factor. The client
+// keeps the first-step session meanwhile.
+func (m *Model) mfaRequired(msg mfaRequiredMsg) (tea.Model, tea.Cmd) {
+	m.setAuthMode(authMFA)
+	m.mfaFactor = &msg.factor
+	m.mfaUserID = msg.userID
+	name := msg.factor.FriendlyName
+	if name == "" {
+		name = "your authenticator app"
+	}
+	m.message = "Enter the code " + name + " shows."
+	return m, nil
+}
+
+func (m *Model) updateMFAKeys(k tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch k.String() {
+	case "enter":
+		code := strings.TrimSpace(m.codeInput.Value())
+		if m.err = internal.ValidateTOTPCode(code); m.err != nil {
+			return m, nil
+		}
+		client, factorID, userID := m.client, m.mfaFactor.ID, m.mfaUserID
+		return m, func() tea.Msg {
+			if _, err := internal.VerifyMFA(client, factorID, code); err != nil {
+				return authErrMsg{err}
+			}
+			return signedInMsg{userID}
+		}
+	case "esc":
+		// Drop the half-finished session rather than keep it around.
+		client := m.client
+		m.setAuthMode(authSignIn)
+		return m, func() tea.Msg {
+			client.Auth.Logout()
+			return nil
+		}
+	}
+	var cmd tea.Cmd
+	m.codeInput, cmd = m.codeInput.Update(k)
+	return m, cmd
+}
+
+func (m *Model) signedIn(msg signedInMsg) (tea.Model, tea.Cmd) {
+	m.userID = msg.userID
+	m.err = nil
+	m.message = ""
+	m.setAuthMode(authSignIn)
+	m.passwordInput.SetValue("")
+	m.view = viewMain
+	return m, tea.Batch(m.loadSavedViews(), m.loadBoard())
+}
+
+func (m *Model) submitSignin(email, pass string) (tea.Model, tea.Cmd) {
+	m.err = nil
+	client := m.client
+	return m, func() tea.Msg {
+		session, err := client.SignInWithEmailPassword(email, pass)
+		if err != nil {
+			return messageErr{err}
+		}
+		return afterSignIn(session)
+	}
+}
+
+func (m *Model) submitSignup(email, pass, name string) (tea.Model, tea.Cmd) {
+	m.err = nil
+	client := m.client
+	return m, func() tea.Msg {
+		_, err := client.Auth.Signup(authtypes.SignupRequest{
+			Email:    email,
+			Password: pass,
+			Data:     map[string]interface{}{"name": name},
+		})
+		if err != nil {
+			return messageErr{err}
+		}
+		session, err := client.SignInWithEmailPassword(email, pass)
+		if err != nil {
+			return messageErr{err}
+		}
+		if _, err := internal.CreateProfile(client, session.User.ID.String(), name); err != nil {
+			return messageErr{err}
+		}
+		return afterSignIn(session)
+	}
+}
+
+func (m Model) viewAuth() string {
+	var title, fields, help string
+	switch m.authMode {
+	case authSignIn:
+		title = "Sign In"
+		fields = "Email:    " + m.emailInput.View() + "\nPassword: " + m.passwordInput.View()
+		help = "Enter to submit • Tab to switch • Ctrl+S toggle sign-in/sign-up • Q to quit\n" +
+			"Ctrl+P phone sign-in • Ctrl+O email me a code • Ctrl+G GitHub, Google, ... • Ctrl+R forgot password"
+	case authSignUp:
+		title = "Sign Up"
+		fields = "Email:    " + m.emailInput.View() + "\nName:     " + m.nameInput.View() + "\nPassword: " + m.passwordInput.View()
+		help = "Enter to submit • Tab to switch • Ctrl+S toggle sign-in/sign-up • Q to quit"
+	case authPhone:
+		title = "Sign In with Phone"
+		fields = "Phone:    " + m.phoneInput.View() + "\nPassword: " + m.passwordInput.View()
+		help = "Enter to submit • Tab to switch • Esc to back"
+	case authCode:
+		title = "Sign In with Email Code"
+		if m.codeSent {
+			fields = "Code:     " + m.codeInput.View()
+			help = "Enter to sign in • Ctrl+O to start over • Esc to back"
+		} else {
+			fields = "Email:    " + m.emailInput.View()
+			help = "Enter to email me a code • Esc to back"
+		}
+	case authSSO:
+		title = "Sign In with GitHub, Google, ..."
+		fields = "Provider: " + m.providerInput.View()
+		help = "Enter to continue in the browser • Esc to back"
+		if m.oauthCancel != nil {
+			help = "Waiting for the browser • Esc to cancel"
+		}
+	case authMFA:
+		title = "Two-Step Sign In"
+		fields = "Code:     " + m.codeInput.View()
+		help = "Enter to verify • Esc to sign out"
+	case authReset:
+		title = "Reset Password"
+		if m.codeSent {
+			fields = "Code:     " + m.codeInput.View() + "\nPassword: " + m.passwordInput.View()
+			help = "Enter to set password • Tab to switch • Ctrl+R to start over • Esc to back"
+		} else {
+			fields = "Email:    " + m.emailInput.View()
+			help = "Enter to email me a reset code • Esc to back"
+		}
+	}
+
+	b := &strings.Builder{}
+	fmt.Fprintln(b, appTitleStyle.Render("Zello"))
+	fmt.Fprintln(b, cardStyle.Render(sectionTitleStyle.Render(title)+"\n\n"+fields+"\n\n"+help))
+	if m.err != nil {
+		fmt.Fprintln(b, errorStyle.Render(m.err.Error()))
+	} else if m.message != "" {
+		fmt.Fprintln(b, helpStyle.Render(m.message))
+	}
+	return b.String()
+}
@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"zel/lo/internal"
+)
+
+type bulkResultMsg struct {
+	op     internal.BulkOp
+	result *internal.BulkResult
+}
+
+// selectedIssues returns the selected issues in list order, or the issue
+// under the cursor when nothing is selected.
+func (m Model) selectedIssues() []internal.Issue {
+	var out []internal.Issue
+	for _, is := range m.issues {
+		if m.selected[is.ID] {
+			out = append(out, is)
+		}
+	}
+	if len(out) == 0 && m.cursor < len(m.issues) {
+		out = append(out, m.issues[m.cursor])
+	}
+	return out
+}
+
+func (m *Model) toggleSelected() {
+	if m.cursor >= len(m.issues) {
+		return
+	}
+	id := m.issues[m.cursor].ID
+	if m.selected[id] {
+		delete(m.selected, id)
+	} else {
+		m.selected[id] = true
+	}
+}
+
+// toggleSelectAll selects every issue matching the current filter, or clears
+// the selection when they are all selected already.
+func (m *Model) toggleSelectAll() {
+	if len(m.selected) == len(m.issues) {
+		m.selected = map[int]bool{}
+		return
+	}
+	for _, is := range m.issues {
+		m.selected[is.ID] = true
+	}
+}
+
+func (m *Model) startBulk() (tea.Model, tea.Cmd) {
+	if len(m.issues) == 0 {
+		return m, nil
+	}
+	m.bulking = true
+	m.err = nil
+	m.bulkReport = ""
+	m.bulkInput.SetValue("")
+	m.bulkInput.Focus()
+	return m, nil
+}
+
+func (m *Model) updateBulkKeys(k tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch k.String() {
+	case "esc":
+		m.bulking = false
+		m.bulkInput.Blur()
+		return m, nil
+	case "enter":
+		op, err := internal.ParseBulkOp(m.bulkInput.Value())
+		if err != nil {
+			m.err = err
+			return m, nil
+		}
+		m.err = nil
+		m.bulking = false
+		m.bulkInput.Blur()
+		store, userID, issues := m.store, m.userID, m.selectedIssues()
+		return m, func() tea.Msg {
+			return bulkResultMsg{op, internal.BulkUpdateIssues(context.Background(), store, issues, op, userID)}
+		}
+	}
+	var cmd tea.Cmd
+	m.bulkInput, cmd = m.bulkInput.Update(k)
+	return m, cmd
+}
+
+func (m *Model) applyBulkResult(msg bulkResultMsg) (tea.Model, tea.Cmd) {
+	m.selected = map[int]bool{}
+	verb := "updated"
+	if msg.op.Action == internal.BulkDelete {
+		verb = "deleted"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d %s", len(msg.result.Succeeded), verb)
+	if n := len(msg.result.Failures); n > 0 {
+		fmt.Fprintf(&b, ", %d failed:", n)
+		for _, f := range msg.result.Failures {
+			fmt.Fprintf(&b, "\n  #%d: %v", f.IssueID, f.Err)
+		}
+	}
+	m.bulkReport = b.String()
+	return m.fetchIssues()
+}
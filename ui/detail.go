@@ -0,0 +1,396 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"zel/lo/internal"
+	"zel/lo/supabase"
+)
+
+// Prompts shown at the bottom of the detail view.
+const (
+	promptNone = iota
+	promptParent
+	promptLink
+	promptDuplicate
+	promptEstimate
+	promptComment
+	promptLabels
+)
+
+type issueDetailMsg struct {
+	issue    internal.Issue
+	children []internal.Issue
+	links    []internal.IssueLink
+	// linked holds the issues on the other end of links, by id.
+	linked   map[int]internal.Issue
+	worklogs []internal.Worklog
+	comments []internal.Comment
+}
+
+// detailErrMsg reports an error inline in the detail view instead of
+// leaving it for the message screen.
+type detailErrMsg struct{ error }
+
+func loadIssueDetail(store internal.IssueStore, client *supabase.Client, id int) tea.Msg {
+	ctx := context.Background()
+	issue, err := store.GetIssue(ctx, id)
+	if err != nil {
+		return messageErr{err}
+	}
+	children, err := store.ListChildIssues(ctx, id)
+	if err != nil {
+		return messageErr{err}
+	}
+	comments, err := store.ListComments(ctx, id)
+	if err != nil {
+		return messageErr{err}
+	}
+	links, err := store.ListLinks(ctx, id)
+	if err != nil {
+		return messageErr{err}
+	}
+	linked := map[int]internal.Issue{}
+	for _, l := range links {
+		_, other := l.Relation(id)
+		if _, ok := linked[other]; ok {
+			continue
+		}
+		if is, err := store.GetIssue(ctx, other); err == nil {
+			linked[other] = *is
+		}
+	}
+	// Worklogs live outside the issue store.
+	var worklogs []internal.Worklog
+	if client != nil {
+		worklogs, err = internal.ListWorklogs(client, id)
+		if err != nil {
+			return messageErr{err}
+		}
+	}
+	return issueDetailMsg{*issue, children, links, linked, worklogs, comments}
+}
+
+func (m *Model) openIssueDetail(id int) (tea.Model, tea.Cmd) {
+	store, client := m.store, m.client
+	return m, func() tea.Msg { return loadIssueDetail(store, client, id) }
+}
+
+func (m *Model) startDetailPrompt(mode int, prompt, placeholder string) (tea.Model, tea.Cmd) {
+	m.detailPrompt = mode
+	m.err = nil
+	m.detailInput.Prompt = prompt
+	m.detailInput.Placeholder = placeholder
+	m.detailInput.SetValue("")
+	m.detailInput.Focus()
+	return m, nil
+}
+
+// closeIssue closes the issue in the detail view; blockers that are still
+// open stop it unless force is set.
+func (m *Model) closeIssue(force bool) (tea.Model, tea.Cmd) {
+	store, client, id, userID := m.store, m.client, m.detail.ID, m.userID
+	return m, func() tea.Msg {
+		req := internal.TransitionRequest{IssueID: id, Status: "closed", Force: force}
+		if _, err := store.TransitionIssue(context.Background(), req, userID); err != nil {
+			return detailErrMsg{err}
+		}
+		return loadIssueDetail(store, client, id)
+	}
+}
+
+func (m *Model) updateDetailKeys(k tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.detailPrompt != promptNone {
+		return m.updateDetailPromptKeys(k)
+	}
+	switch k.String() {
+	case "esc", "q":
+		m.err = nil
+		return m.fetchIssues()
+	case "t":
+		// The timer logs to worklogs, which live in Supabase.
+		if m.client == nil {
+			return m, nil
+		}
+		if m.timerIssue != nil {
+			return m.toggleTimer(*m.timerIssue)
+		}
+		return m.toggleTimer(m.detail)
+	case "n":
+		parentID := m.detail.ID
+		m.parentID = &parentID
+		return m.openTemplatePicker()
+	case "p":
+		return m.startDetailPrompt(promptParent, "Parent: #", "issue number, empty for none")
+	case "l":
+		return m.startDetailPrompt(promptLink, "Link: ", "blocks 12 | blocked-by 12 | duplicates 12 | relates 12 | unlink 12")
+	case "d":
+		return m.startDetailPrompt(promptDuplicate, "Duplicate of: #", "issue number")
+	case "enter":
+		return m.editIssue()
+	case "e":
+		return m.startDetailPrompt(promptEstimate, "Estimate: ", "story points, empty to clear")
+	case "c":
+		return m.startDetailPrompt(promptComment, "Comment: ", "")
+	case "a":
+		m.startDetailPrompt(promptLabels, "Labels: ", "comma separated, empty for none")
+		m.detailInput.SetValue(strings.Join(m.detail.Labels, ", "))
+		m.detailInput.CursorEnd()
+		return m, nil
+	case "x":
+		return m.closeIssue(false)
+	case "X":
+		return m.closeIssue(true)
+	}
+	return m, nil
+}
+
+func (m *Model) updateDetailPromptKeys(k tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch k.String() {
+	case "esc":
+		m.detailPrompt = promptNone
+		m.detailInput.Blur()
+		return m, nil
+	case "enter":
+		run, err := m.detailPromptAction(strings.TrimSpace(m.detailInput.Value()))
+		if err != nil {
+			m.err = err
+			return m, nil
+		}
+		m.detailPrompt = promptNone
+		m.detailInput.Blur()
+		store, client, id := m.store, m.client, m.detail.ID
+		return m, func() tea.Msg {
+			if err := run(context.Background(), store); err != nil {
+				return detailErrMsg{err}
+			}
+			return loadIssueDetail(store, client, id)
+		}
+	}
+	var cmd tea.Cmd
+	m.detailInput, cmd = m.detailInput.Update(k)
+	return m, cmd
+}
+
+// detailPromptAction validates the prompt input and returns the request to
+// run for it.
+func (m *Model) detailPromptAction(input string) (func(context.Context, internal.IssueStore) error, error) {
+	id, userID := m.detail.ID, m.userID
+	switch m.detailPrompt {
+	case promptComment:
+		if input == "" {
+			return nil, fmt.Errorf("comment cannot be empty")
+		}
+		return func(ctx context.Context, store internal.IssueStore) error {
+			_, err := store.CreateComment(ctx, id, userID, input)
+			return err
+		}, nil
+	case promptLabels:
+		labels := splitLabels(input)
+		return func(ctx context.Context, store internal.IssueStore) error {
+			_, err := store.SetLabels(ctx, id, labels)
+			return err
+		}, nil
+	case promptParent:
+		// An empty parent makes the issue top-level again.
+		var parentID *int
+		if input != "" {
+			other, err := parseIssueNumber(input)
+			if err != nil {
+				return nil, err
+			}
+			parentID = &other
+		}
+		return func(ctx context.Context, store internal.IssueStore) error {
+			_, err := store.SetParent(ctx, id, parentID)
+			return err
+		}, nil
+	case promptEstimate:
+		var points *float64
+		if input != "" {
+			v, err := strconv.ParseFloat(input, 64)
+			if err != nil {
+				return nil, fmt.Errorf("estimate must be a number of points")
+			}
+			points = &v
+		}
+		return func(ctx context.Context, store internal.IssueStore) error {
+			_, err := store.SetEstimate(ctx, id, points)
+			return err
+		}, nil
+	case promptDuplicate:
+		other, err := parseIssueNumber(input)
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, store internal.IssueStore) error {
+			return internal.CloseAsDuplicate(ctx, store, id, other, userID)
+		}, nil
+	case promptLink:
+		verb, arg, _ := strings.Cut(input, " ")
+		other, err := parseIssueNumber(arg)
+		if err != nil {
+			return nil, err
+		}
+		link := func(source, target int, kind internal.LinkKind) func(context.Context, internal.IssueStore) error {
+			return func(ctx context.Context, store internal.IssueStore) error {
+				_, err := store.LinkIssues(ctx, source, target, kind)
+				return err
+			}
+		}
+		switch verb {
+		case "blocks":
+			return link(id, other, internal.LinkBlocks), nil
+		case "blocked-by":
+			return link(other, id, internal.LinkBlocks), nil
+		case "duplicates":
+			return link(id, other, internal.LinkDuplicates), nil
+		case "relates":
+			return link(id, other, internal.LinkRelatesTo), nil
+		case "unlink":
+			return func(ctx context.Context, store internal.IssueStore) error {
+				return store.UnlinkIssues(ctx, id, other)
+			}, nil
+		}
+		return nil, fmt.Errorf("unknown link type %q", verb)
+	}
+	return nil, fmt.Errorf("no prompt open")
+}
+
+// splitLabels reads a comma separated label list, dropping blanks and
+// repeats.
+func splitLabels(s string) []string {
+	var labels []string
+	for _, l := range strings.Split(s, ",") {
+		if l = strings.TrimSpace(l); l != "" && !slices.Contains(labels, l) {
+			labels = append(labels, l)
+		}
+	}
+	return labels
+}
+
+func parseIssueNumber(s string) (int, error) {
+	id, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(s), "#"))
+	if err != nil {
+		return 0, fmt.Errorf("expected an issue number, got %q", s)
+	}
+	return id, nil
+}
+
+func (m Model) viewIssueDetail() string {
+	is := m.detail
+	var b strings.Builder
+	key := is.Key(m.board)
+	if id := fmt.Sprintf("#%d", is.ID); key != id {
+		key += " " + helpStyle.Render(id)
+	}
+	fmt.Fprintf(&b, "%s\n", sectionTitleStyle.Render(key+" "+is.Title))
+	fmt.Fprintf(&b, "Status: %s\n", is.Status)
+	if len(is.Labels) > 0 {
+		fmt.Fprintf(&b, "Labels: %s\n", strings.Join(is.Labels, ", "))
+	}
+	if is.ParentID != nil {
+		fmt.Fprintf(&b, "Parent: #%d\n", *is.ParentID)
+	}
+	if is.Estimate != nil {
+		fmt.Fprintf(&b, "Estimate: %s pts\n", strconv.FormatFloat(*is.Estimate, 'f', -1, 64))
+	}
+	if len(m.worklogs) > 0 {
+		totals := internal.SumWorklogs(m.worklogs)
+		fmt.Fprintf(&b, "Logged: %s", internal.FormatDuration(totals.Total))
+		for _, u := range totals.SortedUsers() {
+			who := shortID(u)
+			if u == m.userID {
+				who = "you"
+			}
+			fmt.Fprintf(&b, " • %s %s", who, internal.FormatDuration(totals.ByUser[u]))
+		}
+		fmt.Fprintln(&b)
+	}
+	if is.Description != "" {
+		fmt.Fprintf(&b, "\n%s\n", is.Description)
+	}
+
+	if len(m.children) > 0 {
+		done, total := internal.ChildProgress(m.children)
+		fmt.Fprintf(&b, "\nSub-issues %d/%d\n%s\n", done, total, m.progress.ViewAs(float64(done)/float64(total)))
+		for _, c := range m.children {
+			check := "[ ]"
+			if c.Closed() {
+				check = "[x]"
+			}
+			fmt.Fprintf(&b, "%s #%-4d %s\n", check, c.ID, c.Title)
+		}
+	}
+
+	if len(m.links) > 0 {
+		fmt.Fprintf(&b, "\nLinks\n")
+		for _, l := range m.links {
+			rel, other := l.Relation(is.ID)
+			title := ""
+			if o, ok := m.linked[other]; ok {
+				title = o.Title
+				if o.Closed() {
+					title += " (closed)"
+				}
+			}
+			fmt.Fprintf(&b, "%-13s #%-4d %s\n", rel, other, title)
+		}
+	}
+
+	if len(m.comments) > 0 {
+		fmt.Fprintf(&b, "\nComments\n")
+		for _, c := range m.comments {
+			who := shortID(c.UserID)
+			if c.UserID == m.userID {
+				who = "you"
+			}
+			fmt.Fprintf(&b, "%s %s\n%s\n", helpStyle.Render(shortDate(c.CreatedAt)), who, c.Body)
+		}
+	}
+
+	if m.detailPrompt != promptNone {
+		fmt.Fprintf(&b, "\n%s\n", m.detailInput.View())
+	}
+	if m.err != nil {
+		fmt.Fprintf(&b, "\n%s\n", errorStyle.Render(m.err.Error()))
+	}
+	if t := m.viewTimer(); t != "" {
+		fmt.Fprintln(&b, t)
+	}
+
+	help := "Enter to edit • N for sub-issue • P to set parent • E to estimate • A to edit labels • C to comment • T to start/stop timer • L to link • X to close (shift to force) • D to close as duplicate • Esc to back"
+	if m.client == nil {
+		help = strings.Replace(help, " • T to start/stop timer", "", 1)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		sectionTitleStyle.Render("Issue"),
+		cardStyle.Render(b.String()+"\n"+help),
+	)
+}
+
+// treePrefix indents sub-issues under their parent in the list view.
+func treePrefix(depth int) string {
+	if depth == 0 {
+		return ""
+	}
+	return strings.Repeat("  ", depth-1) + "└─ "
+}
+
+// childSummary renders a compact progress bar for parents in the list view.
+func childSummary(children []internal.Issue) string {
+	done, total := internal.ChildProgress(children)
+	if total == 0 {
+		return ""
+	}
+	const width = 10
+	filled := done * width / total
+	return fmt.Sprintf(" [%s%s] %d/%d", strings.Repeat("█", filled), strings.Repeat("░", width-filled), done, total)
+}
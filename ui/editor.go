@@ -0,0 +1,59 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"zel/lo/internal"
+)
+
+type editorFinishedMsg struct {
+	path string
+	err  error
+}
+
+// openEditor suspends the program and edits the description in $VISUAL or
+// $EDITOR, reading the file back once the editor exits.
+func (m *Model) openEditor() (tea.Model, tea.Cmd) {
+	path, err := internal.WriteEditorFile(m.descriptionInput.Value())
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	cmd, err := internal.EditorCommand(path)
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	return m, tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return editorFinishedMsg{path, err}
+	})
+}
+
+func (m *Model) editorFinished(msg editorFinishedMsg) (tea.Model, tea.Cmd) {
+	text, err := internal.ReadEditorFile(msg.path)
+	if msg.err != nil {
+		m.err = msg.err
+		return m, nil
+	}
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	m.err = nil
+	m.descriptionInput.SetValue(text)
+	return m, nil
+}
+
+// editIssue opens the issue in the detail view in the create form.
+func (m *Model) editIssue() (tea.Model, tea.Cmd) {
+	id := m.detail.ID
+	m.editingID = &id
+	m.parentID, m.createLabels = nil, nil
+	m.titleInput.SetValue(m.detail.Title)
+	m.titleInput.CursorEnd()
+	m.descriptionInput.SetValue(m.detail.Description)
+	m.view = viewCreateIssue
+	m.titleInput.Focus()
+	m.descriptionInput.Blur()
+	return m, nil
+}
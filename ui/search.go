@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"zel/lo/internal"
+)
+
+// searchDebounce is how long typing has to pause before a search is sent.
+const searchDebounce = 250 * time.Millisecond
+
+type (
+	searchTickMsg    struct{ seq int }
+	searchResultsMsg struct {
+		seq     int
+		results []internal.SearchResult
+		err     error
+	}
+)
+
+func (m *Model) openSearch() (tea.Model, tea.Cmd) {
+	m.view = viewSearch
+	m.err = nil
+	m.searchInput.Focus()
+	return m, nil
+}
+
+func (m *Model) updateSearchKeys(k tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch k.String() {
+	case "esc":
+		m.searchInput.Blur()
+		m.view = viewMain
+		return m, nil
+	}
+
+	before := m.searchInput.Value()
+	var cmd tea.Cmd
+	m.searchInput, cmd = m.searchInput.Update(k)
+	if m.searchInput.Value() == before {
+		return m, cmd
+	}
+
+	// Every edit bumps the sequence; only the tick for the latest edit runs
+	// a query, and only results for the latest query are shown.
+	m.searchSeq++
+	seq := m.searchSeq
+	if strings.TrimSpace(m.searchInput.Value()) == "" {
+		m.searching = false
+		m.searchResults = nil
+		return m, cmd
+	}
+	return m, tea.Batch(cmd, tea.Tick(searchDebounce, func(time.Time) tea.Msg {
+		return searchTickMsg{seq}
+	}))
+}
+
+func (m *Model) runSearch(seq int) (tea.Model, tea.Cmd) {
+	if seq != m.searchSeq {
+		return m, nil
+	}
+	m.searching = true
+	store := m.store
+	query := m.searchInput.Value()
+	return m, func() tea.Msg {
+		results, err := store.SearchIssues(context.Background(), query)
+		return searchResultsMsg{seq: seq, results: results, err: err}
+	}
+}
+
+func (m Model) viewSearch() string {
+	var b strings.Builder
+	fmt.Fprintln(&b, m.searchInput.View())
+	fmt.Fprintln(&b)
+	switch {
+	case m.err != nil:
+		fmt.Fprintln(&b, errorStyle.Render(m.err.Error()))
+	case m.searching:
+		fmt.Fprintln(&b, helpStyle.Render("Searching..."))
+	case strings.TrimSpace(m.searchInput.Value()) == "":
+		fmt.Fprintln(&b, helpStyle.Render("Type to search titles, descriptions and comments."))
+	case len(m.searchResults) == 0:
+		fmt.Fprintln(&b, "No matches.")
+	}
+	for _, r := range m.searchResults {
+		fmt.Fprintf(&b, "#%-4d %-20s %-s\n", r.Issue.ID, r.Issue.Title, r.Issue.Description)
+		if r.Snippet != "" {
+			fmt.Fprintln(&b, helpStyle.Render("      ↳ "+r.Snippet))
+		}
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		sectionTitleStyle.Render("Search"),
+		cardStyle.Render(b.String()+"\nEsc to back"),
+	)
+}
@@ -0,0 +1,544 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	authtypes "github.com/supabase-community/auth-go/types"
+
+	"zel/lo/internal"
+	"zel/lo/supabase"
+)
+
+// Settings fields, in tab order.
+const (
+	settingName = iota
+	settingAvatar
+	settingEmail
+	settingCurrentPassword
+	settingNewPassword
+	settingConfirmPassword
+	// settingMFA names a new authenticator app, then takes its first code.
+	settingMFA
+	settingCount
+)
+
+// The verified authenticator apps follow the inputs in tab order, at
+// settingCount and up.
+
+const maxDisplayName = 80
+
+const (
+	mfaNamePlaceholder = "phone, laptop, ..."
+	mfaCodePlaceholder = "6-digit code from the app"
+)
+
+type (
+	// settingsMsg refreshes the settings view after loading or saving. Nil
+	// fields keep what the view already has; reset lists the inputs to
+	// refill from them.
+	settingsMsg struct {
+		account *authtypes.User
+		profile *internal.Profile
+		notice  string
+		reset   []int
+	}
+	// settingsErrMsg reports a failed save inline.
+	settingsErrMsg    struct{ error }
+	accountDeletedMsg struct{}
+	// enrolmentMsg shows a new authenticator app's QR code until its first
+	// code is confirmed.
+	enrolmentMsg struct {
+		enrolment *authtypes.EnrollFactorResponse
+		qr        string
+	}
+)
+
+func newSettingsInputs() []textinput.Model {
+	labels := [settingCount]string{
+		settingName:            "Display name",
+		settingAvatar:          "Image URL or file",
+		settingEmail:           "new address",
+		settingCurrentPassword: "current password",
+		settingNewPassword:     "at least 6 characters",
+		settingConfirmPassword: "new password again",
+		settingMFA:             mfaNamePlaceholder,
+	}
+	inputs := make([]textinput.Model, settingCount)
+	for i := range inputs {
+		in := textinput.New()
+		in.Prompt = ""
+		in.Placeholder = labels[i]
+		in.Width = 40
+		if i >= settingCurrentPassword && i <= settingConfirmPassword {
+			in.EchoMode = textinput.EchoPassword
+		}
+		inputs[i] = in
+	}
+	inputs[settingName].CharLimit = maxDisplayName
+	return inputs
+}
+
+// settingSection returns the fields saved together with field.
+func settingSection(field int) []int {
+	switch field {
+	case settingName, settingAvatar:
+		return []int{settingName, settingAvatar}
+	case settingEmail:
+		return []int{settingEmail}
+	case settingMFA:
+		return []int{settingMFA}
+	}
+	return []int{settingCurrentPassword, settingNewPassword, settingConfirmPassword}
+}
+
+func isURL(s string) bool {
+	return strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "http://")
+}
+
+func loadSettings(client *supabase.Client, userID string) tea.Msg {
+	account, err := internal.CurrentUser(client)
+	if err != nil {
+		return messageErr{err}
+	}
+	profile, err := internal.GetProfile(client, userID)
+	if err != nil {
+		return messageErr{err}
+	}
+	all := make([]int, settingCount)
+	for i := range all {
+		all[i] = i
+	}
+	return settingsMsg{account: account, profile: profile, reset: all}
+}
+
+func (m *Model) openSettings() (tea.Model, tea.Cmd) {
+	m.err = nil
+	m.settingsNotice = ""
+	m.settingsTried = map[int]bool{}
+	m.deletingAccount = false
+	m.endEnrolment()
+	client, userID := m.client, m.userID
+	return m, func() tea.Msg { return loadSettings(client, userID) }
+}
+
+func (m *Model) settingsLoaded(msg settingsMsg) (tea.Model, tea.Cmd) {
+	if msg.account != nil {
+		m.account = msg.account
+	}
+	if msg.profile != nil {
+		m.profile = msg.profile
+	}
+	for _, f := range msg.reset {
+		value := ""
+		switch f {
+		case settingName:
+			value = m.profile.DisplayName
+		case settingAvatar:
+			value = m.profile.AvatarURL
+		case settingMFA:
+			m.endEnrolment()
+		}
+		m.settingsInputs[f].SetValue(value)
+		delete(m.settingsTried, f)
+	}
+	m.settingsNotice = msg.notice
+	m.err = nil
+	if m.view != viewSettings {
+		m.view = viewSettings
+		m.focusSetting(settingName)
+	} else if m.settingsFocus >= settingCount+len(m.accountFactors()) {
+		// The focused app was just removed.
+		m.focusSetting(settingMFA)
+	}
+	return m, nil
+}
+
+// accountFactors returns the authenticator apps listed in settings.
+func (m Model) accountFactors() []authtypes.Factor {
+	if m.account == nil {
+		return nil
+	}
+	return internal.VerifiedFactors(*m.account)
+}
+
+// focusSetting moves to an input or, past the inputs, to an authenticator
+// app.
+func (m *Model) focusSetting(field int) {
+	if m.settingsFocus < settingCount {
+		m.settingsInputs[m.settingsFocus].Blur()
+	}
+	n := settingCount + len(m.accountFactors())
+	m.settingsFocus = (field + n) % n
+	if m.settingsFocus < settingCount {
+		m.settingsInputs[m.settingsFocus].Focus()
+	}
+}
+
+func (m *Model) enrolmentStarted(msg enrolmentMsg) (tea.Model, tea.Cmd) {
+	m.enrolment, m.enrolmentQR = msg.enrolment, msg.qr
+	m.settingsInputs[settingMFA].SetValue("")
+	m.settingsInputs[settingMFA].Placeholder = mfaCodePlaceholder
+	delete(m.settingsTried, settingMFA)
+	m.settingsNotice = ""
+	m.err = nil
+	return m, nil
+}
+
+func (m *Model) endEnrolment() {
+	m.enrolment, m.enrolmentQR = nil, ""
+	m.settingsInputs[settingMFA].Placeholder = mfaNamePlaceholder
+}
+
+// settingError validates one field as typed. Empty fields only count once
+// their section has been saved, so a fresh form is not covered in errors.
+func (m Model) settingError(field int) error {
+	value := m.settingsInputs[field].Value()
+	if value == "" && !m.settingsTried[field] {
+		return nil
+	}
+	switch field {
+	case settingName:
+		if strings.TrimSpace(value) == "" {
+			return fmt.Errorf("name required")
+		}
+	case settingAvatar:
+		value = strings.TrimSpace(value)
+		if value == "" || isURL(value) {
+			return nil
+		}
+		if info, err := os.Stat(value); err != nil || info.IsDir() {
+			return fmt.Errorf("not a URL or an image file")
+		}
+	case settingEmail:
+		value = strings.TrimSpace(value)
+		if err := internal.ValidateEmail(value); err != nil {
+			return err
+		}
+		if m.account != nil && strings.EqualFold(value, m.account.Email) {
+			return fmt.Errorf("that is already your email")
+		}
+	case settingCurrentPassword:
+		if value == "" {
+			return fmt.Errorf("current password required")
+		}
+	case settingNewPassword:
+		return internal.ValidatePassword(value)
+	case settingConfirmPassword:
+		if value != m.settingsInputs[settingNewPassword].Value() {
+			return fmt.Errorf("passwords do not match")
+		}
+	case settingMFA:
+		value = strings.TrimSpace(value)
+		if m.enrolment != nil {
+			return internal.ValidateTOTPCode(value)
+		}
+		for _, f := range m.accountFactors() {
+			if value != "" && f.FriendlyName == value {
+				return fmt.Errorf("you already have an app called %s", value)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Model) updateSettingsKeys(k tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.deletingAccount {
+		return m.updateDeleteAccountKeys(k)
+	}
+	switch k.String() {
+	case "esc":
+		if m.enrolment != nil {
+			return m.cancelEnrolment()
+		}
+		m.err = nil
+		m.view = viewMain
+		return m, nil
+	case "tab", "down":
+		m.focusSetting(m.settingsFocus + 1)
+		return m, nil
+	case "shift+tab", "up":
+		m.focusSetting(m.settingsFocus - 1)
+		return m, nil
+	case "enter":
+		if m.settingsFocus >= settingCount {
+			return m, nil
+		}
+		return m.saveSettings()
+	case "ctrl+x":
+		if m.settingsFocus >= settingCount {
+			return m.removeFactor(m.accountFactors()[m.settingsFocus-settingCount])
+		}
+	case "ctrl+d":
+		m.deletingAccount = true
+		m.err = nil
+		m.settingsNotice = ""
+		m.deleteInput.SetValue("")
+		m.deleteInput.Placeholder = m.account.Email
+		m.deleteInput.Focus()
+		return m, nil
+	}
+	if m.settingsFocus >= settingCount {
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.settingsInputs[m.settingsFocus], cmd = m.settingsInputs[m.settingsFocus].Update(k)
+	m.settingsNotice = ""
+	return m, cmd
+}
+
+// saveSettings saves the section holding the focused field once all of its
+// fields are valid.
+func (m *Model) saveSettings() (tea.Model, tea.Cmd) {
+	fields := settingSection(m.settingsFocus)
+	m.settingsNotice = ""
+	valid := true
+	for _, f := range fields {
+		m.settingsTried[f] = true
+		if m.settingError(f) != nil {
+			valid = false
+		}
+	}
+	if !valid {
+		return m, nil
+	}
+	m.err = nil
+
+	client, userID := m.client, m.userID
+	value := func(f int) string { return m.settingsInputs[f].Value() }
+	switch fields[0] {
+	case settingName:
+		name, avatar := strings.TrimSpace(value(settingName)), strings.TrimSpace(value(settingAvatar))
+		return m, func() tea.Msg {
+			update := internal.ProfileUpdate{DisplayName: &name}
+			if avatar != "" && !isURL(avatar) {
+				data, err := os.ReadFile(avatar)
+				if err != nil {
+					return settingsErrMsg{err}
+				}
+				if _, err := internal.UploadAvatar(client, userID, data); err != nil {
+					return settingsErrMsg{err}
+				}
+			} else {
+				update.AvatarURL = &avatar
+			}
+			profile, err := internal.UpdateProfile(client, userID, update)
+			if err != nil {
+				return settingsErrMsg{err}
+			}
+			return settingsMsg{profile: profile, notice: "Profile saved", reset: fields}
+		}
+	case settingEmail:
+		email := strings.TrimSpace(value(settingEmail))
+		return m, func() tea.Msg {
+			account, err := internal.ChangeEmail(client, email)
+			if err != nil {
+				return settingsErrMsg{err}
+			}
+			notice := fmt.Sprintf("Check %s for a confirmation link; your email changes once you follow it", email)
+			return settingsMsg{account: account, notice: notice, reset: fields}
+		}
+	case settingMFA:
+		if m.enrolment != nil {
+			factorID, code := m.enrolment.ID, strings.TrimSpace(value(settingMFA))
+			return m, func() tea.Msg {
+				if err := internal.ConfirmTOTP(client, factorID, code); err != nil {
+					return settingsErrMsg{err}
+				}
+				account, err := internal.CurrentUser(client)
+				if err != nil {
+					return settingsErrMsg{err}
+				}
+				return settingsMsg{account: account, notice: "Authenticator app added; signing in now asks for its code", reset: fields}
+			}
+		}
+		name := strings.TrimSpace(value(settingMFA))
+		return m, func() tea.Msg {
+			enrolment, err := internal.EnrollTOTP(client, name)
+			if err != nil {
+				return settingsErrMsg{err}
+			}
+			qr, err := internal.TerminalQR(enrolment.TOTP.URI)
+			if err != nil {
+				internal.RemoveFactor(client, enrolment.ID)
+				return settingsErrMsg{err}
+			}
+			return enrolmentMsg{enrolment, qr}
+		}
+	default:
+		email, current, password := m.account.Email, value(settingCurrentPassword), value(settingNewPassword)
+		return m, func() tea.Msg {
+			if err := internal.ChangePassword(client, email, current, password); err != nil {
+				return settingsErrMsg{err}
+			}
+			return settingsMsg{notice: "Password changed", reset: fields}
+		}
+	}
+}
+
+// cancelEnrolment drops an authenticator app whose first code never came.
+func (m *Model) cancelEnrolment() (tea.Model, tea.Cmd) {
+	client, factorID := m.client, m.enrolment.ID
+	m.endEnrolment()
+	m.settingsInputs[settingMFA].SetValue("")
+	delete(m.settingsTried, settingMFA)
+	m.err = nil
+	return m, func() tea.Msg {
+		if err := internal.RemoveFactor(client, factorID); err != nil {
+			return settingsErrMsg{err}
+		}
+		return settingsMsg{notice: "Authenticator app not added"}
+	}
+}
+
+func (m *Model) removeFactor(f authtypes.Factor) (tea.Model, tea.Cmd) {
+	client := m.client
+	m.err = nil
+	m.settingsNotice = ""
+	return m, func() tea.Msg {
+		if err := internal.RemoveFactor(client, f.ID); err != nil {
+			return settingsErrMsg{err}
+		}
+		account, err := internal.CurrentUser(client)
+		if err != nil {
+			return settingsErrMsg{err}
+		}
+		return settingsMsg{account: account, notice: "Removed " + factorName(f)}
+	}
+}
+
+func factorName(f authtypes.Factor) string {
+	if f.FriendlyName == "" {
+		return "authenticator app"
+	}
+	return f.FriendlyName
+}
+
+func (m *Model) updateDeleteAccountKeys(k tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch k.String() {
+	case "esc":
+		m.deletingAccount = false
+		m.deleteInput.Blur()
+		m.err = nil
+		return m, nil
+	case "enter":
+		if strings.TrimSpace(m.deleteInput.Value()) != m.account.Email {
+			m.err = fmt.Errorf("type %s to confirm", m.account.Email)
+			return m, nil
+		}
+		m.err = nil
+		client, userID := m.client, m.userID
+		return m, func() tea.Msg {
+			if err := internal.DeleteAccount(context.Background(), client, userID); err != nil {
+				return settingsErrMsg{err}
+			}
+			return accountDeletedMsg{}
+		}
+	}
+	var cmd tea.Cmd
+	m.deleteInput, cmd = m.deleteInput.Update(k)
+	return m, cmd
+}
+
+// accountDeleted signs out locally and returns to the sign-in screen.
+func (m *Model) accountDeleted() (tea.Model, tea.Cmd) {
+	m.userID = ""
+	m.account, m.profile = nil, nil
+	m.savedViews = nil
+	m.timerIssue = nil
+	m.deletingAccount = false
+	m.deleteInput.Blur()
+	m.err = nil
+	m.message = "Your account was deleted."
+	m.emailInput.SetValue("")
+	m.passwordInput.SetValue("")
+	m.nameInput.SetValue("")
+	m.emailInput.Focus()
+	m.view = viewAuth
+	return m, m.menu.SetItems(m.menuItems())
+}
+
+func (m Model) viewSettings() string {
+	var b strings.Builder
+	field := func(f int, label string) {
+		cursor := "  "
+		if f == m.settingsFocus && !m.deletingAccount {
+			cursor = "> "
+		}
+		fmt.Fprintf(&b, "%s%-9s %s\n", cursor, label, m.settingsInputs[f].View())
+		if err := m.settingError(f); err != nil {
+			fmt.Fprintf(&b, "  %-9s %s\n", "", errorStyle.Render(err.Error()))
+		}
+	}
+
+	fmt.Fprintln(&b, sectionTitleStyle.Render("Profile"))
+	field(settingName, "Name:")
+	field(settingAvatar, "Avatar:")
+
+	fmt.Fprintln(&b, "\n"+sectionTitleStyle.Render("Email"))
+	if m.account != nil {
+		fmt.Fprintf(&b, "  %-9s %s\n", "Current:", m.account.Email)
+		if m.account.EmailChange != "" {
+			fmt.Fprintf(&b, "  %-9s %s\n", "", helpStyle.Render("pending change to "+m.account.EmailChange+" until confirmed"))
+		}
+	}
+	field(settingEmail, "New:")
+
+	fmt.Fprintln(&b, "\n"+sectionTitleStyle.Render("Password"))
+	field(settingCurrentPassword, "Current:")
+	field(settingNewPassword, "New:")
+	field(settingConfirmPassword, "Confirm:")
+
+	fmt.Fprintln(&b, "\n"+sectionTitleStyle.Render("Two-Step Sign In"))
+	if m.enrolment != nil {
+		fmt.Fprint(&b, m.enrolmentQR)
+		fmt.Fprintln(&b, "Scan this with your authenticator app, or enter the key or link by hand:")
+		fmt.Fprintln(&b, m.enrolment.TOTP.Secret)
+		fmt.Fprintln(&b, m.enrolment.TOTP.URI)
+		field(settingMFA, "Code:")
+	} else {
+		field(settingMFA, "Add app:")
+	}
+	factors := m.accountFactors()
+	for i, f := range factors {
+		cursor := "  "
+		if settingCount+i == m.settingsFocus && !m.deletingAccount {
+			cursor = "> "
+		}
+		fmt.Fprintf(&b, "%s%-9s %s, added %s\n", cursor, "App:", factorName(f), f.CreatedAt.Format("2006-01-02"))
+	}
+	if len(factors) == 0 && m.enrolment == nil {
+		fmt.Fprintf(&b, "  %-9s %s\n", "", helpStyle.Render("off; signing in takes only your password"))
+	}
+
+	if m.deletingAccount {
+		fmt.Fprintln(&b, "\n"+errorStyle.Render("Delete account")+"\n"+
+			"Your profile goes; your issues and comments stay. Type your email to confirm.\n"+
+			m.deleteInput.View())
+	}
+	if m.settingsNotice != "" {
+		fmt.Fprintln(&b, "\n"+m.settingsNotice)
+	}
+	if m.err != nil {
+		fmt.Fprintln(&b, "\n"+errorStyle.Render(m.err.Error()))
+	}
+
+	help := "Enter to save section • Tab to move • Ctrl+D to delete account • Esc to back"
+	switch {
+	case m.deletingAccount:
+		help = "Enter to delete for good • Esc to cancel"
+	case m.settingsFocus >= settingCount:
+		help = "Ctrl+X to remove app • Tab to move • Esc to back"
+	case m.enrolment != nil:
+		help = "Enter to confirm code • Tab to move • Esc to cancel adding the app"
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		sectionTitleStyle.Render("Settings"),
+		cardStyle.Render(b.String()+"\n"+help),
+	)
+}
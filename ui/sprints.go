@@ -0,0 +1,335 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"zel/lo/internal"
+	"zel/lo/supabase"
+)
+
+// burndownHeight is the number of rows in the burndown chart.
+const burndownHeight = 8
+
+type boardMsg struct{ board *internal.Board }
+
+type planningMsg struct {
+	board        *internal.Board
+	sprints      []internal.Sprint
+	sprintIndex  int
+	backlog      []internal.Issue
+	sprintIssues []internal.Issue
+	burndown     []internal.BurndownPoint
+	statusCounts []internal.StatusCount
+}
+
+func (m Model) loadBoard() tea.Cmd {
+	store, key := m.store, m.boardKey
+	if m.userID == "" || m.board != nil {
+		return nil
+	}
+	return func() tea.Msg {
+		// Without a board issues are simply created unassigned, so a missing
+		// board is only reported once the planning view needs one.
+		board, err := store.CurrentBoard(context.Background(), key)
+		if err != nil || board == nil {
+			return nil
+		}
+		return boardMsg{board}
+	}
+}
+
+// loadPlanning fetches the sprints of the board and the contents of the
+// sprint at index; a negative index picks the sprint running today, or the
+// latest one.
+func loadPlanning(client *supabase.Client, boardKey string, board *internal.Board, index int) tea.Cmd {
+	return func() tea.Msg {
+		if board == nil {
+			b, err := internal.CurrentBoard(client, boardKey)
+			if err != nil {
+				return messageErr{err}
+			}
+			board = b
+		}
+		sprints, err := internal.ListSprints(client, board.ID)
+		if err != nil {
+			return messageErr{err}
+		}
+		backlog, err := internal.ListBacklog(client, board.ID)
+		if err != nil {
+			return messageErr{err}
+		}
+		counts, err := internal.IssueStatusCounts(context.Background(), client, board.ID)
+		if err != nil {
+			return messageErr{err}
+		}
+		msg := planningMsg{board: board, sprints: sprints, backlog: backlog, statusCounts: counts}
+		if len(sprints) == 0 {
+			return msg
+		}
+
+		if index < 0 || index >= len(sprints) {
+			index = len(sprints) - 1
+			today := time.Now().Format("2006-01-02")
+			for i, s := range sprints {
+				if s.StartDate <= today && today <= s.EndDate {
+					index = i
+				}
+			}
+		}
+		msg.sprintIndex = index
+		sprint := sprints[index]
+
+		msg.sprintIssues, err = internal.ListSprintIssues(client, sprint.ID)
+		if err != nil {
+			return messageErr{err}
+		}
+		ids := make([]int, len(msg.sprintIssues))
+		for i, is := range msg.sprintIssues {
+			ids[i] = is.ID
+		}
+		events, err := internal.ListStatusEvents(client, ids)
+		if err != nil {
+			return messageErr{err}
+		}
+		msg.burndown, err = internal.Burndown(sprint, msg.sprintIssues, events, time.Now())
+		if err != nil {
+			return messageErr{err}
+		}
+		return msg
+	}
+}
+
+func (m *Model) openPlanning() (tea.Model, tea.Cmd) {
+	m.err = nil
+	return m, loadPlanning(m.client, m.boardKey, m.board, -1)
+}
+
+func (m *Model) reloadPlanning() tea.Cmd {
+	return loadPlanning(m.client, m.boardKey, m.board, m.sprintIndex)
+}
+
+func (m Model) currentSprint() *internal.Sprint {
+	if m.sprintIndex < len(m.sprints) {
+		return &m.sprints[m.sprintIndex]
+	}
+	return nil
+}
+
+func (m *Model) updatePlanningKeys(k tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.creatingSprint {
+		return m.updateSprintFormKeys(k)
+	}
+	columns := [2][]internal.Issue{m.backlog, m.sprintIssues}
+	switch k.String() {
+	case "esc", "q":
+		m.view = viewMain
+		return m, nil
+	case "tab", "left", "right", "h", "l":
+		m.planColumn = 1 - m.planColumn
+		return m, nil
+	case "up", "k":
+		if m.planCursor[m.planColumn] > 0 {
+			m.planCursor[m.planColumn]--
+		}
+		return m, nil
+	case "down", "j":
+		if m.planCursor[m.planColumn] < len(columns[m.planColumn])-1 {
+			m.planCursor[m.planColumn]++
+		}
+		return m, nil
+	case "[":
+		if m.sprintIndex > 0 {
+			m.sprintIndex--
+			return m, m.reloadPlanning()
+		}
+		return m, nil
+	case "]":
+		if m.sprintIndex < len(m.sprints)-1 {
+			m.sprintIndex++
+			return m, m.reloadPlanning()
+		}
+		return m, nil
+	case "n":
+		m.creatingSprint = true
+		m.err = nil
+		m.sprintInput.SetValue("")
+		m.sprintInput.Focus()
+		return m, nil
+	case "enter", " ":
+		sprint := m.currentSprint()
+		column := columns[m.planColumn]
+		cursor := m.planCursor[m.planColumn]
+		if sprint == nil || cursor >= len(column) {
+			return m, nil
+		}
+		var target *int
+		if m.planColumn == 0 {
+			target = &sprint.ID
+		}
+		client, id := m.client, column[cursor].ID
+		reload := m.reloadPlanning()
+		return m, func() tea.Msg {
+			if err := internal.MoveIssueToSprint(client, id, target); err != nil {
+				return messageErr{err}
+			}
+			return reload()
+		}
+	}
+	return m, nil
+}
+
+// updateSprintFormKeys reads a new sprint as "name | start | end | goal".
+func (m *Model) updateSprintFormKeys(k tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch k.String() {
+	case "esc":
+		m.creatingSprint = false
+		m.sprintInput.Blur()
+		return m, nil
+	case "enter":
+		parts := strings.Split(m.sprintInput.Value(), "|")
+		if len(parts) < 3 {
+			m.err = fmt.Errorf("expected name | start | end | goal")
+			return m, nil
+		}
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
+		}
+		if m.board == nil {
+			m.err = fmt.Errorf("no board selected")
+			return m, nil
+		}
+		sprint := internal.Sprint{BoardID: m.board.ID, Name: parts[0], StartDate: parts[1], EndDate: parts[2]}
+		if len(parts) > 3 {
+			sprint.Goal = parts[3]
+		}
+		m.creatingSprint = false
+		m.sprintInput.Blur()
+		client, boardKey, board := m.client, m.boardKey, m.board
+		return m, func() tea.Msg {
+			if _, err := internal.CreateSprint(client, sprint); err != nil {
+				return messageErr{err}
+			}
+			return loadPlanning(client, boardKey, board, -1)()
+		}
+	}
+	var cmd tea.Cmd
+	m.sprintInput, cmd = m.sprintInput.Update(k)
+	return m, cmd
+}
+
+func (m Model) viewPlanning() string {
+	title := "Sprint Planning"
+	if m.board != nil {
+		title += " • " + m.board.Name
+	}
+
+	var header strings.Builder
+	if len(m.statusCounts) > 0 {
+		counts := make([]string, len(m.statusCounts))
+		for i, c := range m.statusCounts {
+			counts[i] = fmt.Sprintf("%s %d", c.Status, c.Count)
+		}
+		fmt.Fprintln(&header, helpStyle.Render(strings.Join(counts, " • ")))
+	}
+	if sprint := m.currentSprint(); sprint != nil {
+		fmt.Fprintf(&header, "%s  %s → %s  (%d/%d)\n", sectionTitleStyle.Render(sprint.Name), sprint.StartDate, sprint.EndDate, m.sprintIndex+1, len(m.sprints))
+		if sprint.Goal != "" {
+			fmt.Fprintf(&header, "Goal: %s\n", sprint.Goal)
+		}
+	} else {
+		fmt.Fprintln(&header, "No sprints yet. Press N to create one.")
+	}
+	if m.creatingSprint {
+		fmt.Fprintf(&header, "\n%s\n", m.sprintInput.View())
+	}
+	if m.err != nil {
+		fmt.Fprintf(&header, "\n%s\n", errorStyle.Render(m.err.Error()))
+	}
+
+	column := func(name string, issues []internal.Issue, col int) string {
+		var b strings.Builder
+		fmt.Fprintf(&b, "%s (%d)\n", name, len(issues))
+		for i, is := range issues {
+			cursor := "  "
+			if col == m.planColumn && i == m.planCursor[col] {
+				cursor = "> "
+			}
+			fmt.Fprintf(&b, "%s#%-4d %s\n", cursor, is.ID, truncate(is.Title, 26))
+		}
+		style := lipgloss.NewStyle().Width(36)
+		if col == m.planColumn {
+			style = style.Foreground(accent)
+		}
+		return style.Render(b.String())
+	}
+	columns := lipgloss.JoinHorizontal(lipgloss.Top,
+		column("Backlog", m.backlog, 0),
+		column("Sprint", m.sprintIssues, 1),
+	)
+
+	body := header.String() + "\n" + columns
+	if len(m.burndown) > 0 {
+		body += "\n" + renderBurndown(m.burndown)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		sectionTitleStyle.Render(title),
+		cardStyle.Render(body+"\nEnter to move • Tab to switch • [ ] to change sprint • N for new sprint • Esc to back"),
+	)
+}
+
+// renderBurndown draws remaining issues per day as bars (█) against the
+// ideal line (·).
+func renderBurndown(points []internal.BurndownPoint) string {
+	top := 0.0
+	for _, p := range points {
+		top = math.Max(top, math.Max(p.Ideal, p.Remaining))
+	}
+	if top == 0 {
+		return "Burndown: nothing planned\n"
+	}
+
+	var b strings.Builder
+	fmt.Fprintln(&b, "Burndown")
+	for row := burndownHeight; row >= 1; row-- {
+		threshold := top * float64(row) / burndownHeight
+		label := "   "
+		if row == burndownHeight {
+			label = fmt.Sprintf("%3.0f", top)
+		}
+		fmt.Fprintf(&b, "%s │", label)
+		for _, p := range points {
+			switch {
+			case p.Remaining >= 0 && p.Remaining >= threshold-1e-9:
+				b.WriteString("█ ")
+			case math.Abs(p.Ideal-threshold) < top/(2*burndownHeight):
+				b.WriteString("· ")
+			default:
+				b.WriteString("  ")
+			}
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "  0 └%s\n", strings.Repeat("──", len(points)))
+	first, last := points[0].Day.Format("Jan 2"), points[len(points)-1].Day.Format("Jan 2")
+	pad := len(points)*2 - len(first) - len(last)
+	if pad < 1 {
+		pad = 1
+	}
+	fmt.Fprintf(&b, "     %s%s%s\n", first, strings.Repeat(" ", pad), last)
+	return b.String()
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
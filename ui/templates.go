@@ -0,0 +1,165 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"zel/lo/internal"
+)
+
+type templatesMsg struct{ templates []internal.IssueTemplate }
+
+// openTemplatePicker loads the board's templates and shows the picker; the
+// first entry is always a blank issue.
+func (m *Model) openTemplatePicker() (tea.Model, tea.Cmd) {
+	client, board := m.client, m.board
+	return m, func() tea.Msg {
+		// Templates live outside the issue store.
+		if board == nil || client == nil {
+			return templatesMsg{internal.DefaultTemplates}
+		}
+		templates, err := internal.ListIssueTemplates(client, board.ID)
+		if err != nil {
+			return messageErr{err}
+		}
+		return templatesMsg{templates}
+	}
+}
+
+func (m *Model) updateTemplateKeys(k tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if len(m.templateFill) > 0 {
+		return m.updateTemplateFieldKeys(k)
+	}
+	switch k.String() {
+	case "esc", "q":
+		if m.parentID != nil {
+			m.parentID = nil
+			m.view = viewIssueDetail
+			return m, nil
+		}
+		m.view = viewMain
+		return m, nil
+	case "up", "k":
+		if m.templateCursor > 0 {
+			m.templateCursor--
+		}
+	case "down", "j":
+		if m.templateCursor < len(m.templates) {
+			m.templateCursor++
+		}
+	case "enter":
+		if m.templateCursor == 0 {
+			return m.startIssue(nil)
+		}
+		t := m.templates[m.templateCursor-1]
+		m.template = &t
+		m.templateAnswers = m.templateValues()
+		m.templateFill = nil
+		for _, f := range t.Fields() {
+			if _, ok := m.templateAnswers[f]; !ok {
+				m.templateFill = append(m.templateFill, f)
+			}
+		}
+		if len(m.templateFill) == 0 {
+			return m.startIssue(m.template)
+		}
+		m.templateTotal = len(m.templateFill)
+		m.templateInput.SetValue("")
+		m.templateInput.Focus()
+	}
+	return m, nil
+}
+
+// updateTemplateFieldKeys asks for the template's remaining placeholders one
+// at a time. Fields left empty stay marked as blanks in the description.
+func (m *Model) updateTemplateFieldKeys(k tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch k.String() {
+	case "esc":
+		m.templateFill = nil
+		m.templateInput.Blur()
+		return m, nil
+	case "enter":
+		if v := strings.TrimSpace(m.templateInput.Value()); v != "" {
+			m.templateAnswers[m.templateFill[0]] = v
+		}
+		m.templateFill = m.templateFill[1:]
+		m.templateInput.SetValue("")
+		if len(m.templateFill) == 0 {
+			m.templateInput.Blur()
+			return m.startIssue(m.template)
+		}
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.templateInput, cmd = m.templateInput.Update(k)
+	return m, cmd
+}
+
+// startIssue opens the create form, blank or filled from t, dropping
+// whatever the previous issue left in it.
+func (m *Model) startIssue(t *internal.IssueTemplate) (tea.Model, tea.Cmd) {
+	m.err = nil
+	m.editingID = nil
+	m.titleInput.SetValue("")
+	m.descriptionInput.SetValue("")
+	m.createLabels = nil
+	if t != nil {
+		m.titleInput.SetValue(t.TitlePrefix)
+		m.titleInput.CursorEnd()
+		m.descriptionInput.SetValue(t.Render(m.templateAnswers))
+		m.createLabels = t.Labels
+	}
+	m.view = viewCreateIssue
+	m.titleInput.Focus()
+	m.descriptionInput.Blur()
+	return m, nil
+}
+
+// templateValues are the placeholders filled in automatically.
+func (m Model) templateValues() map[string]string {
+	values := map[string]string{
+		"date":   time.Now().Format("2006-01-02"),
+		"author": shortID(m.userID),
+	}
+	if m.board != nil {
+		values["board"] = m.board.Name
+	}
+	return values
+}
+
+func (m Model) viewTemplatePicker() string {
+	if len(m.templateFill) > 0 {
+		field := m.templateFill[0]
+		n := m.templateTotal - len(m.templateFill) + 1
+		body := fmt.Sprintf("%s (%d of %d)\n\n%s:\n%s", m.template.Name, n, m.templateTotal,
+			strings.ReplaceAll(field, "_", " "), m.templateInput.View())
+		return lipgloss.JoinVertical(lipgloss.Left,
+			sectionTitleStyle.Render("New Issue from Template"),
+			cardStyle.Render(body+"\n\nEnter for next • leave empty to fill in later • Esc to back"),
+		)
+	}
+	var b strings.Builder
+	names := []string{"Blank issue"}
+	for _, t := range m.templates {
+		name := t.Name
+		if len(t.Labels) > 0 {
+			name += helpStyle.Render("  " + strings.Join(t.Labels, ", "))
+		}
+		names = append(names, name)
+	}
+	for i, name := range names {
+		cursor := "  "
+		if i == m.templateCursor {
+			cursor = "> "
+		}
+		fmt.Fprintln(&b, cursor+name)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		sectionTitleStyle.Render("New Issue from Template"),
+		cardStyle.Render(b.String()+"\nEnter to choose • Esc to back"),
+	)
+}
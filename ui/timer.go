@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"zel/lo/internal"
+)
+
+type (
+	timerTickMsg   struct{}
+	timerLoggedMsg struct {
+		issueID int
+		log     *internal.Worklog
+		err     error
+	}
+)
+
+func timerTick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg { return timerTickMsg{} })
+}
+
+// toggleTimer starts timing the given issue, or stops the running timer and
+// logs the elapsed time against the issue it was started on.
+func (m *Model) toggleTimer(issue internal.Issue) (tea.Model, tea.Cmd) {
+	if m.timerIssue == nil {
+		m.timerIssue = &issue
+		m.timerStart = time.Now()
+		m.timerNote = ""
+		return m, timerTick()
+	}
+
+	client, userID := m.client, m.userID
+	id, elapsed := m.timerIssue.ID, time.Since(m.timerStart)
+	m.timerIssue = nil
+	return m, func() tea.Msg {
+		log, err := internal.LogWork(client, id, userID, elapsed, "", time.Now())
+		return timerLoggedMsg{id, log, err}
+	}
+}
+
+func (m *Model) timerLogged(msg timerLoggedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.timerNote = errorStyle.Render(msg.err.Error())
+		return m, nil
+	}
+	m.timerNote = fmt.Sprintf("Logged %s on #%d", internal.FormatDuration(msg.log.Duration()), msg.issueID)
+	if m.view == viewIssueDetail && m.detail.ID == msg.issueID {
+		return m.openIssueDetail(msg.issueID)
+	}
+	return m, nil
+}
+
+func (m Model) viewTimer() string {
+	if m.timerIssue == nil {
+		if m.timerNote == "" {
+			return ""
+		}
+		return "\n" + m.timerNote
+	}
+	elapsed := time.Since(m.timerStart).Truncate(time.Second)
+	h, min, sec := int(elapsed.Hours()), int(elapsed.Minutes())%60, int(elapsed.Seconds())%60
+	return "\n" + sectionTitleStyle.Render(fmt.Sprintf("⏱ #%d %s %02d:%02d:%02d", m.timerIssue.ID, truncate(m.timerIssue.Title, 30), h, min, sec)) +
+		helpStyle.Render("  T to stop and log")
+}
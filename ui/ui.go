@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
@@ -25,6 +26,11 @@ const (
 	viewCreateIssue
 	viewListIssues
 	viewMessage
+	viewSearch
+	viewIssueDetail
+	viewPlanning
+	viewTemplatePicker
+	viewSettings
 )
 
 // Styled components
@@ -52,14 +58,24 @@ func (i menuItem) FilterValue() string { return i.title }
 
 type Model struct {
 	client   *supabase.Client
+	store    internal.IssueStore
 	userID   string
 	view     int
+	boardKey string
+	board    *internal.Board
 
 	// Auth
-	modeSignup bool
+	authMode      int
+	codeSent      bool
 	emailInput    textinput.Model
 	passwordInput textinput.Model
 	nameInput     textinput.Model
+	phoneInput    textinput.Model
+	codeInput     textinput.Model
+	providerInput textinput.Model
+	oauthCancel   context.CancelFunc
+	mfaFactor     *authtypes.Factor
+	mfaUserID     string
 	spinner       spinner.Model
 
 	// Menu
@@ -68,16 +84,91 @@ type Model struct {
 	// Create issue
 	titleInput       textinput.Model
 	descriptionInput textarea.Model
+	parentID         *int
+	editingID        *int
+	createLabels     []string
+	templates        []internal.IssueTemplate
+	templateCursor   int
+	template         *internal.IssueTemplate
+	templateFill     []string
+	templateTotal    int
+	templateAnswers  map[string]string
+	templateInput    textinput.Model
 
 	// List issues
-	issues []internal.Issue
+	issues      []internal.Issue
+	filterInput textinput.Model
+	filtering   bool
+	sort        string
+	columns     []string
+	cursor      int
+	selected    map[int]bool
+	bulkInput   textinput.Model
+	bulking     bool
+	bulkReport  string
+	depth       map[int]int
+
+	// Issue detail
+	detail        internal.Issue
+	children      []internal.Issue
+	progress      progress.Model
+	links         []internal.IssueLink
+	linked        map[int]internal.Issue
+	comments      []internal.Comment
+	detailInput   textinput.Model
+	detailPrompt  int
+
+	// Sprint planning
+	sprints        []internal.Sprint
+	sprintIndex    int
+	backlog        []internal.Issue
+	sprintIssues   []internal.Issue
+	burndown       []internal.BurndownPoint
+	statusCounts   []internal.StatusCount
+	planColumn     int
+	planCursor     [2]int
+	sprintInput    textinput.Model
+	creatingSprint bool
+
+	// Saved views
+	savedViews    []internal.SavedView
+	viewNameInput textinput.Model
+	savingView    bool
+	saveShared    bool
+
+	// Search
+	searchInput   textinput.Model
+	searchSeq     int
+	searchResults []internal.SearchResult
+	searching     bool
+
+	// Time tracking
+	worklogs   []internal.Worklog
+	timerIssue *internal.Issue
+	timerStart time.Time
+	timerNote  string
+
+	// Settings
+	account         *authtypes.User
+	profile         *internal.Profile
+	settingsInputs  []textinput.Model
+	settingsFocus   int
+	settingsTried   map[int]bool
+	settingsNotice  string
+	deletingAccount bool
+	deleteInput     textinput.Model
+	enrolment       *authtypes.EnrollFactorResponse
+	enrolmentQR     string
 
 	// Message
 	message string
 	err     error
 }
 
-func New(client *supabase.Client, userID string) Model {
+// New builds the TUI model. Issues are read and written through store;
+// everything else still talks to client, which may be nil when the store is
+// not backed by Supabase.
+func New(client *supabase.Client, store internal.IssueStore, userID, boardKey string) Model {
 	email := textinput.New()
 	email.Placeholder = "email@example.com"
 	email.Width = 40
@@ -89,15 +180,21 @@ func New(client *supabase.Client, userID string) Model {
 	name := textinput.New()
 	name.Placeholder = "name (on signup)"
 	name.Width = 40
+	phone := textinput.New()
+	phone.Placeholder = "+14155550100"
+	phone.Width = 40
+	code := textinput.New()
+	code.Placeholder = "6-digit code"
+	code.CharLimit = 10
+	code.Width = 40
+	provider := textinput.New()
+	provider.Placeholder = "github"
+	provider.Width = 40
 
 	spin := spinner.New()
 	spin.Spinner = spinner.Dot
 
-	items := []list.Item{
-		menuItem{"Create Issue", "Open a form to create a new issue"},
-		menuItem{"List My Issues", "View issues you created"},
-	}
-	menu := list.New(items, list.NewDefaultDelegate(), 0, 0)
+	menu := list.New(Model{}.menuItems(), list.NewDefaultDelegate(), 0, 0)
 	menu.Title = "Menu"
 	menu.SetShowHelp(false)
 	menu.SetShowStatusBar(false)
@@ -113,6 +210,41 @@ func New(client *supabase.Client, userID string) Model {
 	desc.SetHeight(8)
 	desc.SetWidth(76)
 
+	filter := textinput.New()
+	filter.Placeholder = "status:open label:bug assignee:me"
+	filter.Prompt = "Filter: "
+	filter.Width = 60
+
+	bulk := textinput.New()
+	bulk.Placeholder = "status closed [--force] | +label bug | -label bug | assign me | delete"
+	bulk.Prompt = "Bulk: "
+	bulk.Width = 60
+
+	detailInput := textinput.New()
+	detailInput.Width = 60
+
+	sprint := textinput.New()
+	sprint.Placeholder = "Sprint 12 | 2026-11-02 | 2026-11-13 | Ship search"
+	sprint.Prompt = "New sprint: "
+	sprint.Width = 60
+
+	viewName := textinput.New()
+	viewName.Placeholder = "view name"
+	viewName.Prompt = "Save view as: "
+	viewName.Width = 40
+
+	search := textinput.New()
+	search.Placeholder = "Search issues and comments"
+	search.Prompt = "/ "
+	search.Width = 60
+
+	templateField := textinput.New()
+	templateField.Width = 60
+
+	deleteAccount := textinput.New()
+	deleteAccount.Prompt = "Email: "
+	deleteAccount.Width = 40
+
 	initialView := viewAuth
 	if userID != "" {
 		initialView = viewMain
@@ -120,21 +252,40 @@ func New(client *supabase.Client, userID string) Model {
 
 	return Model{
 		client:           client,
+		store:            store,
 		userID:           userID,
 		view:             initialView,
-		modeSignup:       false,
+		boardKey:         boardKey,
+		authMode:         authSignIn,
 		emailInput:       email,
 		passwordInput:    password,
 		nameInput:        name,
+		phoneInput:       phone,
+		codeInput:        code,
+		providerInput:    provider,
 		spinner:          spin,
 		menu:             menu,
 		titleInput:       title,
 		descriptionInput: desc,
+		filterInput:      filter,
+		columns:          columnPresets[0],
+		selected:         map[int]bool{},
+		bulkInput:        bulk,
+		depth:            map[int]int{},
+		progress:         progress.New(progress.WithDefaultGradient(), progress.WithWidth(40)),
+		detailInput:      detailInput,
+		sprintInput:      sprint,
+		viewNameInput:    viewName,
+		searchInput:      search,
+		settingsInputs:   newSettingsInputs(),
+		settingsTried:    map[int]bool{},
+		deleteInput:      deleteAccount,
+		templateInput:    templateField,
 	}
 }
 
 // tea.Model
-func (m Model) Init() tea.Cmd { return nil }
+func (m Model) Init() tea.Cmd { return tea.Batch(m.loadSavedViews(), m.loadBoard()) }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
@@ -148,6 +299,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateCreateKeys(msg)
 		case viewListIssues:
 			return m.updateListKeys(msg)
+		case viewSearch:
+			return m.updateSearchKeys(msg)
+		case viewIssueDetail:
+			return m.updateDetailKeys(msg)
+		case viewPlanning:
+			return m.updatePlanningKeys(msg)
+		case viewTemplatePicker:
+			return m.updateTemplateKeys(msg)
+		case viewSettings:
+			return m.updateSettingsKeys(msg)
 		case viewMessage:
 			if key := msg.String(); key == "q" || key == "esc" || key == "enter" {
 				m.view = viewMain
@@ -173,10 +334,105 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.titleInput.Focus()
 			m.descriptionInput.Blur()
 		}
+		if m.view == viewMain {
+			return m, tea.Batch(m.loadSavedViews(), m.loadBoard())
+		}
 		return m, nil
+	case savedViewsMsg:
+		m.savedViews = msg.views
+		return m, m.menu.SetItems(m.menuItems())
 	case issuesMsg:
-		m.issues = msg.list
+		m.issues = make([]internal.Issue, 0, len(msg.list))
+		m.depth = map[int]int{}
+		for _, row := range internal.IssueTree(msg.list) {
+			m.issues = append(m.issues, row.Issue)
+			m.depth[row.Issue.ID] = row.Depth
+		}
 		m.view = viewListIssues
+		if m.cursor >= len(m.issues) {
+			m.cursor = 0
+		}
+		return m, nil
+	case issueDetailMsg:
+		m.detail = msg.issue
+		m.children = msg.children
+		m.links = msg.links
+		m.linked = msg.linked
+		m.worklogs = msg.worklogs
+		m.comments = msg.comments
+		m.err = nil
+		m.view = viewIssueDetail
+		return m, nil
+	case timerTickMsg:
+		if m.timerIssue != nil {
+			return m, timerTick()
+		}
+		return m, nil
+	case timerLoggedMsg:
+		return m.timerLogged(msg)
+	case editorFinishedMsg:
+		return m.editorFinished(msg)
+	case templatesMsg:
+		m.templates = msg.templates
+		m.templateCursor = 0
+		m.templateFill = nil
+		m.view = viewTemplatePicker
+		return m, nil
+	case boardMsg:
+		m.board = msg.board
+		// Views shared on the board can only be listed now.
+		return m, m.loadSavedViews()
+	case planningMsg:
+		m.board = msg.board
+		m.sprints = msg.sprints
+		m.sprintIndex = msg.sprintIndex
+		m.backlog = msg.backlog
+		m.sprintIssues = msg.sprintIssues
+		m.burndown = msg.burndown
+		m.statusCounts = msg.statusCounts
+		for col, n := range []int{len(m.backlog), len(m.sprintIssues)} {
+			if m.planCursor[col] >= n {
+				m.planCursor[col] = max(n-1, 0)
+			}
+		}
+		m.view = viewPlanning
+		return m, nil
+	case detailErrMsg:
+		m.err = msg.error
+		return m, nil
+	case bulkResultMsg:
+		return m.applyBulkResult(msg)
+	case settingsMsg:
+		return m.settingsLoaded(msg)
+	case settingsErrMsg:
+		m.err = msg.error
+		m.settingsNotice = ""
+		return m, nil
+	case accountDeletedMsg:
+		return m.accountDeleted()
+	case enrolmentMsg:
+		return m.enrolmentStarted(msg)
+	case codeSentMsg:
+		return m.authCodeSent(msg)
+	case authErrMsg:
+		m.err = msg.error
+		m.message = ""
+		m.oauthCancel = nil
+		return m, nil
+	case signedInMsg:
+		return m.signedIn(msg)
+	case oauthStartedMsg:
+		return m.oauthStarted(msg)
+	case mfaRequiredMsg:
+		return m.mfaRequired(msg)
+	case searchTickMsg:
+		return m.runSearch(msg.seq)
+	case searchResultsMsg:
+		if msg.seq == m.searchSeq {
+			m.searching = false
+			m.searchResults = msg.results
+			m.err = msg.err
+		}
 		return m, nil
 	}
 
@@ -184,19 +440,28 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch m.view {
 	case viewAuth:
 		var cmds []tea.Cmd
-		var cmd tea.Cmd
-		m.emailInput, cmd = m.emailInput.Update(msg)
-		cmds = append(cmds, cmd)
-		m.passwordInput, cmd = m.passwordInput.Update(msg)
-		cmds = append(cmds, cmd)
-		m.nameInput, cmd = m.nameInput.Update(msg)
-		cmds = append(cmds, cmd)
+		for _, in := range m.authFields() {
+			var cmd tea.Cmd
+			*in, cmd = in.Update(msg)
+			cmds = append(cmds, cmd)
+		}
 		return m, tea.Batch(cmds...)
 	case viewCreateIssue:
 		var cmd tea.Cmd
 		m.titleInput, cmd = m.titleInput.Update(msg)
 		m.descriptionInput, _ = m.descriptionInput.Update(msg)
 		return m, cmd
+	case viewTemplatePicker:
+		var cmd tea.Cmd
+		m.templateInput, cmd = m.templateInput.Update(msg)
+		return m, cmd
+	case viewSettings:
+		if m.settingsFocus >= settingCount {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.settingsInputs[m.settingsFocus], cmd = m.settingsInputs[m.settingsFocus].Update(msg)
+		return m, cmd
 	}
 
 	return m, nil
@@ -212,97 +477,22 @@ func (m Model) View() string {
 		return m.viewCreateIssue()
 	case viewListIssues:
 		return m.viewListIssues()
+	case viewSearch:
+		return m.viewSearch()
+	case viewIssueDetail:
+		return m.viewIssueDetail()
+	case viewPlanning:
+		return m.viewPlanning()
+	case viewTemplatePicker:
+		return m.viewTemplatePicker()
+	case viewSettings:
+		return m.viewSettings()
 	case viewMessage:
 		return m.viewMessage()
 	}
 	return ""
 }
 
-// Auth
-func (m *Model) updateAuthKeys(k tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch k.String() {
-	case "tab":
-		if m.modeSignup {
-			if m.emailInput.Focused() {
-				m.emailInput.Blur(); m.nameInput.Focus()
-			} else if m.nameInput.Focused() {
-				m.nameInput.Blur(); m.passwordInput.Focus()
-			} else {
-				m.passwordInput.Blur(); m.emailInput.Focus()
-			}
-		} else {
-			if m.emailInput.Focused() {
-				m.emailInput.Blur(); m.passwordInput.Focus()
-			} else {
-				m.passwordInput.Blur(); m.emailInput.Focus()
-			}
-		}
-	case "ctrl+s":
-		m.modeSignup = !m.modeSignup
-		return m, nil
-	case "enter":
-		email := strings.TrimSpace(m.emailInput.Value())
-		pass := m.passwordInput.Value()
-		name := strings.TrimSpace(m.nameInput.Value())
-		if email == "" || pass == "" || (m.modeSignup && name == "") {
-			m.err = fmt.Errorf("fill required fields")
-			return m, nil
-		}
-		if m.modeSignup {
-			return m.submitSignup(email, pass, name)
-		}
-		return m.submitSignin(email, pass)
-	case "esc", "q":
-		return m, tea.Quit
-	default:
-		var cmds []tea.Cmd
-		var cmd tea.Cmd
-		m.emailInput, cmd = m.emailInput.Update(k)
-		cmds = append(cmds, cmd)
-		m.passwordInput, cmd = m.passwordInput.Update(k)
-		cmds = append(cmds, cmd)
-		if m.modeSignup {
-			m.nameInput, cmd = m.nameInput.Update(k)
-			cmds = append(cmds, cmd)
-		}
-		return m, tea.Batch(cmds...)
-	}
-	return m, nil
-}
-
-func (m *Model) submitSignin(email, pass string) (tea.Model, tea.Cmd) {
-	m.err = nil
-	return m, tea.Batch(func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 12*time.Second)
-		defer cancel()
-		_ = ctx
-		session, err := m.client.SignInWithEmailPassword(email, pass)
-		if err != nil {
-			return messageErr{err}
-		}
-		m.userID = session.User.ID.String()
-		return messageInfo{"Signed in"}
-	}, func() tea.Msg { return changeView{viewMain} })
-}
-
-func (m *Model) submitSignup(email, pass, name string) (tea.Model, tea.Cmd) {
-	m.err = nil
-	return m, tea.Batch(func() tea.Msg {
-		_, err := m.client.Auth.Signup(authtypes.SignupRequest{Email: email, Password: pass})
-		if err != nil {
-			return messageErr{err}
-		}
-		session, err := m.client.SignInWithEmailPassword(email, pass)
-		if err != nil {
-			return messageErr{err}
-		}
-		m.userID = session.User.ID.String()
-		// Optional: create user profile record via main.CreateUser equivalent if needed
-		_ = name
-		return messageInfo{"Account created"}
-	}, func() tea.Msg { return changeView{viewMain} })
-}
-
 // Menu
 func (m *Model) updateMenuKeys(k tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch k.String() {
@@ -310,14 +500,27 @@ func (m *Model) updateMenuKeys(k tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if it, ok := m.menu.SelectedItem().(menuItem); ok {
 			switch it.title {
 			case "Create Issue":
-				m.view = viewCreateIssue
-				m.titleInput.Focus()
-				m.descriptionInput.Blur()
-				return m, nil
+				return m.openTemplatePicker()
+			case "Sprint Planning":
+				return m.openPlanning()
+			case "Settings":
+				return m.openSettings()
 			case "List My Issues":
+				m.filterInput.SetValue("")
+				m.sort = ""
+				m.columns = columnPresets[0]
 				return m.fetchIssues()
 			}
 		}
+		if it, ok := m.menu.SelectedItem().(savedViewItem); ok {
+			return m.openSavedView(it.view)
+		}
+	case "d":
+		if it, ok := m.menu.SelectedItem().(savedViewItem); ok {
+			return m.deleteSavedView(it.view)
+		}
+	case "/":
+		return m.openSearch()
 	case "esc", "q":
 		return m, tea.Quit
 	}
@@ -330,8 +533,18 @@ func (m *Model) updateMenuKeys(k tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m *Model) updateCreateKeys(k tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch k.String() {
 	case "esc":
+		m.err = nil
+		if m.editingID != nil {
+			m.editingID = nil
+			m.view = viewIssueDetail
+			return m, nil
+		}
 		m.view = viewMain
+		m.parentID = nil
+		m.createLabels = nil
 		return m, nil
+	case "ctrl+e":
+		return m.openEditor()
 	case "tab":
 		if m.titleInput.Focused() {
 			m.titleInput.Blur(); m.descriptionInput.Focus()
@@ -360,28 +573,137 @@ func (m *Model) updateCreateKeys(k tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m *Model) submitIssue(title, desc string) (tea.Model, tea.Cmd) {
+	if m.editingID != nil {
+		store, client, id := m.store, m.client, *m.editingID
+		m.editingID = nil
+		return m, func() tea.Msg {
+			if _, err := store.UpdateIssue(context.Background(), id, title, desc); err != nil {
+				return messageErr{err}
+			}
+			return loadIssueDetail(store, client, id)
+		}
+	}
+	parentID, labels := m.parentID, m.createLabels
+	m.parentID, m.createLabels = nil, nil
+	store, userID, board := m.store, m.userID, m.board
+	req := internal.CreateIssueRequest{Title: title, Description: desc, ParentID: parentID, Labels: labels}
+	if board != nil {
+		req.BoardID = &board.ID
+	}
 	return m, tea.Batch(func() tea.Msg {
-		_, err := internal.CreateIssue(m.client, internal.CreateIssueRequest{Title: title, Description: desc}, m.userID)
+		issue, err := store.CreateIssue(context.Background(), req, userID)
 		if err != nil {
 			return messageErr{err}
 		}
-		return messageInfo{"Issue created"}
+		if board == nil {
+			return messageInfo{"Issue created"}
+		}
+		return messageInfo{"Created " + issue.Key(board)}
 	}, func() tea.Msg { return changeView{viewMain} })
 }
 
 // List Issues
 func (m *Model) updateListKeys(k tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.filtering {
+		return m.updateFilterKeys(k)
+	}
+	if m.savingView {
+		return m.updateSaveViewKeys(k)
+	}
+	if m.bulking {
+		return m.updateBulkKeys(k)
+	}
+	switch k.String() {
+	case "t", "s", "S":
+		// Worklogs and saved views live in Supabase; without it these keys
+		// are not bound.
+		if m.client == nil {
+			return m, nil
+		}
+	}
 	switch k.String() {
 	case "esc", "q":
 		m.view = viewMain
+		m.selected = map[int]bool{}
+		m.bulkReport = ""
+		return m, nil
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+		return m, nil
+	case "down", "j":
+		if m.cursor < len(m.issues)-1 {
+			m.cursor++
+		}
+		return m, nil
+	case " ":
+		m.toggleSelected()
+		return m, nil
+	case "*":
+		m.toggleSelectAll()
+		return m, nil
+	case "b":
+		return m.startBulk()
+	case "t":
+		if m.timerIssue != nil {
+			return m.toggleTimer(*m.timerIssue)
+		}
+		if m.cursor < len(m.issues) {
+			return m.toggleTimer(m.issues[m.cursor])
+		}
+		return m, nil
+	case "enter":
+		if m.cursor < len(m.issues) {
+			return m.openIssueDetail(m.issues[m.cursor].ID)
+		}
+		return m, nil
+	case "/":
+		return m.openSearch()
+	case "f":
+		m.filtering = true
+		m.err = nil
+		m.filterInput.Focus()
 		return m, nil
+	case "o":
+		m.sort = nextOption(sortOrders, m.sort)
+		return m.fetchIssues()
+	case "c":
+		m.columns = nextPreset(m.columns)
+		return m, nil
+	case "s", "S":
+		return m.startSaveView(k.String() == "S")
 	}
 	return m, nil
 }
 
+func (m *Model) updateFilterKeys(k tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch k.String() {
+	case "esc":
+		m.filtering = false
+		m.filterInput.Blur()
+		return m, nil
+	case "enter":
+		// Parse up front so query mistakes stay inline in the filter bar.
+		if _, err := internal.ParseQuery(m.filterInput.Value()); err != nil {
+			m.err = err
+			return m, nil
+		}
+		m.err = nil
+		m.filtering = false
+		m.filterInput.Blur()
+		return m.fetchIssues()
+	}
+	var cmd tea.Cmd
+	m.filterInput, cmd = m.filterInput.Update(k)
+	return m, cmd
+}
+
 func (m *Model) fetchIssues() (tea.Model, tea.Cmd) {
+	query := strings.TrimSpace(m.filterInput.Value())
+	store, sort, userID := m.store, m.sort, m.userID
 	return m, func() tea.Msg {
-		issues, err := internal.ListIssues(m.client)
+		issues, err := store.QueryIssues(context.Background(), query, sort, userID)
 		if err != nil {
 			return messageErr{err}
 		}
@@ -397,53 +719,95 @@ type (
 	issuesMsg struct{ list []internal.Issue }
 )
 
-func (m Model) viewAuth() string {
-	mode := "Sign In"
-	if m.modeSignup {
-		mode = "Sign Up"
-	}
-	b := &strings.Builder{}
-	fmt.Fprintln(b, appTitleStyle.Render("Zello"))
-	fmt.Fprintln(b, cardStyle.Render(sectionTitleStyle.Render(mode)+
-		"\n\nEmail:   "+m.emailInput.View()+
-		"\nPassword: "+m.passwordInput.View()+
-		func() string { if m.modeSignup { return "\nName:    "+m.nameInput.View() } ; return "" }()+
-		"\n\nEnter to submit • Tab to switch • Ctrl+S toggle sign-in/sign-up • Q to quit"))
-	if m.err != nil {
-		fmt.Fprintln(b, errorStyle.Render(m.err.Error()))
-	}
-	return b.String()
-}
-
 func (m Model) viewMenu() string {
 	return lipgloss.JoinVertical(lipgloss.Left,
 		appTitleStyle.Render("Zello"),
-		cardStyle.Render(m.menu.View()),
-		helpStyle.Render("Enter to select • Q to quit"),
+		cardStyle.Render(m.menu.View()+m.viewTimer()),
+		helpStyle.Render("Enter to select • D to delete a saved view • / to search • Q to quit"),
 	)
 }
 
 func (m Model) viewCreateIssue() string {
+	heading := "Create Issue"
+	if m.parentID != nil {
+		heading = fmt.Sprintf("Create Sub-issue of #%d", *m.parentID)
+	}
+	if m.editingID != nil {
+		heading = fmt.Sprintf("Edit Issue #%d", *m.editingID)
+	}
+	errText := ""
+	if m.err != nil {
+		errText = "\n\n" + errorStyle.Render(m.err.Error())
+	}
+	labels := ""
+	if len(m.createLabels) > 0 {
+		labels = "\n\nLabels: " + strings.Join(m.createLabels, ", ")
+	}
 	return lipgloss.JoinVertical(lipgloss.Left,
-		sectionTitleStyle.Render("Create Issue"),
-		cardStyle.Render("Title:\n"+m.titleInput.View()+"\n\nDescription:\n"+m.descriptionInput.View()+"\n\nEnter to submit • Tab to switch • Esc to back"),
+		sectionTitleStyle.Render(heading),
+		cardStyle.Render("Title:\n"+m.titleInput.View()+"\n\nDescription:\n"+m.descriptionInput.View()+labels+errText+"\n\nEnter to submit • Tab to switch • Ctrl+E to open $EDITOR • Esc to back"),
 	)
 }
 
+func (m Model) viewFilterBar() string {
+	if !m.filtering && !m.savingView && !m.bulking && m.filterInput.Value() == "" && m.sort == "" {
+		return ""
+	}
+	bar := m.filterInput.View()
+	if m.sort != "" {
+		bar += "\n" + helpStyle.Render("Sort: "+m.sort)
+	}
+	if m.savingView {
+		bar += "\n" + m.viewNameInput.View()
+	}
+	if m.bulking {
+		bar += "\n" + m.bulkInput.View()
+	}
+	if m.err != nil {
+		bar += "\n" + errorStyle.Render(m.err.Error())
+	}
+	return bar + "\n\n"
+}
+
 func (m Model) viewListIssues() string {
 	if len(m.issues) == 0 {
 		return lipgloss.JoinVertical(lipgloss.Left,
 			sectionTitleStyle.Render("My Issues"),
-			cardStyle.Render("No issues found."+"\n\nEsc to back"),
+			cardStyle.Render(m.viewFilterBar()+"No issues found."+"\n\nF to filter • Esc to back"),
 		)
 	}
 	var b strings.Builder
 	fmt.Fprintln(&b, sectionTitleStyle.Render("My Issues"))
-	for _, is := range m.issues {
-		line := fmt.Sprintf("#%-4d %-20s %-s", is.ID, is.Title, is.Description)
-		fmt.Fprintln(&b, line)
+	b.WriteString(m.viewFilterBar())
+	for i, is := range m.issues {
+		cursor, mark := "  ", "  "
+		if i == m.cursor {
+			cursor = "> "
+		}
+		if m.selected[is.ID] {
+			mark = "● "
+		}
+		var children []internal.Issue
+		for _, c := range m.issues {
+			if c.ParentID != nil && *c.ParentID == is.ID {
+				children = append(children, c)
+			}
+		}
+		fmt.Fprintln(&b, cursor+mark+treePrefix(m.depth[is.ID])+renderIssueRow(m.columns, is)+childSummary(children))
+	}
+	if len(m.selected) > 0 {
+		fmt.Fprintln(&b, helpStyle.Render(fmt.Sprintf("\n%d selected", len(m.selected))))
+	}
+	if m.bulkReport != "" {
+		fmt.Fprintln(&b, "\n"+m.bulkReport)
+	}
+	b.WriteString(m.viewTimer())
+	help := "Enter to open • T to start/stop timer • Space to select • * to select all • B for bulk actions • F to filter • O to sort • C for columns • S to save view (shift to share) • / to search • Esc to back"
+	if m.client == nil {
+		help = strings.Replace(help, " • T to start/stop timer", "", 1)
+		help = strings.Replace(help, " • S to save view (shift to share)", "", 1)
 	}
-	return lipgloss.JoinVertical(lipgloss.Left, cardStyle.Render(b.String()+"\n\nEsc to back"))
+	return lipgloss.JoinVertical(lipgloss.Left, cardStyle.Render(b.String()+"\n\n"+help))
 }
 
 func (m Model) viewMessage() string {
@@ -475,8 +839,8 @@ func (m Model) UpdateMsg(msg tea.Msg) (Model, tea.Cmd) {
 }
 
 // Program entry
-func Start(client *supabase.Client, userID string) error {
-	p := tea.NewProgram(New(client, userID))
+func Start(client *supabase.Client, store internal.IssueStore, userID, boardKey string) error {
+	p := tea.NewProgram(New(client, store, userID, boardKey))
 	model, err := p.StartReturningModel()
 	if err != nil { return err }
 	_ = model
@@ -0,0 +1,294 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	authtypes "github.com/supabase-community/auth-go/types"
+
+	"zel/lo/internal"
+	"zel/lo/supabase/supabasetest"
+)
+
+// keyMsg builds the key message bubbletea delivers for key.
+func keyMsg(key string) tea.KeyMsg {
+	switch key {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "tab":
+		return tea.KeyMsg{Type: tea.KeyTab}
+	case " ":
+		return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
+
+// collect runs cmd and returns the messages it produces, expanding batches.
+// Commands that wait, like cursor blinks and timer ticks, are dropped.
+func collect(t *testing.T, cmd tea.Cmd) []tea.Msg {
+	t.Helper()
+	if cmd == nil {
+		return nil
+	}
+	done := make(chan tea.Msg, 1)
+	panicked := make(chan interface{}, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				panicked <- r
+			}
+		}()
+		done <- cmd()
+	}()
+	select {
+	case msg := <-done:
+		switch msg := msg.(type) {
+		case tea.BatchMsg:
+			var out []tea.Msg
+			for _, c := range msg {
+				out = append(out, collect(t, c)...)
+			}
+			return out
+		case nil:
+			return nil
+		}
+		return []tea.Msg{msg}
+	case r := <-panicked:
+		t.Fatalf("command panicked: %v", r)
+	case <-time.After(50 * time.Millisecond):
+	}
+	return nil
+}
+
+// send delivers msg and everything its commands produce, a few rounds deep.
+func send(t *testing.T, m tea.Model, msg tea.Msg) tea.Model {
+	t.Helper()
+	queue := []tea.Msg{msg}
+	for round := 0; round < 4 && len(queue) > 0; round++ {
+		var next []tea.Msg
+		for _, msg := range queue {
+			if _, ok := msg.(tea.QuitMsg); ok {
+				continue
+			}
+			var cmd tea.Cmd
+			m, cmd = m.Update(msg)
+			next = append(next, collect(t, cmd)...)
+		}
+		queue = next
+	}
+	return m
+}
+
+func press(t *testing.T, m tea.Model, keys ...string) tea.Model {
+	t.Helper()
+	for _, k := range keys {
+		m = send(t, m, keyMsg(k))
+	}
+	return m
+}
+
+// sweepKeys presses every key, and every key followed by a few more, on a
+// fresh copy of the model set up by start, failing on panics.
+func sweepKeys(t *testing.T, start func() tea.Model) {
+	var keys []string
+	for _, r := range "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789/*?+- " {
+		keys = append(keys, string(r))
+	}
+	keys = append(keys, "enter", "esc", "tab")
+	for _, k := range keys {
+		for _, then := range [][]string{nil, {"enter"}, {"1", "enter"}, {"esc"}} {
+			seq := append([]string{k}, then...)
+			t.Run(fmt.Sprintf("%q", seq), func(t *testing.T) {
+				defer func() {
+					if r := recover(); r != nil {
+						t.Fatalf("panic: %v", r)
+					}
+				}()
+				m := press(t, start(), seq...)
+				m.View()
+			})
+		}
+	}
+}
+
+func localModel() Model {
+	parent := 1
+	store := internal.NewMemoryStore(
+		internal.Issue{ID: 1, Title: "Parent", Status: "open", UserID: internal.LocalUserID},
+		internal.Issue{ID: 2, Title: "Child", Status: "open", UserID: internal.LocalUserID, ParentID: &parent},
+	)
+	return New(nil, store, internal.LocalUserID, "")
+}
+
+func TestWithoutSupabaseDetail(t *testing.T) {
+	m := localModel()
+	_, cmd := m.openIssueDetail(1)
+	var opened tea.Model = m
+	for _, msg := range collect(t, cmd) {
+		opened = send(t, opened, msg)
+	}
+	view := opened.View()
+	if opened.(Model).view != viewIssueDetail || !strings.Contains(view, "Child") {
+		t.Fatalf("detail view without Supabase:\n%s", view)
+	}
+	sweepKeys(t, func() tea.Model { return opened })
+}
+
+func TestWithoutSupabaseList(t *testing.T) {
+	m := localModel()
+	_, cmd := m.fetchIssues()
+	var listed tea.Model = m
+	for _, msg := range collect(t, cmd) {
+		listed = send(t, listed, msg)
+	}
+	if listed.(Model).view != viewListIssues {
+		t.Fatalf("list view without Supabase:\n%s", listed.View())
+	}
+	sweepKeys(t, func() tea.Model { return listed })
+}
+
+func TestWithoutSupabaseMenu(t *testing.T) {
+	m := localModel()
+	var started tea.Model = m
+	for _, msg := range collect(t, m.Init()) {
+		started = send(t, started, msg)
+	}
+	sweepKeys(t, func() tea.Model { return started })
+}
+
+func TestLocalCommentsAndLabels(t *testing.T) {
+	store, err := internal.OpenSQLiteStore(context.Background(), filepath.Join(t.TempDir(), "local.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	var m tea.Model = New(nil, store, internal.LocalUserID, "")
+	for _, msg := range collect(t, m.Init()) {
+		m = send(t, m, msg)
+	}
+	board := m.(Model).board
+	if board == nil || board.Key != "LOCAL" {
+		t.Fatalf("board = %+v, want the LOCAL board", board)
+	}
+	is, err := store.CreateIssue(context.Background(), internal.CreateIssueRequest{Title: "Local", BoardID: &board.ID}, internal.LocalUserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opened := m.(Model)
+	_, cmd := opened.openIssueDetail(is.ID)
+	for _, msg := range collect(t, cmd) {
+		m = send(t, m, msg)
+	}
+
+	m = press(t, m, "c", "Looks good", "enter")
+	m = press(t, m, "a", ", bug, ui", "enter")
+	if err := m.(Model).err; err != nil {
+		t.Fatal(err)
+	}
+	view := m.View()
+	for _, want := range []string{"LOCAL-1", "Looks good", "Labels: bug, ui"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("detail view is missing %q:\n%s", want, view)
+		}
+	}
+	comments, err := store.ListComments(context.Background(), is.ID)
+	if err != nil || len(comments) != 1 || comments[0].UserID != internal.LocalUserID {
+		t.Errorf("stored comments = %+v, %v", comments, err)
+	}
+}
+
+func TestLocalCloseIssue(t *testing.T) {
+	ctx := context.Background()
+	store, err := internal.OpenSQLiteStore(ctx, filepath.Join(t.TempDir(), "local.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	blocked, err := store.CreateIssue(ctx, internal.CreateIssueRequest{Title: "Blocked"}, internal.LocalUserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocker, err := store.CreateIssue(ctx, internal.CreateIssueRequest{Title: "Blocker"}, internal.LocalUserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.LinkIssues(ctx, blocker.ID, blocked.ID, internal.LinkBlocks); err != nil {
+		t.Fatal(err)
+	}
+
+	m := New(nil, store, internal.LocalUserID, "")
+	_, cmd := m.openIssueDetail(blocked.ID)
+	var opened tea.Model = m
+	for _, msg := range collect(t, cmd) {
+		opened = send(t, opened, msg)
+	}
+	view := opened.View()
+	if !strings.Contains(view, "blocked by") || strings.Contains(view, "timer") {
+		t.Errorf("local detail view:\n%s", view)
+	}
+
+	opened = press(t, opened, "x")
+	var be *internal.BlockedError
+	if err := opened.(Model).err; !errors.As(err, &be) {
+		t.Fatalf("closing a blocked issue: err = %v", err)
+	}
+	opened = press(t, opened, "X")
+	if err := opened.(Model).err; err != nil {
+		t.Fatal(err)
+	}
+	if is, err := store.GetIssue(ctx, blocked.ID); err != nil || is.Status != "closed" {
+		t.Errorf("stored issue = %+v, %v", is, err)
+	}
+	if got := opened.(Model).detail.Status; got != "closed" {
+		t.Errorf("detail status = %q, want closed", got)
+	}
+}
+
+func TestSettingsEnrolmentShowsURI(t *testing.T) {
+	m := localModel()
+	m.view = viewSettings
+	enrolment := &authtypes.EnrollFactorResponse{Type: "totp"}
+	enrolment.TOTP.Secret = "JBSWY3DPEHPK3PXP"
+	enrolment.TOTP.URI = "otpauth://totp/zello:ada@example.com?secret=JBSWY3DPEHPK3PXP&issuer=zello"
+	next, _ := m.enrolmentStarted(enrolmentMsg{enrolment: enrolment})
+
+	view := next.View()
+	for _, want := range []string{enrolment.TOTP.Secret, enrolment.TOTP.URI} {
+		if !strings.Contains(view, want) {
+			t.Errorf("enrolment view is missing %q:\n%s", want, view)
+		}
+	}
+}
+
+func TestSignupSignsIn(t *testing.T) {
+	srv := supabasetest.NewServer()
+	defer srv.Close()
+	client := srv.Client()
+	m := New(client, internal.NewSupabaseStore(client), "", "")
+
+	next, cmd := m.submitSignup("ada@example.com", "correct horse", "Ada")
+	for _, msg := range collect(t, cmd) {
+		next = send(t, next, msg)
+	}
+
+	got := next.(Model)
+	if got.err != nil {
+		t.Fatal(got.err)
+	}
+	if got.userID == "" || got.view != viewMain {
+		t.Fatalf("after signup userID = %q, view = %v", got.userID, got.view)
+	}
+	profiles := srv.Rows("profiles")
+	if len(profiles) != 1 || profiles[0]["id"] != got.userID {
+		t.Errorf("profiles = %v, want one for %s", profiles, got.userID)
+	}
+}
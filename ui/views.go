@@ -0,0 +1,234 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"zel/lo/internal"
+)
+
+// issueColumn describes one column of the issue list. A zero width means
+// the column is never padded.
+type issueColumn struct {
+	width int
+	value func(internal.Issue) string
+}
+
+var issueColumns = map[string]issueColumn{
+	"id":          {5, func(is internal.Issue) string { return fmt.Sprintf("#%d", is.ID) }},
+	"status":      {12, func(is internal.Issue) string { return is.Status }},
+	"title":       {20, func(is internal.Issue) string { return is.Title }},
+	"description": {0, func(is internal.Issue) string { return is.Description }},
+	"assignee":    {10, func(is internal.Issue) string { return shortID(is.AssigneeID) }},
+	"labels":      {16, func(is internal.Issue) string { return strings.Join(is.Labels, ",") }},
+	"created":     {10, func(is internal.Issue) string { return shortDate(is.CreatedAt) }},
+	"estimate": {4, func(is internal.Issue) string {
+		if is.Estimate == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*is.Estimate, 'f', -1, 64)
+	}},
+}
+
+// columnPresets are cycled with C in the list view; the first is the default.
+var columnPresets = [][]string{
+	{"id", "title", "description"},
+	{"id", "status", "title"},
+	{"id", "status", "title", "estimate", "assignee", "labels", "created"},
+}
+
+// sortOrders are cycled with O in the list view; "" keeps the server order.
+var sortOrders = []string{"", "created_at.desc", "created_at.asc", "updated_at.desc", "status.asc", "title.asc"}
+
+// savedViewItem lists a saved view in the main menu.
+type savedViewItem struct {
+	view internal.SavedView
+	own  bool
+}
+
+func (i savedViewItem) Title() string { return "★ " + i.view.Name }
+func (i savedViewItem) Description() string {
+	desc := i.view.Query
+	if desc == "" {
+		desc = "all issues"
+	}
+	if !i.own {
+		desc += " • shared"
+	}
+	return desc
+}
+func (i savedViewItem) FilterValue() string { return i.view.Name }
+
+type savedViewsMsg struct{ views []internal.SavedView }
+
+func renderIssueRow(columns []string, is internal.Issue) string {
+	cells := make([]string, 0, len(columns))
+	for i, name := range columns {
+		col, ok := issueColumns[name]
+		if !ok {
+			continue
+		}
+		v := col.value(is)
+		if i < len(columns)-1 && col.width > 0 {
+			v = fmt.Sprintf("%-*s", col.width, v)
+		}
+		cells = append(cells, v)
+	}
+	return strings.Join(cells, " ")
+}
+
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+func shortDate(ts string) string {
+	if len(ts) > 10 {
+		return ts[:10]
+	}
+	return ts
+}
+
+func (m Model) menuItems() []list.Item {
+	items := []list.Item{
+		menuItem{"Create Issue", "Open a form to create a new issue"},
+		menuItem{"List My Issues", "View issues you created"},
+	}
+	if m.client != nil {
+		items = append(items, menuItem{"Sprint Planning", "Plan sprints and follow the burndown"})
+		items = append(items, menuItem{"Settings", "Edit your profile, email and password"})
+	}
+	for _, v := range m.savedViews {
+		items = append(items, savedViewItem{view: v, own: v.UserID == m.userID})
+	}
+	return items
+}
+
+// boardID is the current board's id, or 0 when there is none.
+func (m Model) boardID() int {
+	if m.board == nil {
+		return 0
+	}
+	return m.board.ID
+}
+
+func (m Model) loadSavedViews() tea.Cmd {
+	client, userID, boardID := m.client, m.userID, m.boardID()
+	if userID == "" || client == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		views, err := internal.ListSavedViews(client, userID, boardID)
+		if err != nil {
+			return messageErr{err}
+		}
+		return savedViewsMsg{views}
+	}
+}
+
+func (m *Model) openSavedView(v internal.SavedView) (tea.Model, tea.Cmd) {
+	m.filterInput.SetValue(v.Query)
+	m.sort = v.Sort
+	m.columns = v.Columns
+	if len(m.columns) == 0 {
+		m.columns = columnPresets[0]
+	}
+	return m.fetchIssues()
+}
+
+func (m *Model) deleteSavedView(v internal.SavedView) (tea.Model, tea.Cmd) {
+	if v.UserID != m.userID {
+		return m, nil
+	}
+	client, userID, boardID := m.client, m.userID, m.boardID()
+	return m, func() tea.Msg {
+		if err := internal.DeleteSavedView(client, v.ID, userID); err != nil {
+			return messageErr{err}
+		}
+		views, err := internal.ListSavedViews(client, userID, boardID)
+		if err != nil {
+			return messageErr{err}
+		}
+		return savedViewsMsg{views}
+	}
+}
+
+func (m *Model) startSaveView(shared bool) (tea.Model, tea.Cmd) {
+	if shared && m.board == nil {
+		m.err = fmt.Errorf("no board to share the view with")
+		return m, nil
+	}
+	m.savingView = true
+	m.saveShared = shared
+	m.err = nil
+	m.viewNameInput.SetValue("")
+	m.viewNameInput.Focus()
+	return m, nil
+}
+
+func (m *Model) updateSaveViewKeys(k tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch k.String() {
+	case "esc":
+		m.savingView = false
+		m.viewNameInput.Blur()
+		return m, nil
+	case "enter":
+		name := strings.TrimSpace(m.viewNameInput.Value())
+		if name == "" {
+			m.err = fmt.Errorf("view name required")
+			return m, nil
+		}
+		m.savingView = false
+		m.viewNameInput.Blur()
+		client, boardID := m.client, m.boardID()
+		view := internal.SavedView{
+			UserID:  m.userID,
+			Name:    name,
+			Query:   strings.TrimSpace(m.filterInput.Value()),
+			Sort:    m.sort,
+			Columns: m.columns,
+			Shared:  m.saveShared,
+		}
+		if boardID != 0 {
+			view.BoardID = &boardID
+		}
+		return m, func() tea.Msg {
+			if _, err := internal.SaveView(client, view); err != nil {
+				return messageErr{err}
+			}
+			views, err := internal.ListSavedViews(client, view.UserID, boardID)
+			if err != nil {
+				return messageErr{err}
+			}
+			return savedViewsMsg{views}
+		}
+	}
+	var cmd tea.Cmd
+	m.viewNameInput, cmd = m.viewNameInput.Update(k)
+	return m, cmd
+}
+
+func nextOption(options []string, current string) string {
+	for i, o := range options {
+		if o == current {
+			return options[(i+1)%len(options)]
+		}
+	}
+	return options[0]
+}
+
+func nextPreset(current []string) []string {
+	key := strings.Join(current, ",")
+	for i, p := range columnPresets {
+		if strings.Join(p, ",") == key {
+			return columnPresets[(i+1)%len(columnPresets)]
+		}
+	}
+	return columnPresets[0]
+}
@@ -0,0 +1,47 @@
+package webhook
+
+import (
+	"io"
+	"net/http"
+	"time"
+)
+
+// Receiver is an http.Handler that accepts signed webhook requests, for
+// testing subscriptions locally. Requests with a bad signature get a 401 and
+// never reach Handle.
+type Receiver struct {
+	Secret string
+	// Tolerance bounds the age of accepted requests; zero means five
+	// minutes.
+	Tolerance time.Duration
+	Handle    func(event, deliveryID string, body []byte)
+	// Reject, when set, is called for requests that fail verification.
+	Reject func(r *http.Request, err error)
+}
+
+func (rc Receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+	tolerance := rc.Tolerance
+	if tolerance == 0 {
+		tolerance = 5 * time.Minute
+	}
+	if err := Verify(rc.Secret, r.Header, body, tolerance); err != nil {
+		if rc.Reject != nil {
+			rc.Reject(r, err)
+		}
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if rc.Handle != nil {
+		rc.Handle(r.Header.Get(EventHeader), r.Header.Get(DeliveryHeader), body)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
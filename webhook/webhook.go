@@ -0,0 +1,161 @@
+// Package webhook signs and delivers zello webhook requests and verifies
+// them on the receiving side.
+//
+// Every request is a JSON POST carrying these headers:
+//
+//	X-Zello-Event:     the event type, e.g. issue.status_changed
+//	X-Zello-Delivery:  an id that stays the same across retries
+//	X-Zello-Timestamp: Unix seconds when the attempt was signed
+//	X-Zello-Signature: sha256=HEX, the HMAC-SHA256 of "TIMESTAMP.BODY"
+//	                   keyed with the webhook secret
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	EventHeader     = "X-Zello-Event"
+	DeliveryHeader  = "X-Zello-Delivery"
+	TimestampHeader = "X-Zello-Timestamp"
+	SignatureHeader = "X-Zello-Signature"
+)
+
+// Delivery is one event bound for one webhook.
+type Delivery struct {
+	ID    string
+	Event string
+	Body  []byte
+}
+
+// Attempt records the outcome of a single POST. StatusCode is zero when the
+// request never got a response.
+type Attempt struct {
+	StatusCode int
+	Err        error
+	Duration   time.Duration
+}
+
+// OK reports whether the receiver accepted the delivery.
+func (a Attempt) OK() bool {
+	return a.Err == nil && a.StatusCode >= 200 && a.StatusCode < 300
+}
+
+// Sender posts deliveries, retrying failed attempts with exponential
+// backoff. The zero value makes up to three attempts, waiting one second
+// before the second and two before the third, with a ten second timeout
+// each.
+type Sender struct {
+	Client      *http.Client
+	MaxAttempts int
+	Backoff     time.Duration
+}
+
+// Sign returns the signature header value for body sent at timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature headers of a received request. Requests signed
+// more than tolerance ago are rejected to limit replays; a zero tolerance
+// skips that check.
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration) error {
+	ts, err := strconv.ParseInt(header.Get(TimestampHeader), 10, 64)
+	if err != nil {
+		return errors.New("missing or invalid timestamp")
+	}
+	if tolerance > 0 {
+		age := time.Since(time.Unix(ts, 0))
+		if age > tolerance || age < -tolerance {
+			return fmt.Errorf("timestamp is %s old", age.Round(time.Second))
+		}
+	}
+	want := Sign(secret, ts, body)
+	if !hmac.Equal([]byte(want), []byte(header.Get(SignatureHeader))) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+// Send posts d to url and returns every attempt made. It stops at the first
+// 2xx response, and does not retry 4xx responses other than 408 and 429
+// since the receiver will reject them again.
+func (s Sender) Send(ctx context.Context, url, secret string, d Delivery) []Attempt {
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	max := s.MaxAttempts
+	if max <= 0 {
+		max = 3
+	}
+	backoff := s.Backoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+
+	var attempts []Attempt
+	for i := 0; i < max; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return append(attempts, Attempt{Err: ctx.Err()})
+			case <-time.After(backoff << (i - 1)):
+			}
+		}
+		a := s.post(ctx, client, url, secret, d)
+		attempts = append(attempts, a)
+		if a.OK() || !retryable(a) {
+			break
+		}
+	}
+	return attempts
+}
+
+func (s Sender) post(ctx context.Context, client *http.Client, url, secret string, d Delivery) Attempt {
+	start := time.Now()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(d.Body))
+	if err != nil {
+		return Attempt{Err: err}
+	}
+	ts := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "zello-webhooks")
+	req.Header.Set(EventHeader, d.Event)
+	req.Header.Set(DeliveryHeader, d.ID)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(ts, 10))
+	req.Header.Set(SignatureHeader, Sign(secret, ts, d.Body))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Attempt{Err: err, Duration: time.Since(start)}
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	a := Attempt{StatusCode: resp.StatusCode, Duration: time.Since(start)}
+	if !a.OK() {
+		a.Err = fmt.Errorf("receiver responded %s", resp.Status)
+	}
+	return a
+}
+
+func retryable(a Attempt) bool {
+	if a.StatusCode == 0 {
+		return true
+	}
+	return a.StatusCode >= 500 || a.StatusCode == http.StatusRequestTimeout || a.StatusCode == http.StatusTooManyRequests
+}
@@ -0,0 +1,178 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	body := []byte(`{"event":"ping"}`)
+	now := time.Now().Unix()
+	header := func(ts int64, sig string) http.Header {
+		h := http.Header{}
+		h.Set(TimestampHeader, strconv.FormatInt(ts, 10))
+		h.Set(SignatureHeader, sig)
+		return h
+	}
+
+	// Computed with:
+	// printf '1700000000.{"event":"ping"}' | openssl dgst -sha256 -hmac whsec_test
+	if got, want := Sign("whsec_test", 1700000000, body), "sha256=aa8efe37b751e71157c508c5ac4acb1e9fe5225db98355dfc00f4b680afbc447"; got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+
+	tests := []struct {
+		name      string
+		header    http.Header
+		body      []byte
+		tolerance time.Duration
+		ok        bool
+	}{
+		{"valid", header(now, Sign("s3cret", now, body)), body, time.Minute, true},
+		{"wrong secret", header(now, Sign("other", now, body)), body, time.Minute, false},
+		{"tampered body", header(now, Sign("s3cret", now, body)), []byte(`{"event":"pong"}`), time.Minute, false},
+		{"timestamp not signed", header(now+1, Sign("s3cret", now, body)), body, time.Minute, false},
+		{"too old", header(now-600, Sign("s3cret", now-600, body)), body, time.Minute, false},
+		{"old without tolerance", header(now-600, Sign("s3cret", now-600, body)), body, 0, true},
+		{"missing timestamp", http.Header{SignatureHeader: {Sign("s3cret", now, body)}}, body, time.Minute, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify("s3cret", tt.header, tt.body, tt.tolerance)
+			if tt.ok && err != nil {
+				t.Errorf("rejected: %v", err)
+			}
+			if !tt.ok && err == nil {
+				t.Error("accepted")
+			}
+		})
+	}
+}
+
+// recorder is a receiver answering with the next status in turn and
+// remembering the requests it verified.
+type recorder struct {
+	mu       sync.Mutex
+	statuses []int
+	calls    int
+	verified []string
+}
+
+func (rc *recorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rc.mu.Lock()
+	status := rc.statuses[min(rc.calls, len(rc.statuses)-1)]
+	rc.calls++
+	rc.mu.Unlock()
+	if status != http.StatusNoContent {
+		w.WriteHeader(status)
+		return
+	}
+	Receiver{
+		Secret: "s3cret",
+		Handle: func(event, deliveryID string, body []byte) {
+			rc.mu.Lock()
+			rc.verified = append(rc.verified, event+" "+deliveryID+" "+string(body))
+			rc.mu.Unlock()
+		},
+	}.ServeHTTP(w, r)
+}
+
+func TestSend(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		attempts int
+		ok       bool
+	}{
+		{"first try", []int{204}, 1, true},
+		{"retry on 5xx", []int{500, 503, 204}, 3, true},
+		{"retry on 429", []int{429, 204}, 2, true},
+		{"give up after last attempt", []int{502}, 3, false},
+		{"no retry on 4xx", []int{400}, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc := &recorder{statuses: tt.statuses}
+			srv := httptest.NewServer(rc)
+			defer srv.Close()
+
+			sender := Sender{Backoff: time.Millisecond}
+			d := Delivery{ID: "1-42", Event: "issue.created", Body: []byte(`{"event":"issue.created"}`)}
+			attempts := sender.Send(context.Background(), srv.URL, "s3cret", d)
+
+			if len(attempts) != tt.attempts || rc.calls != tt.attempts {
+				t.Fatalf("made %d attempts, receiver saw %d, want %d", len(attempts), rc.calls, tt.attempts)
+			}
+			last := attempts[len(attempts)-1]
+			if last.OK() != tt.ok {
+				t.Errorf("last attempt = %+v, want ok %v", last, tt.ok)
+			}
+			if !tt.ok && (last.Err == nil || last.StatusCode != tt.statuses[len(tt.statuses)-1]) {
+				t.Errorf("failed attempt = %+v", last)
+			}
+			for _, a := range attempts[:len(attempts)-1] {
+				if a.OK() || a.StatusCode == 0 {
+					t.Errorf("earlier attempt = %+v", a)
+				}
+			}
+			if tt.ok {
+				want := `issue.created 1-42 {"event":"issue.created"}`
+				if len(rc.verified) != 1 || rc.verified[0] != want {
+					t.Errorf("receiver verified %q, want %q", rc.verified, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSendUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	attempts := Sender{MaxAttempts: 2, Backoff: time.Millisecond}.Send(context.Background(), url, "s3cret", Delivery{ID: "1", Event: "ping"})
+	if len(attempts) != 2 {
+		t.Fatalf("made %d attempts, want 2", len(attempts))
+	}
+	for _, a := range attempts {
+		if a.StatusCode != 0 || a.Err == nil {
+			t.Errorf("attempt = %+v, want a connection error", a)
+		}
+	}
+}
+
+func TestSendCancelled(t *testing.T) {
+	srv := httptest.NewServer(&recorder{statuses: []int{500}})
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	attempts := Sender{Backoff: time.Hour}.Send(ctx, srv.URL, "s3cret", Delivery{ID: "1", Event: "ping"})
+	if len(attempts) != 2 || attempts[1].Err != context.DeadlineExceeded {
+		t.Errorf("attempts = %+v, want a 500 then the deadline", attempts)
+	}
+}
+
+func TestReceiverRejectsBadSignature(t *testing.T) {
+	var rejected error
+	rc := Receiver{
+		Secret: "s3cret",
+		Handle: func(string, string, []byte) { t.Error("handled a forged request") },
+		Reject: func(_ *http.Request, err error) { rejected = err },
+	}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	attempts := Sender{}.Send(context.Background(), srv.URL, "wrong", Delivery{ID: "1", Event: "ping", Body: []byte("{}")})
+	if len(attempts) != 1 || attempts[0].StatusCode != http.StatusUnauthorized {
+		t.Errorf("attempts = %+v, want a single 401", attempts)
+	}
+	if rejected == nil {
+		t.Error("Reject was not called")
+	}
+}